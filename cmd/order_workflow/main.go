@@ -0,0 +1,23 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"OrderService/internal/model"
+)
+
+func main() {
+	format := flag.String("format", "mermaid", "diagram format: mermaid or dot")
+	flag.Parse()
+
+	switch *format {
+	case "mermaid":
+		fmt.Print(model.OrderWorkflow.Mermaid())
+	case "dot", "graphviz":
+		fmt.Print(model.OrderWorkflow.Graphviz())
+	default:
+		log.Fatalf("unknown format %q", *format)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	pb "github.com/erdedan1/protocol/proto/order_service/gen/v1"
+	"github.com/golang-jwt/jwt/v5"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/metadata"
@@ -44,7 +45,7 @@ func main() {
 
 	ctx := metadata.AppendToOutgoingContext(
 		context.Background(),
-		"x-user-uuid", "1179803e-06f0-4369-b94f-14e26ec190a3",
+		"authorization", "Bearer "+devToken(cfg.Infrastructure.Auth, "1179803e-06f0-4369-b94f-14e26ec190a3"),
 	)
 
 	for i := 0; i < 30; i++ {
@@ -57,7 +58,7 @@ func main() {
 
 	ctx = metadata.AppendToOutgoingContext(
 		context.Background(),
-		"x-user-uuid", "1179803e-06f0-4369-b94f-14e26ec190a1",
+		"authorization", "Bearer "+devToken(cfg.Infrastructure.Auth, "1179803e-06f0-4369-b94f-14e26ec190a1"),
 	)
 
 	for i := 0; i < 30; i++ {
@@ -68,3 +69,20 @@ func main() {
 		fmt.Println(res)
 	}
 }
+
+// devToken signs a short lived HS256 token for the probe, it only works against HS256 setups.
+func devToken(cfg config.AuthConfig, userID string) string {
+	claims := jwt.RegisteredClaims{
+		Subject:   userID,
+		Audience:  jwt.ClaimStrings{cfg.Audience},
+		Issuer:    cfg.Issuer,
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(5 * time.Minute)),
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(cfg.HMACSecret))
+	if err != nil {
+		log.Fatalf("sign token: %v", err)
+	}
+
+	return token
+}
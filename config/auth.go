@@ -0,0 +1,20 @@
+package config
+
+import "time"
+
+const (
+	AuthAlgorithmHS256 = "HS256"
+	AuthAlgorithmRS256 = "RS256"
+)
+
+// AuthConfig configures verification of the bearer JWTs callers authenticate with.
+// RS256 keys come from a PEM public key, a local JWKS file, or both.
+type AuthConfig struct {
+	Algorithm        string        `env:"AUTH_JWT_ALGORITHM" env-default:"HS256" validate:"oneof=HS256 RS256"`
+	HMACSecret       string        `env:"AUTH_JWT_HMAC_SECRET" validate:"required_if=Algorithm HS256"`
+	RSAPublicKeyFile string        `env:"AUTH_JWT_RSA_PUBLIC_KEY_FILE" validate:"omitempty,file"`
+	JWKSFile         string        `env:"AUTH_JWT_JWKS_FILE" validate:"omitempty,file"`
+	Audience         string        `env:"AUTH_JWT_AUDIENCE" validate:"required"`
+	Issuer           string        `env:"AUTH_JWT_ISSUER"`
+	Leeway           time.Duration `env:"AUTH_JWT_LEEWAY" env-default:"30s" validate:"gte=0"`
+}
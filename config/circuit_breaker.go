@@ -2,8 +2,15 @@ package config
 
 import "time"
 
+// CircuitBreakerConfig is the default of every per-method breaker. SettingsFile can
+// override it per breaker.
 type CircuitBreakerConfig struct {
-	ConsecutiveFailures uint32        `env:"CIRCUIT_BREAKER_CONSECUTIVE_FAILURES" env-default:"5" validate:"gt=0"`
-	HalfOpenRequests    uint32        `env:"CIRCUIT_BREAKER_HALF_OPEN_REQUESTS" env-default:"3" validate:"gt=0"`
-	OpenTimeout         time.Duration `env:"CIRCUIT_BREAKER_OPEN_TIMEOUT" env-default:"10s" validate:"gt=0"`
+	Window           time.Duration `env:"CIRCUIT_BREAKER_WINDOW" env-default:"10s" validate:"gt=0"`
+	WindowBuckets    int           `env:"CIRCUIT_BREAKER_WINDOW_BUCKETS" env-default:"10" validate:"gt=0"`
+	MinRequests      uint32        `env:"CIRCUIT_BREAKER_MIN_REQUESTS" env-default:"20" validate:"gt=0"`
+	FailureRatio     float64       `env:"CIRCUIT_BREAKER_FAILURE_RATIO" env-default:"0.5" validate:"gt=0,lte=1"`
+	HalfOpenRequests uint32        `env:"CIRCUIT_BREAKER_HALF_OPEN_REQUESTS" env-default:"3" validate:"gt=0"`
+	OpenTimeout      time.Duration `env:"CIRCUIT_BREAKER_OPEN_TIMEOUT" env-default:"10s" validate:"gt=0"`
+	// SettingsFile is a JSON file with settings per breaker name, see circuitbreaker.File.
+	SettingsFile string `env:"CIRCUIT_BREAKER_SETTINGS_FILE" validate:"omitempty,file"`
 }
@@ -0,0 +1,15 @@
+package config
+
+import "time"
+
+type ConcurrencyLimiterConfig struct {
+	Enabled      bool    `env:"CONCURRENCY_LIMITER_ENABLED" validate:"-"`
+	InitialLimit int     `env:"CONCURRENCY_LIMITER_INITIAL_LIMIT" env-default:"50" validate:"gt=0"`
+	MinLimit     int     `env:"CONCURRENCY_LIMITER_MIN_LIMIT" env-default:"10" validate:"gt=0"`
+	MaxLimit     int     `env:"CONCURRENCY_LIMITER_MAX_LIMIT" env-default:"500" validate:"gtefield=MinLimit"`
+	BackoffRatio float64 `env:"CONCURRENCY_LIMITER_BACKOFF_RATIO" env-default:"0.9" validate:"gt=0,lt=1"`
+	// LatencyThreshold is the latency past which a call counts as a sign of overload.
+	LatencyThreshold time.Duration `env:"CONCURRENCY_LIMITER_LATENCY_THRESHOLD" env-default:"500ms" validate:"gt=0"`
+	// ReadShare is the part of the limit reads may fill, the rest is kept for order writes.
+	ReadShare float64 `env:"CONCURRENCY_LIMITER_READ_SHARE" env-default:"0.8" validate:"gt=0,lte=1"`
+}
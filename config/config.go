@@ -1,6 +1,8 @@
 package config
 
 import (
+	"time"
+
 	"github.com/go-playground/validator/v10"
 	"github.com/ilyakaznacheev/cleanenv"
 	_ "github.com/joho/godotenv/autoload"
@@ -15,15 +17,26 @@ type Config struct {
 }
 
 type InfrastructureConfig struct {
-	RedisConfig            RedisConfig            `validate:"required"`
-	Observability          Observability          `validate:"required"`
-	OrderLifecircuitConfig OrderLifecircuitConfig `validate:"required"`
-	RateLimiter            RateLimiterConfig      `validate:"required"`
-	CircuitBreaker         CircuitBreakerConfig   `validate:"required"`
+	RedisConfig            RedisConfig              `validate:"required"`
+	MarketCache            MarketCacheConfig        `validate:"required"`
+	Observability          Observability            `validate:"required"`
+	OrderLifecircuitConfig OrderLifecircuitConfig   `validate:"required"`
+	RateLimiter            RateLimiterConfig        `validate:"required"`
+	CircuitBreaker         CircuitBreakerConfig     `validate:"required"`
+	ConcurrencyLimiter     ConcurrencyLimiterConfig `validate:"required"`
+	Outbox                 OutboxConfig             `validate:"required"`
+	OrderStatusStream      OrderStatusStreamConfig  `validate:"required"`
+	LeaderElection         LeaderElectionConfig     `validate:"required"`
+	Idempotency            IdempotencyConfig        `validate:"required"`
+	UserRepo               UserRepoConfig           `validate:"required"`
+	Auth                   AuthConfig               `validate:"required"`
 }
 
 type GRPCApiConfig struct {
 	SpotInstrumentServiceHost string `env:"GRPC_API_SPOT_INSTRUMENT_SERVICE_HOST" validate:"required"`
+	// SpotInstrumentFallbackTTL is how long the last known markets are kept to be served
+	// while the breaker to the spot instrument service is open.
+	SpotInstrumentFallbackTTL time.Duration `env:"GRPC_API_SPOT_INSTRUMENT_FALLBACK_TTL" env-default:"24h" validate:"gt=0"`
 }
 
 func New() (*Config, error) {
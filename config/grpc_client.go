@@ -8,4 +8,21 @@ type GRPCClientConfig struct {
 	BaseBackoffDelay  time.Duration `env:"GRPC_CLIENT_BASE_BACKOFF_DELAY" validate:"gte=0"`
 	BackoffMultiplier float64       `env:"GRPC_CLIENT_BACKOFF_MULTIPLIER" validate:"gte=1"`
 	BackoffJitter     float64       `env:"GRPC_CLIENT_BACKOFF_JITTER" validate:"gte=0"`
+
+	// CallTimeout is the deadline of calls made without one.
+	CallTimeout time.Duration `env:"GRPC_CLIENT_CALL_TIMEOUT" env-default:"3s" validate:"gt=0"`
+
+	// Retries of idempotent calls, attempts include the first one and 1 turns retrying off.
+	RetryMaxAttempts       int           `env:"GRPC_CLIENT_RETRY_MAX_ATTEMPTS" env-default:"3" validate:"gte=1,lte=5"`
+	RetryInitialBackoff    time.Duration `env:"GRPC_CLIENT_RETRY_INITIAL_BACKOFF" env-default:"100ms" validate:"gt=0"`
+	RetryMaxBackoff        time.Duration `env:"GRPC_CLIENT_RETRY_MAX_BACKOFF" env-default:"1s" validate:"gtefield=RetryInitialBackoff"`
+	RetryBackoffMultiplier float64       `env:"GRPC_CLIENT_RETRY_BACKOFF_MULTIPLIER" env-default:"2" validate:"gt=0"`
+	RetryableCodes         []string      `env:"GRPC_CLIENT_RETRYABLE_CODES" env-default:"UNAVAILABLE" validate:"min=1"`
+
+	TLSEnabled        bool          `env:"GRPC_CLIENT_TLS_ENABLED" validate:"-"`
+	TLSCAFile         string        `env:"GRPC_CLIENT_TLS_CA_FILE" validate:"omitempty,file"`
+	TLSCertFile       string        `env:"GRPC_CLIENT_TLS_CERT_FILE" validate:"required_with=TLSKeyFile,omitempty,file"`
+	TLSKeyFile        string        `env:"GRPC_CLIENT_TLS_KEY_FILE" validate:"required_with=TLSCertFile,omitempty,file"`
+	TLSServerName     string        `env:"GRPC_CLIENT_TLS_SERVER_NAME"`
+	TLSReloadInterval time.Duration `env:"GRPC_CLIENT_TLS_RELOAD_INTERVAL" env-default:"30s" validate:"gte=0"`
 }
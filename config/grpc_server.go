@@ -7,10 +7,20 @@ type GRPCServerConfig struct {
 	MaxRecvMsgSize       int           `env:"GRPC_SERVER_MAX_RECV_MSG_SIZE" validate:"gte=0"`
 	MaxSendMsgSize       int           `env:"GRPC_SERVER_MAX_SEND_MSG_SIZE" validate:"gte=0"`
 	EnableReflection     bool          `env:"GRPC_SERVER_ENABLE_REFLECTION" validate:"-"`
-	TLSCertFile          string        `env:"GRPC_SERVER_TLS_CERT_FILE" validate:"omitempty,file"`
-	TLSKeyFile           string        `env:"GRPC_SERVER_TLS_KEY_FILE" validate:"omitempty,file"`
+	TLSCertFile          string        `env:"GRPC_SERVER_TLS_CERT_FILE" validate:"required_with=TLSKeyFile,omitempty,file"`
+	TLSKeyFile           string        `env:"GRPC_SERVER_TLS_KEY_FILE" validate:"required_with=TLSCertFile,omitempty,file"`
+	TLSClientCAFile      string        `env:"GRPC_SERVER_TLS_CLIENT_CA_FILE" validate:"required_if=TLSRequireClientCert true,omitempty,file"`
+	TLSRequireClientCert bool          `env:"GRPC_SERVER_TLS_REQUIRE_CLIENT_CERT" validate:"-"`
+	TLSReloadInterval    time.Duration `env:"GRPC_SERVER_TLS_RELOAD_INTERVAL" env-default:"30s" validate:"gte=0"`
+	HealthCheckInterval  time.Duration `env:"GRPC_SERVER_HEALTH_CHECK_INTERVAL" env-default:"5s" validate:"gt=0"`
+	HealthCheckTimeout   time.Duration `env:"GRPC_SERVER_HEALTH_CHECK_TIMEOUT" env-default:"2s" validate:"gt=0"`
+	ShutdownDrainDelay   time.Duration `env:"GRPC_SERVER_SHUTDOWN_DRAIN_DELAY" env-default:"5s" validate:"gte=0"`
 	ReadTimeout          time.Duration `env:"GRPC_SERVER_READ_TIMEOUT" validate:"gte=0"`
 	WriteTimeout         time.Duration `env:"GRPC_SERVER_WRITE_TIMEOUT" validate:"gte=0"`
+	EnableGateway        bool          `env:"GRPC_SERVER_ENABLE_GATEWAY" validate:"-"`
+	GatewayListenAddr    string        `env:"GRPC_SERVER_GATEWAY_LISTEN_ADDR" validate:"required_with=EnableGateway,omitempty"`
+	EnableAdmin          bool          `env:"GRPC_SERVER_ENABLE_ADMIN" validate:"-"`
+	AdminListenAddr      string        `env:"GRPC_SERVER_ADMIN_LISTEN_ADDR" validate:"required_with=EnableAdmin,omitempty"`
 	EnablePrometheus     bool          `env:"GRPC_SERVER_ENABLE_PROMETHEUS" validate:"-"`
 	PrometheusListenAddr string        `env:"GRPC_SERVER_PROMETHEUS_LISTEN_ADDR" validate:"required_with=EnablePrometheus,omitempty"`
 }
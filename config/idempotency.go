@@ -0,0 +1,8 @@
+package config
+
+import "time"
+
+type IdempotencyConfig struct {
+	Retention     time.Duration `env:"IDEMPOTENCY_KEY_RETENTION" env-default:"24h" validate:"gt=0"`
+	SweepInterval time.Duration `env:"IDEMPOTENCY_KEY_SWEEP_INTERVAL" env-default:"1h" validate:"gt=0"`
+}
@@ -0,0 +1,9 @@
+package config
+
+import "time"
+
+type LeaderElectionConfig struct {
+	Enabled       bool          `env:"LEADER_ELECTION_ENABLED" env-default:"true"`
+	LockKey       int64         `env:"LEADER_ELECTION_LOCK_KEY" env-default:"7031450124" validate:"required"`
+	RenewInterval time.Duration `env:"LEADER_ELECTION_RENEW_INTERVAL" env-default:"5s" validate:"gt=0"`
+}
@@ -0,0 +1,14 @@
+package config
+
+import "time"
+
+// MarketCacheConfig tunes the in-process tier in front of the Redis market cache.
+type MarketCacheConfig struct {
+	LocalCapacity int           `env:"MARKET_CACHE_LOCAL_CAPACITY" env-default:"1024" validate:"gt=0"`
+	LocalTTL      time.Duration `env:"MARKET_CACHE_LOCAL_TTL" env-default:"5s" validate:"gt=0"`
+	// StaleTTL is how long an expired local entry is still served while it is refreshed.
+	StaleTTL    time.Duration `env:"MARKET_CACHE_STALE_TTL" env-default:"30s" validate:"gte=0"`
+	LoadTimeout time.Duration `env:"MARKET_CACHE_LOAD_TIMEOUT" env-default:"5s" validate:"gt=0"`
+	// InvalidationChannel is the Redis pub/sub channel market changes are announced on.
+	InvalidationChannel string `env:"MARKET_CACHE_INVALIDATION_CHANNEL" env-default:"markets:invalidate" validate:"required"`
+}
@@ -5,4 +5,7 @@ import "time"
 type OrderLifecircuitConfig struct {
 	StepInterval time.Duration `env:"ORDER_LIFECIRCUIT_STEP_INTERVAL" env-default:"5s" validate:"gt=0"`
 	TimeOut      time.Duration `env:"ORDER_LIFECIRCUIT_TIMEOUT" env-default:"12h" validate:"gt=0"`
+	PollInterval time.Duration `env:"ORDER_LIFECIRCUIT_POLL_INTERVAL" env-default:"1s" validate:"gt=0"`
+	BatchSize    int           `env:"ORDER_LIFECIRCUIT_BATCH_SIZE" env-default:"100" validate:"gt=0"`
+	ClaimLease   time.Duration `env:"ORDER_LIFECIRCUIT_CLAIM_LEASE" env-default:"30s" validate:"gt=0"`
 }
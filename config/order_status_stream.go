@@ -0,0 +1,15 @@
+package config
+
+import "time"
+
+const (
+	OrderStatusTransportPubSub  = "pubsub"
+	OrderStatusTransportStreams = "streams"
+)
+
+type OrderStatusStreamConfig struct {
+	Transport    string        `env:"ORDER_STATUS_TRANSPORT" env-default:"pubsub" validate:"oneof=pubsub streams"`
+	MaxLen       int64         `env:"ORDER_STATUS_STREAM_MAX_LEN" env-default:"100" validate:"gt=0"`
+	TTL          time.Duration `env:"ORDER_STATUS_STREAM_TTL" env-default:"24h" validate:"gt=0"`
+	BlockTimeout time.Duration `env:"ORDER_STATUS_STREAM_BLOCK_TIMEOUT" env-default:"5s" validate:"gt=0"`
+}
@@ -0,0 +1,16 @@
+package config
+
+import "time"
+
+type OutboxConfig struct {
+	PollInterval time.Duration `env:"OUTBOX_POLL_INTERVAL" env-default:"500ms" validate:"gt=0"`
+	BatchSize    int           `env:"OUTBOX_BATCH_SIZE" env-default:"100" validate:"gt=0"`
+	ClaimLease   time.Duration `env:"OUTBOX_CLAIM_LEASE" env-default:"30s" validate:"gt=0"`
+	BaseBackoff  time.Duration `env:"OUTBOX_BASE_BACKOFF" env-default:"1s" validate:"gt=0"`
+	MaxBackoff   time.Duration `env:"OUTBOX_MAX_BACKOFF" env-default:"1m" validate:"gtefield=BaseBackoff"`
+	// MaxAttempts is how often an event is tried before it is dead-lettered.
+	MaxAttempts int `env:"OUTBOX_MAX_ATTEMPTS" env-default:"20" validate:"gt=0"`
+	// Retention is how long delivered events are kept before the sweeper deletes them.
+	Retention     time.Duration `env:"OUTBOX_RETENTION" env-default:"24h" validate:"gt=0"`
+	SweepInterval time.Duration `env:"OUTBOX_SWEEP_INTERVAL" env-default:"10m" validate:"gt=0"`
+}
@@ -1,9 +1,29 @@
 package config
 
+import "time"
+
+const (
+	RateLimiterBackendMemory = "memory"
+	RateLimiterBackendRedis  = "redis"
+)
+
 type RateLimiterConfig struct {
 	GlobalRequestsPerSecond float64 `env:"RATE_LIMITER_GLOBAL_RPS" env-default:"50" validate:"gt=0"`
 	GlobalBurst             float64 `env:"RATE_LIMITER_GLOBAL_BURST" env-default:"100" validate:"gt=0"`
 
 	ClientRequestsPerSecond float64 `env:"RATE_LIMITER_CLIENT_RPS" env-default:"50" validate:"gt=0"`
 	ClientBurst             float64 `env:"RATE_LIMITER_CLIENT_BURST" env-default:"100" validate:"gt=0"`
+
+	// PolicyFile is a JSON file with rates per role and rates and costs per method, see
+	// ratelimit.PolicyFile. Without it every call costs one token of the client rate.
+	PolicyFile string `env:"RATE_LIMITER_POLICY_FILE" validate:"omitempty,file"`
+
+	// Backend keeps the per-client buckets, redis shares them between replicas. The global
+	// bucket guards a single instance and always stays in memory.
+	Backend string `env:"RATE_LIMITER_BACKEND" env-default:"memory" validate:"oneof=memory redis"`
+	// ClientIdleTTL is how long the bucket of a silent client is kept.
+	ClientIdleTTL  time.Duration `env:"RATE_LIMITER_CLIENT_IDLE_TTL" env-default:"10m" validate:"gt=0"`
+	RedisKeyPrefix string        `env:"RATE_LIMITER_REDIS_KEY_PREFIX" env-default:"ratelimit:"`
+	// RedisTimeout bounds a bucket update, past it the call is limited by local buckets.
+	RedisTimeout time.Duration `env:"RATE_LIMITER_REDIS_TIMEOUT" env-default:"50ms" validate:"gt=0"`
 }
@@ -0,0 +1,12 @@
+package config
+
+const (
+	UserRepoBackendPostgres = "postgres"
+	UserRepoBackendMemory   = "memory"
+)
+
+type UserRepoConfig struct {
+	// Backend picks the user store, memory keeps the seeded in-process users and is meant for tests.
+	// Postgres starts empty, scripts/seed_dev_users.sql adds the same users to a local database.
+	Backend string `env:"USER_REPO_BACKEND" env-default:"postgres" validate:"oneof=postgres memory"`
+}
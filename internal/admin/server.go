@@ -0,0 +1,140 @@
+package admin
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"OrderService/internal/auth"
+	errs "OrderService/internal/errors"
+	"OrderService/internal/policy"
+	"OrderService/internal/usecase"
+
+	log "github.com/erdedan1/shared/logger"
+)
+
+const (
+	layer = "AdminServer"
+
+	readHeaderTimeout = 5 * time.Second
+	shutdownTimeout   = 5 * time.Second
+
+	bearerPrefix = "bearer "
+)
+
+// Server is the operator HTTP listener. Every endpoint needs a bearer token of an active
+// user the policy lets operate the service, judged by the stored roles like the order
+// RPCs are. It serves TLS with the gRPC server's certificates when a tlsConfig is given;
+// without one it is plaintext and must only listen on a private address.
+type Server struct {
+	server   *http.Server
+	mux      *http.ServeMux
+	verifier *auth.Verifier
+	users    usecase.UserRepo
+	policy   *policy.Policy
+	log      log.Logger
+}
+
+func NewServer(
+	address string,
+	verifier *auth.Verifier,
+	users usecase.UserRepo,
+	tlsConfig *tls.Config,
+	log log.Logger,
+) *Server {
+	mux := http.NewServeMux()
+
+	return &Server{
+		server: &http.Server{
+			Addr:              address,
+			Handler:           mux,
+			TLSConfig:         tlsConfig,
+			ReadHeaderTimeout: readHeaderTimeout,
+		},
+		mux:      mux,
+		verifier: verifier,
+		users:    users,
+		policy:   policy.Default(),
+		log:      log,
+	}
+}
+
+// Handle registers handler for pattern behind the admin check.
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, s.requireAdmin(handler))
+}
+
+// ServeHTTP serves the registered endpoints, each behind the admin check.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Run serves the admin endpoints until ctx is cancelled.
+func (s *Server) Run(ctx context.Context) {
+	const method = "Run"
+
+	lis, err := net.Listen("tcp", s.server.Addr)
+	if err != nil {
+		s.log.Error(layer, method, "failed to listen", err, "address", s.server.Addr)
+		return
+	}
+	if s.server.TLSConfig != nil {
+		lis = tls.NewListener(lis, s.server.TLSConfig)
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		if err := s.server.Shutdown(shutdownCtx); err != nil {
+			s.log.Error(layer, method, "admin server shutdown failed", err)
+		}
+	}()
+
+	s.log.Info(layer, method, "admin server started", "address", s.server.Addr, "tls", s.server.TLSConfig != nil)
+
+	if err := s.server.Serve(lis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		s.log.Error(layer, method, "admin server failed", err, "address", s.server.Addr)
+	}
+}
+
+func (s *Server) requireAdmin(next http.Handler) http.Handler {
+	const method = "requireAdmin"
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("Authorization")
+		if len(header) <= len(bearerPrefix) || !strings.EqualFold(header[:len(bearerPrefix)], bearerPrefix) {
+			http.Error(w, "missing bearer token", http.StatusUnauthorized)
+			return
+		}
+
+		principal, err := s.verifier.Verify(strings.TrimSpace(header[len(bearerPrefix):]))
+		if err != nil {
+			s.log.Error(layer, method, "bearer token rejected", err, "path", r.URL.Path)
+			http.Error(w, "invalid bearer token", http.StatusUnauthorized)
+			return
+		}
+
+		// the roles of the token are not trusted, a demoted or deactivated admin may
+		// still hold one that has not expired
+		user, cerr := s.users.GetUserById(r.Context(), principal.UserID)
+		if cerr != nil && cerr != errs.ErrUserNotFound {
+			s.log.Error(layer, method, "failed to get user", cerr, "user_id", principal.UserID)
+			http.Error(w, "user lookup failed", http.StatusServiceUnavailable)
+			return
+		}
+
+		if !s.policy.Allows(user, policy.ActionOperateService) {
+			http.Error(w, "admin role required", http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(auth.NewContext(r.Context(), principal)))
+	})
+}
@@ -0,0 +1,113 @@
+package admin_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"OrderService/config"
+	"OrderService/internal/admin"
+	"OrderService/internal/auth"
+	errs "OrderService/internal/errors"
+	"OrderService/internal/model"
+	"OrderService/mocks"
+
+	sharedErrs "github.com/erdedan1/shared/errs"
+	log "github.com/erdedan1/shared/logger"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const adminTestSecret = "admin-secret"
+
+func newTestServer(t *testing.T, users *mocks.UserRepo) *admin.Server {
+	t.Helper()
+
+	verifier, err := auth.NewVerifier(config.AuthConfig{
+		Algorithm:  config.AuthAlgorithmHS256,
+		HMACSecret: adminTestSecret,
+		Audience:   "order-service",
+	})
+	assert.Nil(t, err)
+
+	logger, _ := log.NewLogger("debug")
+
+	server := admin.NewServer("127.0.0.1:0", verifier, users, nil, logger)
+	server.Handle("GET /admin/ping", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	return server
+}
+
+// adminToken claims the admin role whatever the stored user has.
+func adminToken(t *testing.T, userID uuid.UUID) string {
+	t.Helper()
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, auth.Claims{
+		Roles: []string{model.RoleAdmin},
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   userID.String(),
+			Audience:  jwt.ClaimStrings{"order-service"},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	}).SignedString([]byte(adminTestSecret))
+	assert.NoError(t, err)
+
+	return "Bearer " + token
+}
+
+func ping(server *admin.Server, authorization string) int {
+	request := httptest.NewRequest(http.MethodGet, "/admin/ping", nil)
+	if authorization != "" {
+		request.Header.Set("Authorization", authorization)
+	}
+
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, request)
+
+	return rec.Code
+}
+
+func TestServer_RequiresStoredAdmin(t *testing.T) {
+	deactivatedAt := time.Now()
+
+	tests := []struct {
+		name   string
+		stored *model.User
+		err    *sharedErrs.CustomError
+		want   int
+	}{
+		{name: "stored admin", stored: &model.User{Roles: []string{model.RoleAdmin}}, want: http.StatusNoContent},
+		{name: "demoted admin", stored: &model.User{Roles: []string{model.RoleTrader}}, want: http.StatusForbidden},
+		{name: "deactivated admin", stored: &model.User{Roles: []string{model.RoleAdmin}, DeactivatedAt: &deactivatedAt}, want: http.StatusForbidden},
+		{name: "unknown user", err: errs.ErrUserNotFound, want: http.StatusForbidden},
+		{name: "lookup failed", err: sharedErrs.New(sharedErrs.UNAVAILABLE, "postgres is down"), want: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := mocks.NewUserRepo(t)
+			server := newTestServer(t, users)
+
+			userID := uuid.New()
+			if tt.stored != nil {
+				tt.stored.ID = userID
+			}
+			users.On("GetUserById", mock.Anything, userID).Return(tt.stored, tt.err)
+
+			assert.Equal(t, tt.want, ping(server, adminToken(t, userID)))
+		})
+	}
+}
+
+func TestServer_RejectsMissingOrInvalidToken(t *testing.T) {
+	// no expectations, the user is never looked up
+	server := newTestServer(t, mocks.NewUserRepo(t))
+
+	assert.Equal(t, http.StatusUnauthorized, ping(server, ""))
+	assert.Equal(t, http.StatusUnauthorized, ping(server, "Bearer not-a-token"))
+}
@@ -2,81 +2,241 @@ package app
 
 import (
 	"context"
+	"crypto/tls"
 	"os"
 	"os/signal"
 	"syscall"
 
 	"OrderService/config"
+	"OrderService/internal/admin"
+	"OrderService/internal/auth"
+	"OrderService/internal/connection"
 	"OrderService/internal/grpc/order_service"
 	"OrderService/internal/grpc/spot_instrument_service"
+	"OrderService/internal/health"
+	"OrderService/internal/metrics"
+	"OrderService/internal/ratelimit"
 	"OrderService/internal/repository/market"
 	postgres "OrderService/internal/repository/order/postgres"
 	orderStatusRepo "OrderService/internal/repository/order_status"
+	outboxRepo "OrderService/internal/repository/outbox"
 	"OrderService/internal/repository/user"
+	idempotencySrv "OrderService/internal/service/idempotency"
+	lifecircuitSrv "OrderService/internal/service/lifecircuit"
 	orderSrv "OrderService/internal/service/order"
+	outboxSrv "OrderService/internal/service/outbox"
+	"OrderService/internal/usecase"
 	"OrderService/pkg/cache"
+	"OrderService/pkg/circuitbreaker"
 
 	"github.com/erdedan1/shared/errs"
 	log "github.com/erdedan1/shared/logger"
+	"github.com/jmoiron/sqlx"
 	"go.opentelemetry.io/otel/sdk/trace"
 )
 
 type App struct {
 	cfg        *config.Config
 	grpcServer *order_service.GRPCServer
-	log        log.Logger
+	// elector gates jobs to the leader instance, without it jobs run everywhere.
+	elector leaderElector
+	jobs    []Job
+	// metrics serves /metrics on every instance, nil when Prometheus is disabled.
+	metrics *metrics.Server
+	// admin serves the operator endpoints, nil when disabled.
+	admin *admin.Server
+	// marketInvalidator evicts stale markets on every instance, leader or not.
+	marketInvalidator *market.Invalidator
+	log               log.Logger
 }
 
-func New(cfg *config.Config, grpcServer *order_service.GRPCServer, log log.Logger) *App {
-	return &App{
+func New(
+	cfg *config.Config,
+	grpcServer *order_service.GRPCServer,
+	log log.Logger,
+	elector *connection.LeaderElector,
+	jobs ...Job,
+) *App {
+	app := &App{
 		cfg:        cfg,
 		grpcServer: grpcServer,
+		jobs:       jobs,
 		log:        log,
 	}
+	if elector != nil {
+		app.elector = elector
+	}
+
+	return app
 }
 
 func Build(cfg *config.Config, log log.Logger, tp *trace.TracerProvider) (*App, *errs.CustomError) {
 	ctx := context.Background()
 	redis := cache.NewRedisClient(cfg)
 
-	orderRepo, err := postgres.New(ctx, log, cfg.PostgresDB, tp)
+	db, err := connection.New(ctx, cfg.PostgresDB)
 	if err != nil {
 		return nil, err
 	}
 
-	userRepo := user.NewRepo(log, tp)
+	orderRepo := postgres.New(db, log, tp)
+
+	userRepo := newUserRepo(db, log, tp, cfg.Infrastructure.UserRepo)
+
+	subscriber, publisher := newOrderStatusTransport(redis, log, tp, cfg.Infrastructure.OrderStatusStream)
+
+	outbox := outboxRepo.New(db, log, tp)
+	outboxRelay := outboxSrv.NewRelay(
+		outbox,
+		publisher,
+		log,
+		tp,
+		cfg.Infrastructure.Outbox,
+	)
+	outboxSweeper := outboxSrv.NewSweeper(outbox, log, tp, cfg.Infrastructure.Outbox)
 
-	subscriber := orderStatusRepo.NewRedisSubscriber(redis, log, tp)
-	publisher := orderStatusRepo.NewRedisPublisher(redis, log, tp)
+	marketCache := market.NewMarketsCache(redis, log, tp)
 
-	marketService, err := spot_instrument_service.NewMarketService(cfg, tp)
+	marketService, err := spot_instrument_service.NewMarketService(cfg, marketCache, log, tp)
 	if err != nil {
 		return nil, err
 	}
 
-	marketCache := market.NewMarketsCache(redis, log, tp)
+	tieredMarketCache := market.NewTieredMarketsCache(marketCache, cfg.Infrastructure.MarketCache, log, tp)
+	marketInvalidator := market.NewInvalidator(redis, tieredMarketCache, cfg.Infrastructure.MarketCache.InvalidationChannel, log, tp)
 
 	orderService := orderSrv.New(
 		orderRepo,
 		userRepo,
-		marketCache,
+		tieredMarketCache,
 		marketService,
 		subscriber,
-		publisher,
 		log,
 		tp,
 		cfg,
 	)
 
-	grpcServer, err := order_service.NewGRPCServer(cfg.GRPCServer.Address, orderService, log, tp, cfg.Infrastructure)
+	grpcServer, err := order_service.NewGRPCServer(
+		cfg.GRPCServer,
+		orderService,
+		userRepo,
+		newClientRateLimiter(redis, log, cfg.Infrastructure.RateLimiter),
+		log,
+		tp,
+		cfg.Infrastructure,
+		health.PostgresProbe(db),
+		health.RedisProbe(redis),
+		health.GRPCClientProbe(health.SpotInstrumentProbeName, marketService.Conn()),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	lifecircuitWorker := lifecircuitSrv.NewWorker(orderRepo, log, tp, cfg.Infrastructure.OrderLifecircuitConfig)
+	idempotencySweeper := idempotencySrv.NewSweeper(orderRepo, log, tp, cfg.Infrastructure.Idempotency)
+
+	var elector *connection.LeaderElector
+	if cfg.Infrastructure.LeaderElection.Enabled {
+		elector = connection.NewLeaderElector(db, log, cfg.Infrastructure.LeaderElection)
+	}
+
+	app := New(cfg, grpcServer, log, elector, outboxRelay, outboxSweeper, lifecircuitWorker, idempotencySweeper)
+	app.marketInvalidator = marketInvalidator
+	if cfg.GRPCServer.EnablePrometheus {
+		app.metrics = metrics.NewServer(cfg.GRPCServer.PrometheusListenAddr, log)
+	}
+	if cfg.GRPCServer.EnableAdmin {
+		app.admin, err = newAdminServer(cfg, map[string]*circuitbreaker.Group{
+			"grpc_server":                        grpcServer.Breakers(),
+			spot_instrument_service.BreakerGroup: marketService.Breakers(),
+		}, marketInvalidator, userRepo, grpcServer.TLSConfig(), log)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return app, nil
+}
+
+func newAdminServer(
+	cfg *config.Config,
+	breakers map[string]*circuitbreaker.Group,
+	marketInvalidator *market.Invalidator,
+	userRepo usecase.UserRepo,
+	tlsConfig *tls.Config,
+	log log.Logger,
+) (*admin.Server, *errs.CustomError) {
+	verifier, err := auth.NewVerifier(cfg.Infrastructure.Auth)
 	if err != nil {
 		return nil, err
 	}
 
-	return New(cfg, grpcServer, log), nil
+	server := admin.NewServer(cfg.GRPCServer.AdminListenAddr, verifier, userRepo, tlsConfig, log)
+	server.Handle("GET /admin/circuit-breakers", circuitbreaker.Handler(breakers))
+	server.Handle("POST /admin/market-cache/flush", marketInvalidator.FlushHandler())
+
+	return server, nil
+}
+
+func newUserRepo(db *sqlx.DB, log log.Logger, tp *trace.TracerProvider, cfg config.UserRepoConfig) usecase.UserRepo {
+	if cfg.Backend == config.UserRepoBackendMemory {
+		return user.NewRepo(log, tp)
+	}
+
+	return user.NewPostgresRepo(db, log, tp)
+}
+
+func newClientRateLimiter(redis cache.RedisClient, log log.Logger, cfg config.RateLimiterConfig) ratelimit.Limiter {
+	if cfg.Backend == config.RateLimiterBackendRedis {
+		return ratelimit.NewRedis(redis, cfg.RedisKeyPrefix, cfg.ClientIdleTTL, cfg.RedisTimeout, log)
+	}
+
+	return ratelimit.NewMemory(cfg.ClientIdleTTL)
+}
+
+func newOrderStatusTransport(
+	redis cache.RedisClient,
+	log log.Logger,
+	tp *trace.TracerProvider,
+	cfg config.OrderStatusStreamConfig,
+) (usecase.OrderStatusSubscriber, usecase.OrderStatusPublisher) {
+	if cfg.Transport == config.OrderStatusTransportStreams {
+		return orderStatusRepo.NewRedisStreamSubscriber(redis, log, tp, cfg),
+			orderStatusRepo.NewRedisStreamPublisher(redis, log, tp, cfg)
+	}
+
+	return orderStatusRepo.NewRedisSubscriber(redis, log, tp),
+		orderStatusRepo.NewRedisPublisher(redis, log, tp)
 }
 
 func (a *App) Start(ctx context.Context) *errs.CustomError {
+	stopJobs := a.startJobs(ctx)
+	defer stopJobs()
+
+	if a.metrics != nil {
+		metricsCtx, stopMetrics := context.WithCancel(ctx)
+		defer stopMetrics()
+		go a.metrics.Run(metricsCtx)
+	}
+
+	if a.marketInvalidator != nil {
+		invalidatorCtx, stopInvalidator := context.WithCancel(ctx)
+		defer stopInvalidator()
+		go a.marketInvalidator.Run(invalidatorCtx)
+	}
+
+	if a.admin != nil {
+		adminCtx, stopAdmin := context.WithCancel(ctx)
+		defer stopAdmin()
+		go a.admin.Run(adminCtx)
+	}
+
+	if gateway := a.grpcServer.Gateway(); gateway != nil {
+		gatewayCtx, stopGateway := context.WithCancel(ctx)
+		defer stopGateway()
+		go gateway.Run(gatewayCtx)
+	}
+
 	errCh := make(chan *errs.CustomError, 1)
 	go func() {
 		errCh <- a.grpcServer.Start()
@@ -103,3 +263,39 @@ func (a *App) Start(ctx context.Context) *errs.CustomError {
 		return nil
 	}
 }
+
+// startJobs runs the background jobs, on the leader only when leader election is on.
+// The returned func stops them and, if leading, hands leadership off.
+func (a *App) startJobs(ctx context.Context) func() {
+	ctx, cancel := context.WithCancel(ctx)
+	jobs := newJobGroup(ctx, a.jobs)
+
+	if a.elector == nil {
+		jobs.start()
+		return func() {
+			cancel()
+			jobs.stop()
+		}
+	}
+
+	a.elector.OnChange(func(isLeader bool) {
+		if isLeader {
+			jobs.start()
+			return
+		}
+		jobs.stop()
+	})
+
+	electorDone := make(chan struct{})
+	go func() {
+		defer close(electorDone)
+		a.elector.Run(ctx)
+	}()
+
+	return func() {
+		cancel()
+		// the elector stops the jobs before releasing the lock
+		<-electorDone
+		jobs.stop()
+	}
+}
@@ -0,0 +1,66 @@
+package app
+
+import (
+	"context"
+	"sync"
+)
+
+// Job is a background worker that runs for the lifetime of the application.
+type Job interface {
+	Run(ctx context.Context)
+}
+
+// leaderElector reports leadership changes while Run campaigns, see connection.LeaderElector.
+type leaderElector interface {
+	OnChange(fn func(isLeader bool))
+	Run(ctx context.Context)
+}
+
+// jobGroup starts and stops the background jobs together, e.g. as leadership comes and goes.
+type jobGroup struct {
+	ctx  context.Context
+	jobs []Job
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newJobGroup(ctx context.Context, jobs []Job) *jobGroup {
+	return &jobGroup{
+		ctx:  ctx,
+		jobs: jobs,
+	}
+}
+
+func (g *jobGroup) start() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.cancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(g.ctx)
+	g.cancel = cancel
+
+	for _, job := range g.jobs {
+		g.wg.Go(func() {
+			job.Run(ctx)
+		})
+	}
+}
+
+// stop cancels the running jobs and waits until all of them returned.
+func (g *jobGroup) stop() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.cancel == nil {
+		return
+	}
+
+	g.cancel()
+	g.cancel = nil
+	g.wg.Wait()
+}
@@ -0,0 +1,132 @@
+package app
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeJob counts the runs in progress and how many were started.
+type fakeJob struct {
+	running atomic.Int32
+	started atomic.Int32
+}
+
+func (j *fakeJob) Run(ctx context.Context) {
+	j.started.Add(1)
+	j.running.Add(1)
+	defer j.running.Add(-1)
+
+	<-ctx.Done()
+}
+
+func (j *fakeJob) isRunning() bool {
+	return j.running.Load() == 1
+}
+
+// fakeElector hands leadership out when the test says so, like the elector it calls
+// the callbacks one after another.
+type fakeElector struct {
+	mu        sync.Mutex
+	callbacks []func(isLeader bool)
+	running   chan struct{}
+}
+
+func newFakeElector() *fakeElector {
+	return &fakeElector{running: make(chan struct{})}
+}
+
+func (e *fakeElector) OnChange(fn func(isLeader bool)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.callbacks = append(e.callbacks, fn)
+}
+
+func (e *fakeElector) Run(ctx context.Context) {
+	close(e.running)
+	<-ctx.Done()
+	// resigning stops the jobs like the elector does before it releases the lock
+	e.setLeader(false)
+}
+
+func (e *fakeElector) setLeader(isLeader bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, fn := range e.callbacks {
+		fn(isLeader)
+	}
+}
+
+func TestJobGroup_StartsJobsOnce(t *testing.T) {
+	job := &fakeJob{}
+	group := newJobGroup(context.Background(), []Job{job})
+
+	group.start()
+	group.start()
+	assert.Eventually(t, job.isRunning, time.Second, time.Millisecond)
+
+	group.stop()
+	assert.False(t, job.isRunning())
+	assert.Equal(t, int32(1), job.started.Load())
+
+	// stopping twice is fine and the group can start again
+	group.stop()
+	group.start()
+	assert.Eventually(t, job.isRunning, time.Second, time.Millisecond)
+	group.stop()
+	assert.Equal(t, int32(2), job.started.Load())
+}
+
+func TestJobGroup_StopsJobsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	job := &fakeJob{}
+	group := newJobGroup(ctx, []Job{job})
+
+	group.start()
+	assert.Eventually(t, job.isRunning, time.Second, time.Millisecond)
+
+	cancel()
+	assert.Eventually(t, func() bool { return !job.isRunning() }, time.Second, time.Millisecond)
+	group.stop()
+}
+
+func TestStartJobs_WithoutElector(t *testing.T) {
+	job := &fakeJob{}
+	app := &App{jobs: []Job{job}}
+
+	stop := app.startJobs(context.Background())
+	assert.Eventually(t, job.isRunning, time.Second, time.Millisecond)
+
+	stop()
+	assert.False(t, job.isRunning())
+}
+
+func TestStartJobs_FollowLeadership(t *testing.T) {
+	job := &fakeJob{}
+	elector := newFakeElector()
+	app := &App{jobs: []Job{job}, elector: elector}
+
+	stop := app.startJobs(context.Background())
+	<-elector.running
+	assert.False(t, job.isRunning())
+
+	elector.setLeader(true)
+	assert.Eventually(t, job.isRunning, time.Second, time.Millisecond)
+
+	// the lease was lost, another instance may lead now
+	elector.setLeader(false)
+	assert.False(t, job.isRunning())
+
+	elector.setLeader(true)
+	assert.Eventually(t, job.isRunning, time.Second, time.Millisecond)
+
+	stop()
+	assert.False(t, job.isRunning())
+	assert.Equal(t, int32(2), job.started.Load())
+}
@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"crypto/x509"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const uuidURNPrefix = "urn:uuid:"
+
+// PrincipalFromCertificate maps a verified client certificate to a principal. The user id
+// is taken from a urn:uuid URI SAN or, failing that, from the subject common name, and the
+// roles are the subject organizational units.
+func PrincipalFromCertificate(cert *x509.Certificate) (*Principal, error) {
+	userID, err := certificateUserID(cert)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Principal{
+		UserID: userID,
+		Roles:  cert.Subject.OrganizationalUnit,
+		Method: MethodMTLS,
+	}, nil
+}
+
+func certificateUserID(cert *x509.Certificate) (uuid.UUID, error) {
+	for _, uri := range cert.URIs {
+		if raw, ok := strings.CutPrefix(uri.String(), uuidURNPrefix); ok {
+			return uuid.Parse(raw)
+		}
+	}
+
+	if id, err := uuid.Parse(cert.Subject.CommonName); err == nil {
+		return id, nil
+	}
+
+	return uuid.Nil, errors.New("client certificate carries no user id")
+}
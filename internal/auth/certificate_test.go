@@ -0,0 +1,32 @@
+package auth_test
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/url"
+	"testing"
+
+	"OrderService/internal/auth"
+	"OrderService/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrincipalFromCertificate(t *testing.T) {
+	userID := uuid.New()
+
+	principal, err := auth.PrincipalFromCertificate(&x509.Certificate{
+		Subject: pkix.Name{CommonName: "trading-bot", OrganizationalUnit: []string{model.RoleTrader}},
+		URIs:    []*url.URL{{Scheme: "urn", Opaque: "uuid:" + userID.String()}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, userID, principal.UserID)
+	assert.Equal(t, auth.MethodMTLS, principal.Method)
+	assert.True(t, principal.HasRole(model.RoleTrader))
+
+	_, err = auth.PrincipalFromCertificate(&x509.Certificate{
+		Subject: pkix.Name{CommonName: "no-user-id"},
+	})
+	assert.Error(t, err)
+}
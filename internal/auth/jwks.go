@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+)
+
+type jwks struct {
+	Keys []jwk `json:"keys"`
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// loadJWKS reads the RSA signing keys of a JWKS document, keyed by kid.
+// Keys of other types or meant for encryption are skipped.
+func loadJWKS(path string) (map[string]*rsa.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var set jwks
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+		if k.Kid == "" {
+			return nil, errors.New("jwks key without kid")
+		}
+
+		key, err := k.rsaPublicKey()
+		if err != nil {
+			return nil, fmt.Errorf("jwks key %s: %w", k.Kid, err)
+		}
+		keys[k.Kid] = key
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("jwks has no RSA signing keys")
+	}
+
+	return keys, nil
+}
+
+func (k jwk) rsaPublicKey() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("modulus: %w", err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("exponent: %w", err)
+	}
+
+	exponent := new(big.Int).SetBytes(e)
+	if !exponent.IsInt64() || exponent.Int64() > 1<<31-1 || exponent.Int64() < 3 {
+		return nil, errors.New("exponent out of range")
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(exponent.Int64()),
+	}, nil
+}
@@ -0,0 +1,39 @@
+package auth
+
+import (
+	"context"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// Method tells how a principal proved its identity.
+type Method string
+
+const (
+	MethodJWT  Method = "jwt"
+	MethodMTLS Method = "mtls"
+)
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	UserID uuid.UUID
+	Roles  []string
+	Method Method
+}
+
+func (p *Principal) HasRole(role string) bool {
+	return slices.Contains(p.Roles, role)
+}
+
+type principalKey struct{}
+
+func NewContext(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// FromContext returns the principal the auth interceptor put into the request context.
+func FromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
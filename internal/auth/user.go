@@ -0,0 +1,24 @@
+package auth
+
+import (
+	"context"
+
+	"OrderService/internal/model"
+
+	"github.com/google/uuid"
+)
+
+type userKey struct{}
+
+// NewUserContext keeps the stored user of the caller for the rest of the request, so the
+// layers that go by the stored roles look it up once between them.
+func NewUserContext(ctx context.Context, user *model.User) context.Context {
+	return context.WithValue(ctx, userKey{}, user)
+}
+
+// UserFromContext returns the stored user with the given id when the request has looked
+// it up already.
+func UserFromContext(ctx context.Context, id uuid.UUID) (*model.User, bool) {
+	user, ok := ctx.Value(userKey{}).(*model.User)
+	return user, ok && user != nil && user.ID == id
+}
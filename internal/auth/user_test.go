@@ -0,0 +1,28 @@
+package auth_test
+
+import (
+	"context"
+	"testing"
+
+	"OrderService/internal/auth"
+	"OrderService/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserFromContext(t *testing.T) {
+	user := &model.User{ID: uuid.New(), Roles: []string{model.RoleTrader}}
+	ctx := auth.NewUserContext(context.Background(), user)
+
+	got, ok := auth.UserFromContext(ctx, user.ID)
+	assert.True(t, ok)
+	assert.Same(t, user, got)
+
+	// the requester may be someone else than the user the request looked up
+	_, ok = auth.UserFromContext(ctx, uuid.New())
+	assert.False(t, ok)
+
+	_, ok = auth.UserFromContext(context.Background(), user.ID)
+	assert.False(t, ok)
+}
@@ -0,0 +1,117 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"os"
+
+	"OrderService/config"
+
+	"github.com/erdedan1/shared/errs"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// Claims are the JWT claims the service reads: the user id in sub and the user's roles.
+type Claims struct {
+	Roles []string `json:"roles,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// Verifier checks signed bearer tokens and turns them into principals.
+type Verifier struct {
+	parser *jwt.Parser
+
+	hmacSecret []byte
+	// rsaKey is the key from the PEM file, used for tokens without a kid or with an unknown one.
+	rsaKey  *rsa.PublicKey
+	rsaKeys map[string]*rsa.PublicKey
+}
+
+func NewVerifier(cfg config.AuthConfig) (*Verifier, *errs.CustomError) {
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{cfg.Algorithm}),
+		jwt.WithExpirationRequired(),
+		jwt.WithAudience(cfg.Audience),
+		jwt.WithLeeway(cfg.Leeway),
+	}
+	if cfg.Issuer != "" {
+		options = append(options, jwt.WithIssuer(cfg.Issuer))
+	}
+
+	v := &Verifier{parser: jwt.NewParser(options...)}
+
+	switch cfg.Algorithm {
+	case config.AuthAlgorithmHS256:
+		if cfg.HMACSecret == "" {
+			return nil, errs.New(errs.INTERNAL, "HS256 needs AUTH_JWT_HMAC_SECRET")
+		}
+		v.hmacSecret = []byte(cfg.HMACSecret)
+
+	case config.AuthAlgorithmRS256:
+		if cfg.RSAPublicKeyFile == "" && cfg.JWKSFile == "" {
+			return nil, errs.New(errs.INTERNAL, "RS256 needs AUTH_JWT_RSA_PUBLIC_KEY_FILE or AUTH_JWT_JWKS_FILE")
+		}
+
+		if cfg.RSAPublicKeyFile != "" {
+			pem, err := os.ReadFile(cfg.RSAPublicKeyFile)
+			if err != nil {
+				return nil, errs.New(errs.INTERNAL, "failed to read jwt public key: %w", err)
+			}
+			if v.rsaKey, err = jwt.ParseRSAPublicKeyFromPEM(pem); err != nil {
+				return nil, errs.New(errs.INTERNAL, "failed to parse jwt public key: %w", err)
+			}
+		}
+
+		if cfg.JWKSFile != "" {
+			keys, err := loadJWKS(cfg.JWKSFile)
+			if err != nil {
+				return nil, errs.New(errs.INTERNAL, "failed to load jwks: %w", err)
+			}
+			v.rsaKeys = keys
+		}
+
+	default:
+		return nil, errs.New(errs.INTERNAL, "unsupported jwt algorithm "+cfg.Algorithm)
+	}
+
+	return v, nil
+}
+
+// Verify checks the token signature, expiry and audience and returns its principal.
+func (v *Verifier) Verify(raw string) (*Principal, error) {
+	claims := &Claims{}
+	if _, err := v.parser.ParseWithClaims(raw, claims, v.key); err != nil {
+		return nil, err
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("subject is not a user id: %w", err)
+	}
+
+	return &Principal{
+		UserID: userID,
+		Roles:  claims.Roles,
+		Method: MethodJWT,
+	}, nil
+}
+
+func (v *Verifier) key(token *jwt.Token) (any, error) {
+	if v.hmacSecret != nil {
+		return v.hmacSecret, nil
+	}
+
+	if kid, ok := token.Header["kid"].(string); ok && kid != "" {
+		if key, found := v.rsaKeys[kid]; found {
+			return key, nil
+		}
+	}
+
+	if v.rsaKey != nil {
+		return v.rsaKey, nil
+	}
+
+	return nil, errors.New("no key matches the token")
+}
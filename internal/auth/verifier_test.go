@@ -0,0 +1,122 @@
+package auth_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"OrderService/config"
+	"OrderService/internal/auth"
+	"OrderService/internal/model"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func signHS256(t *testing.T, secret string, claims auth.Claims) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	assert.NoError(t, err)
+	return token
+}
+
+func TestVerifier_HS256(t *testing.T) {
+	cfg := config.AuthConfig{
+		Algorithm:  config.AuthAlgorithmHS256,
+		HMACSecret: "secret",
+		Audience:   "order-service",
+	}
+	verifier, cerr := auth.NewVerifier(cfg)
+	assert.Nil(t, cerr)
+
+	userID := uuid.New()
+	valid := auth.Claims{
+		Roles: []string{model.RoleTrader},
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   userID.String(),
+			Audience:  jwt.ClaimStrings{"order-service"},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	}
+
+	principal, err := verifier.Verify(signHS256(t, "secret", valid))
+	assert.NoError(t, err)
+	assert.Equal(t, userID, principal.UserID)
+	assert.True(t, principal.HasRole(model.RoleTrader))
+
+	_, err = verifier.Verify(signHS256(t, "other-secret", valid))
+	assert.Error(t, err)
+
+	expired := valid
+	expired.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+	_, err = verifier.Verify(signHS256(t, "secret", expired))
+	assert.ErrorIs(t, err, jwt.ErrTokenExpired)
+
+	noExpiry := valid
+	noExpiry.ExpiresAt = nil
+	_, err = verifier.Verify(signHS256(t, "secret", noExpiry))
+	assert.Error(t, err)
+
+	wrongAudience := valid
+	wrongAudience.Audience = jwt.ClaimStrings{"billing"}
+	_, err = verifier.Verify(signHS256(t, "secret", wrongAudience))
+	assert.ErrorIs(t, err, jwt.ErrTokenInvalidAudience)
+}
+
+func TestVerifier_RS256_JWKS(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	jwks, err := json.Marshal(map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "k1",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}},
+	})
+	assert.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	assert.NoError(t, os.WriteFile(path, jwks, 0o600))
+
+	verifier, cerr := auth.NewVerifier(config.AuthConfig{
+		Algorithm: config.AuthAlgorithmRS256,
+		JWKSFile:  path,
+		Audience:  "order-service",
+	})
+	assert.Nil(t, cerr)
+
+	userID := uuid.New()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   userID.String(),
+			Audience:  jwt.ClaimStrings{"order-service"},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	})
+	token.Header["kid"] = "k1"
+	raw, err := token.SignedString(key)
+	assert.NoError(t, err)
+
+	principal, err := verifier.Verify(raw)
+	assert.NoError(t, err)
+	assert.Equal(t, userID, principal.UserID)
+
+	// an HS256 token must not pass an RS256 verifier
+	_, err = verifier.Verify(signHS256(t, "secret", auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   userID.String(),
+			Audience:  jwt.ClaimStrings{"order-service"},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	}))
+	assert.Error(t, err)
+}
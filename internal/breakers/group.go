@@ -0,0 +1,64 @@
+package breakers
+
+import (
+	"OrderService/config"
+	"OrderService/internal/metrics"
+	"OrderService/pkg/circuitbreaker"
+
+	"github.com/erdedan1/shared/errs"
+	log "github.com/erdedan1/shared/logger"
+)
+
+var stateValues = map[circuitbreaker.State]float64{
+	circuitbreaker.StateClosed:   0,
+	circuitbreaker.StateHalfOpen: 1,
+	circuitbreaker.StateOpen:     2,
+}
+
+// NewGroup builds the breakers of group from the config, with the overrides of the
+// settings file. State changes are logged and exported as metrics.
+func NewGroup(group string, cfg config.CircuitBreakerConfig, logger log.Logger) (*circuitbreaker.Group, *errs.CustomError) {
+	defaults := circuitbreaker.Settings{
+		Window:           circuitbreaker.Duration{Duration: cfg.Window},
+		WindowBuckets:    cfg.WindowBuckets,
+		MinRequests:      cfg.MinRequests,
+		FailureRatio:     cfg.FailureRatio,
+		OpenTimeout:      circuitbreaker.Duration{Duration: cfg.OpenTimeout},
+		HalfOpenRequests: cfg.HalfOpenRequests,
+	}
+
+	var overrides map[string]circuitbreaker.Settings
+	if cfg.SettingsFile != "" {
+		file, err := circuitbreaker.LoadFile(cfg.SettingsFile)
+		if err != nil {
+			return nil, errs.New(errs.INVALID_ARGUMENT, "failed to load circuit breaker settings", err)
+		}
+		defaults = defaults.Merge(file.Default)
+		overrides = file.Breakers
+	}
+
+	// the env and the file are only checked on their own, a mix of both can still be off
+	if err := defaults.Validate(); err != nil {
+		return nil, errs.New(errs.INVALID_ARGUMENT, "invalid circuit breaker settings", err)
+	}
+	for name, override := range overrides {
+		if err := defaults.Merge(override).Validate(); err != nil {
+			return nil, errs.New(errs.INVALID_ARGUMENT, "invalid circuit breaker settings of "+name, err)
+		}
+	}
+
+	onChange := func(change circuitbreaker.StateChange) {
+		const method = "onChange"
+
+		metrics.CircuitBreakerState.WithLabelValues(group, change.Name).Set(stateValues[change.To])
+		metrics.CircuitBreakerTransitions.WithLabelValues(group, change.Name, string(change.To)).Inc()
+		logger.Info("CircuitBreaker", method, "circuit breaker state changed",
+			"group", group,
+			"breaker", change.Name,
+			"from", string(change.From),
+			"to", string(change.To),
+		)
+	}
+
+	return circuitbreaker.NewGroup(defaults, overrides, onChange), nil
+}
@@ -0,0 +1,27 @@
+package breakers_test
+
+import (
+	"testing"
+	"time"
+
+	"OrderService/config"
+	"OrderService/internal/breakers"
+
+	log "github.com/erdedan1/shared/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewGroup_RejectsWindowNarrowerThanBuckets(t *testing.T) {
+	logger, _ := log.NewLogger("debug")
+
+	_, err := breakers.NewGroup("grpc_server", config.CircuitBreakerConfig{
+		Window:           5 * time.Nanosecond,
+		WindowBuckets:    10,
+		MinRequests:      1,
+		FailureRatio:     0.5,
+		HalfOpenRequests: 1,
+		OpenTimeout:      time.Second,
+	}, logger)
+
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,92 @@
+package concurrency
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Priority tells how early calls are shed. A priority may fill its share of the limit,
+// calls of a lower priority are turned away while those of a higher one still get in.
+type Priority int
+
+const (
+	PriorityLow Priority = iota
+	PriorityHigh
+)
+
+// AIMD sizes the number of calls allowed in flight from their latency, like the AIMD
+// limit of Netflix concurrency-limits. Every call finished within the latency threshold
+// while at least half of the limit was in use grows the limit by one, every slow or
+// timed out call shrinks it by the backoff ratio.
+type AIMD struct {
+	minLimit  float64
+	maxLimit  float64
+	backoff   float64
+	threshold time.Duration
+	shares    map[Priority]float64
+
+	mu       sync.Mutex
+	limit    float64
+	inFlight int
+}
+
+func NewAIMD(initialLimit, minLimit, maxLimit int, backoff float64, threshold time.Duration, lowPriorityShare float64) *AIMD {
+	return &AIMD{
+		minLimit:  float64(minLimit),
+		maxLimit:  float64(maxLimit),
+		backoff:   backoff,
+		threshold: threshold,
+		shares: map[Priority]float64{
+			PriorityLow:  lowPriorityShare,
+			PriorityHigh: 1,
+		},
+		limit: math.Min(math.Max(float64(initialLimit), float64(minLimit)), float64(maxLimit)),
+	}
+}
+
+// Acquire takes an in-flight slot unless the share of priority is used up. Every taken
+// slot must be given back with Release.
+func (a *AIMD) Acquire(priority Priority) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if float64(a.inFlight) >= math.Floor(a.limit*a.shares[priority]) {
+		return false
+	}
+	a.inFlight++
+
+	return true
+}
+
+// Release gives the slot back and adjusts the limit. dropped marks a call that failed
+// in a way that signals overload, like a deadline that ran out.
+func (a *AIMD) Release(latency time.Duration, dropped bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	inFlight := a.inFlight
+	a.inFlight--
+
+	switch {
+	case dropped || latency > a.threshold:
+		a.limit = math.Max(a.minLimit, a.limit*a.backoff)
+	case float64(inFlight)*2 >= a.limit:
+		// growing the limit only makes sense while it is actually used
+		a.limit = math.Min(a.maxLimit, a.limit+1)
+	}
+}
+
+func (a *AIMD) Limit() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return int(a.limit)
+}
+
+func (a *AIMD) InFlight() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.inFlight
+}
@@ -0,0 +1,41 @@
+package concurrency_test
+
+import (
+	"testing"
+	"time"
+
+	"OrderService/internal/concurrency"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAIMD_ShedsLowPriorityFirst(t *testing.T) {
+	limiter := concurrency.NewAIMD(10, 5, 20, 0.5, 100*time.Millisecond, 0.5)
+
+	for range 5 {
+		assert.True(t, limiter.Acquire(concurrency.PriorityLow))
+	}
+	assert.False(t, limiter.Acquire(concurrency.PriorityLow))
+
+	for range 5 {
+		assert.True(t, limiter.Acquire(concurrency.PriorityHigh))
+	}
+	assert.False(t, limiter.Acquire(concurrency.PriorityHigh))
+	assert.Equal(t, 10, limiter.InFlight())
+}
+
+func TestAIMD_AdaptsLimitToLatency(t *testing.T) {
+	limiter := concurrency.NewAIMD(10, 5, 20, 0.5, 100*time.Millisecond, 1)
+
+	for range 6 {
+		limiter.Acquire(concurrency.PriorityHigh)
+	}
+	limiter.Release(time.Millisecond, false)
+	assert.Equal(t, 11, limiter.Limit())
+
+	limiter.Release(time.Second, false)
+	assert.Equal(t, 5, limiter.Limit())
+
+	limiter.Release(time.Millisecond, true)
+	assert.Equal(t, 5, limiter.Limit(), "the limit never drops below the minimum")
+}
@@ -0,0 +1,208 @@
+package connection
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"OrderService/config"
+
+	log "github.com/erdedan1/shared/logger"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	leaderLayer = "LeaderElector"
+
+	defaultResignTimeout = time.Second * 5
+)
+
+// LeaderElector makes one instance of the service the leader by holding a Postgres
+// session-level advisory lock on a dedicated connection. The lock lives as long as
+// that session, so renewing leadership means checking the session is still alive.
+type LeaderElector struct {
+	// open starts the database session the lock is taken on.
+	open func(ctx context.Context) (lockSession, error)
+	log  log.Logger
+	cfg  config.LeaderElectionConfig
+
+	leader atomic.Bool
+	// conn holds the lock while leading, it is only touched by the Run goroutine.
+	conn lockSession
+
+	mu        sync.Mutex
+	callbacks []func(isLeader bool)
+}
+
+func NewLeaderElector(db *sqlx.DB, log log.Logger, cfg config.LeaderElectionConfig) *LeaderElector {
+	return &LeaderElector{
+		open: func(ctx context.Context) (lockSession, error) {
+			conn, err := db.Conn(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return advisoryLockSession{conn: conn}, nil
+		},
+		log: log,
+		cfg: cfg,
+	}
+}
+
+func (e *LeaderElector) IsLeader() bool {
+	return e.leader.Load()
+}
+
+// OnChange registers a callback called with the new state every time leadership is
+// gained or lost. Callbacks run on the elector goroutine, one after another, and
+// leadership is only handed off after all of them returned.
+func (e *LeaderElector) OnChange(fn func(isLeader bool)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.callbacks = append(e.callbacks, fn)
+}
+
+// Run campaigns for leadership until ctx is cancelled, then resigns.
+func (e *LeaderElector) Run(ctx context.Context) {
+	const method = "Run"
+
+	e.log.Info(leaderLayer, method, "leader election started", "lock_key", e.cfg.LockKey)
+	defer e.log.Info(leaderLayer, method, "leader election stopped", "lock_key", e.cfg.LockKey)
+
+	ticker := time.NewTicker(e.cfg.RenewInterval)
+	defer ticker.Stop()
+
+	defer e.resign()
+
+	for {
+		e.campaign(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (e *LeaderElector) campaign(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, e.cfg.RenewInterval)
+	defer cancel()
+
+	if e.IsLeader() {
+		e.renew(ctx)
+		return
+	}
+
+	e.acquire(ctx)
+}
+
+func (e *LeaderElector) acquire(ctx context.Context) {
+	const method = "acquire"
+
+	conn, err := e.open(ctx)
+	if err != nil {
+		e.log.Error(leaderLayer, method, err.Error(), err, "lock_key", e.cfg.LockKey)
+		return
+	}
+
+	acquired, err := conn.TryLock(ctx, e.cfg.LockKey)
+	if err != nil {
+		_ = conn.Close()
+		e.log.Error(leaderLayer, method, err.Error(), err, "lock_key", e.cfg.LockKey)
+		return
+	}
+
+	if !acquired {
+		_ = conn.Close()
+		return
+	}
+
+	e.conn = conn
+	e.setLeader(true)
+}
+
+func (e *LeaderElector) renew(ctx context.Context) {
+	const method = "renew"
+
+	if err := e.conn.Ping(ctx); err != nil {
+		e.log.Error(leaderLayer, method, "leader session lost", err, "lock_key", e.cfg.LockKey)
+
+		// closing the broken session releases the lock on the server side, if it is still there
+		e.setLeader(false)
+		_ = e.conn.Close()
+		e.conn = nil
+	}
+}
+
+// resign stops the leader work first and only then releases the lock,
+// so the next leader never overlaps with this one.
+func (e *LeaderElector) resign() {
+	const method = "resign"
+
+	if !e.IsLeader() {
+		return
+	}
+
+	e.setLeader(false)
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultResignTimeout)
+	defer cancel()
+
+	if err := e.conn.Unlock(ctx, e.cfg.LockKey); err != nil {
+		e.log.Error(leaderLayer, method, err.Error(), err, "lock_key", e.cfg.LockKey)
+	}
+	_ = e.conn.Close()
+	e.conn = nil
+}
+
+func (e *LeaderElector) setLeader(isLeader bool) {
+	if e.leader.Swap(isLeader) == isLeader {
+		return
+	}
+
+	e.log.Info(leaderLayer, "setLeader", "leadership changed", "is_leader", isLeader, "lock_key", e.cfg.LockKey)
+
+	e.mu.Lock()
+	callbacks := slices.Clone(e.callbacks)
+	e.mu.Unlock()
+
+	for _, fn := range callbacks {
+		fn(isLeader)
+	}
+}
+
+// lockSession is the database session holding the advisory lock, the lock is released
+// when the session ends.
+type lockSession interface {
+	TryLock(ctx context.Context, key int64) (bool, error)
+	Ping(ctx context.Context) error
+	Unlock(ctx context.Context, key int64) error
+	Close() error
+}
+
+type advisoryLockSession struct {
+	conn *sql.Conn
+}
+
+func (s advisoryLockSession) TryLock(ctx context.Context, key int64) (bool, error) {
+	var acquired bool
+	err := s.conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, key).Scan(&acquired)
+	return acquired, err
+}
+
+func (s advisoryLockSession) Ping(ctx context.Context) error {
+	return s.conn.PingContext(ctx)
+}
+
+func (s advisoryLockSession) Unlock(ctx context.Context, key int64) error {
+	_, err := s.conn.ExecContext(ctx, `SELECT pg_advisory_unlock($1)`, key)
+	return err
+}
+
+func (s advisoryLockSession) Close() error {
+	return s.conn.Close()
+}
@@ -0,0 +1,209 @@
+package connection
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"OrderService/config"
+
+	log "github.com/erdedan1/shared/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+const testLockKey = 42
+
+// fakeLockServer stands in for Postgres: one session at a time holds the advisory lock
+// and the lock goes away with the session, as soon as the server loses it.
+type fakeLockServer struct {
+	mu     sync.Mutex
+	holder *fakeLockSession
+	events []string
+}
+
+func (s *fakeLockServer) open(context.Context) (lockSession, error) {
+	return &fakeLockSession{server: s}, nil
+}
+
+func (s *fakeLockServer) record(event string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.events = append(s.events, event)
+}
+
+func (s *fakeLockServer) recorded() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string(nil), s.events...)
+}
+
+// drop ends the session of the current holder on the server side, like a network
+// partition or a terminated backend would.
+func (s *fakeLockServer) drop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.holder != nil {
+		s.holder.lost = true
+		s.holder = nil
+	}
+}
+
+type fakeLockSession struct {
+	server *fakeLockServer
+	lost   bool
+}
+
+var errSessionLost = errors.New("session lost")
+
+func (s *fakeLockSession) TryLock(_ context.Context, key int64) (bool, error) {
+	s.server.mu.Lock()
+	defer s.server.mu.Unlock()
+
+	if s.lost {
+		return false, errSessionLost
+	}
+	if s.server.holder != nil && s.server.holder != s {
+		return false, nil
+	}
+	s.server.holder = s
+	return true, nil
+}
+
+func (s *fakeLockSession) Ping(context.Context) error {
+	s.server.mu.Lock()
+	defer s.server.mu.Unlock()
+
+	if s.lost {
+		return errSessionLost
+	}
+	return nil
+}
+
+func (s *fakeLockSession) Unlock(context.Context, int64) error {
+	s.server.mu.Lock()
+	defer s.server.mu.Unlock()
+
+	if s.server.holder == s {
+		s.server.holder = nil
+		s.server.events = append(s.server.events, "unlock")
+	}
+	return nil
+}
+
+func (s *fakeLockSession) Close() error {
+	s.server.mu.Lock()
+	defer s.server.mu.Unlock()
+
+	if s.server.holder == s {
+		s.server.holder = nil
+	}
+	return nil
+}
+
+func newTestElector(server *fakeLockServer) *LeaderElector {
+	logger, _ := log.NewLogger("debug")
+
+	elector := NewLeaderElector(nil, logger, config.LeaderElectionConfig{
+		LockKey:       testLockKey,
+		RenewInterval: 10 * time.Millisecond,
+	})
+	elector.open = server.open
+
+	return elector
+}
+
+func runElector(ctx context.Context, elector *LeaderElector) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		elector.Run(ctx)
+	}()
+	return done
+}
+
+func TestLeaderElector_OnlyOneInstanceLeads(t *testing.T) {
+	server := &fakeLockServer{}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	first, second := newTestElector(server), newTestElector(server)
+	firstDone := runElector(ctx, first)
+	secondDone := runElector(ctx, second)
+
+	assert.Eventually(t, func() bool { return first.IsLeader() || second.IsLeader() }, time.Second, time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	assert.NotEqual(t, first.IsLeader(), second.IsLeader())
+
+	cancel()
+	<-firstDone
+	<-secondDone
+}
+
+func TestLeaderElector_StepsDownOnLostSession(t *testing.T) {
+	server := &fakeLockServer{}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	leader := newTestElector(server)
+	var mu sync.Mutex
+	var changes []bool
+	leader.OnChange(func(isLeader bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		changes = append(changes, isLeader)
+	})
+	leaderDone := runElector(ctx, leader)
+	assert.Eventually(t, leader.IsLeader, time.Second, time.Millisecond)
+
+	// the server dropped the session and another instance took the lock, the old leader
+	// finds out on its next renewal and must stop its jobs
+	server.drop()
+	other := newTestElector(server)
+	otherDone := runElector(ctx, other)
+	assert.Eventually(t, other.IsLeader, time.Second, time.Millisecond)
+
+	assert.Eventually(t, func() bool { return !leader.IsLeader() }, time.Second, time.Millisecond)
+	mu.Lock()
+	assert.Equal(t, []bool{true, false}, changes)
+	mu.Unlock()
+
+	cancel()
+	<-leaderDone
+	<-otherDone
+}
+
+func TestLeaderElector_StopsJobsBeforeUnlockOnCancel(t *testing.T) {
+	server := &fakeLockServer{}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	elector := newTestElector(server)
+	elector.OnChange(func(isLeader bool) {
+		if !isLeader {
+			server.record("jobs stopped")
+		}
+	})
+	done := runElector(ctx, elector)
+	assert.Eventually(t, elector.IsLeader, time.Second, time.Millisecond)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("elector did not stop")
+	}
+
+	assert.False(t, elector.IsLeader())
+	assert.Equal(t, []string{"jobs stopped", "unlock"}, server.recorded())
+
+	// the lock is free for the next instance
+	next := newTestElector(server)
+	ctx, cancel = context.WithCancel(context.Background())
+	nextDone := runElector(ctx, next)
+	assert.Eventually(t, next.IsLeader, time.Second, time.Millisecond)
+	cancel()
+	<-nextDone
+}
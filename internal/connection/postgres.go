@@ -56,6 +56,11 @@ func NewHandle(config config.PostgresDB) *sqlx.DB {
 	return client
 }
 
+// Ping checks the database answers on a pooled connection.
+func Ping(ctx context.Context, db *sqlx.DB) error {
+	return db.PingContext(ctx)
+}
+
 func getServerURI(config config.PostgresDB) string {
 	return fmt.Sprintf(
 		"postgres://%s:%s@%s:%s?sslmode=disable",
@@ -0,0 +1,13 @@
+package dto
+
+import (
+	"github.com/google/uuid"
+)
+
+type CancelOrderRequest struct {
+	// RequesterUUID is the authenticated caller, it differs from UserUUID when an admin cancels another user's order.
+	RequesterUUID uuid.UUID
+	UserUUID      uuid.UUID
+	OrderUUID     uuid.UUID
+	Reason        string
+}
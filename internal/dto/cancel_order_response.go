@@ -0,0 +1,16 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CancelOrderResponse has no proto message, the json tags are what the HTTP gateway answers with.
+type CancelOrderResponse struct {
+	OrderUUID   uuid.UUID  `json:"order_uuid"`
+	Status      string     `json:"status"`
+	Reason      string     `json:"reason"`
+	CancelledBy uuid.UUID  `json:"cancelled_by"`
+	CancelledAt *time.Time `json:"cancelled_at,omitempty"`
+}
@@ -12,12 +12,18 @@ import (
 )
 
 type CreateOrderRequest struct {
-	UserUUID   uuid.UUID
-	MarketUUID uuid.UUID
-	OrderType  string
-	UserRole   string
-	Price      decimal.Decimal
-	Quantity   int64
+	// RequesterUUID is the authenticated caller, orders can only be created for oneself.
+	RequesterUUID uuid.UUID
+	UserUUID      uuid.UUID
+	MarketUUID    uuid.UUID
+	OrderType     string
+	UserRole      string
+	Price         decimal.Decimal
+	Quantity      int64
+	// IdempotencyKey makes retries of the same request return the order created first.
+	IdempotencyKey string
+	// ClientOrderID is the client's own id for the order, unique per user.
+	ClientOrderID string
 }
 
 func (c *CreateOrderRequest) FromProto(request *pb.CreateOrderRequest) (*CreateOrderRequest, *errors.CustomError) {
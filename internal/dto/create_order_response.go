@@ -14,6 +14,8 @@ type CreateOrderResponse struct {
 	Status    string
 	CreatedAt *time.Time
 	UpdatedAt *time.Time
+	// ClientOrderID is not part of the proto message, the handler returns it in metadata.
+	ClientOrderID string
 }
 
 func (c *CreateOrderResponse) ToProto() *pb.CreateOrderResponse {
@@ -0,0 +1,12 @@
+package dto
+
+import (
+	"github.com/google/uuid"
+)
+
+// ForceOrderStatusRequest moves an order to a status on behalf of an admin, without waiting for the lifecycle.
+type ForceOrderStatusRequest struct {
+	RequesterUUID uuid.UUID
+	OrderUUID     uuid.UUID
+	Status        string
+}
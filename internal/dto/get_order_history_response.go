@@ -0,0 +1,35 @@
+package dto
+
+import (
+	"time"
+
+	"OrderService/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// OrderStatusChangeResponse has no proto message, the json tags are what the HTTP gateway answers with.
+type OrderStatusChangeResponse struct {
+	FromStatus string     `json:"from_status,omitempty"`
+	ToStatus   string     `json:"to_status"`
+	ActorType  string     `json:"actor_type"`
+	ActorUUID  *uuid.UUID `json:"actor_uuid,omitempty"`
+	ChangedAt  time.Time  `json:"changed_at"`
+}
+
+func (o *OrderStatusChangeResponse) FromModel(change *model.OrderStatusChange) *OrderStatusChangeResponse {
+	if change.FromStatus != nil {
+		o.FromStatus = change.FromStatus.ToString()
+	}
+	o.ToStatus = change.ToStatus.ToString()
+	o.ActorType = string(change.ActorType)
+	o.ActorUUID = change.ActorID
+	o.ChangedAt = change.ChangedAt
+
+	return o
+}
+
+type GetOrderHistoryResponse struct {
+	OrderUUID uuid.UUID                   `json:"order_uuid"`
+	History   []OrderStatusChangeResponse `json:"history"`
+}
@@ -9,8 +9,14 @@ import (
 )
 
 type GetOrderStatusRequest struct {
-	UserUUID  uuid.UUID
-	OrderUUID uuid.UUID
+	// RequesterUUID is the authenticated caller, it differs from UserUUID when an admin looks at another user's order.
+	RequesterUUID uuid.UUID
+	UserUUID      uuid.UUID
+	OrderUUID     uuid.UUID
+	// ResumeToken is the EventID, or the UpdatedAt in RFC 3339, of the last status the client received.
+	ResumeToken string
+	// ClientOrderID, when set, selects the order instead of OrderUUID.
+	ClientOrderID string
 }
 
 func (g *GetOrderStatusRequest) FromProto(request *pb.GetOrderStatusRequest) (*GetOrderStatusRequest, *errors.CustomError) {
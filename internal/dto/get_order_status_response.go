@@ -11,6 +11,8 @@ import (
 type GetOrderStatusResponse struct {
 	Status    string
 	UpdatedAt *time.Time
+	// EventID identifies the status event, empty for snapshots and transports without replay.
+	EventID string
 }
 
 func (g *GetOrderStatusResponse) ToProto() *pb.GetOrderStatusResponse {
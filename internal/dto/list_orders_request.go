@@ -0,0 +1,20 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ListOrdersRequest struct {
+	// RequesterUUID is the authenticated caller, it differs from UserUUID when an admin lists another user's orders.
+	RequesterUUID uuid.UUID
+	UserUUID      uuid.UUID
+	MarketUUID    uuid.UUID
+	Statuses      []string
+	OrderType     string
+	CreatedFrom   *time.Time
+	CreatedTo     *time.Time
+	Cursor        string
+	Limit         int
+}
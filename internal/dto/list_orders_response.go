@@ -0,0 +1,42 @@
+package dto
+
+import (
+	"time"
+
+	"OrderService/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// OrderResponse has no proto message, the json tags are what the HTTP gateway answers with.
+type OrderResponse struct {
+	OrderUUID  uuid.UUID       `json:"order_uuid"`
+	UserUUID   uuid.UUID       `json:"user_uuid"`
+	MarketUUID uuid.UUID       `json:"market_uuid"`
+	OrderType  string          `json:"order_type"`
+	Status     string          `json:"status"`
+	Price      decimal.Decimal `json:"price"`
+	Quantity   int64           `json:"quantity"`
+	CreatedAt  *time.Time      `json:"created_at,omitempty"`
+	UpdatedAt  *time.Time      `json:"updated_at,omitempty"`
+}
+
+func (o *OrderResponse) FromModel(order *model.Order) *OrderResponse {
+	o.OrderUUID = order.ID
+	o.UserUUID = order.UserUUID
+	o.MarketUUID = order.MarketUUID
+	o.OrderType = order.Type
+	o.Status = order.Status.ToString()
+	o.Price = order.Price
+	o.Quantity = order.Quantity
+	o.CreatedAt = order.CreatedAt
+	o.UpdatedAt = order.UpdatedAt
+
+	return o
+}
+
+type ListOrdersResponse struct {
+	Orders     []OrderResponse `json:"orders"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+}
@@ -51,9 +51,18 @@ func (v *ViewMarketsResponse) FromProto(market *pb.Market) (*ViewMarketsResponse
 	v.UUID = id
 	v.Name = market.Name
 	v.Enabled = market.Enabled
-	v.CreatedAt = new(market.CreatedAt.AsTime())
-	v.UpdatedAt = new(market.UpdatedAt.AsTime())
-	v.DeletedAt = new(market.DeletedAt.AsTime())
+	v.CreatedAt = optionalTime(market.CreatedAt)
+	v.UpdatedAt = optionalTime(market.UpdatedAt)
+	// a market that was never deleted has no deleted_at, AsTime would make it the epoch
+	v.DeletedAt = optionalTime(market.DeletedAt)
 
 	return v, nil
 }
+
+func optionalTime(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+
+	return new(ts.AsTime())
+}
@@ -11,9 +11,22 @@ var (
 	ErrNoMarketsAvailable      = errs.New(errs.NOT_FOUND, "no markets available for user")
 	ErrInvalidUserID           = errs.New(errs.PERMISSION_DENIED, "invalid user id")
 
-	ErrUserNotFound = errs.New(errs.NOT_FOUND, "user not found")
+	ErrUserNotFound       = errs.New(errs.NOT_FOUND, "user not found")
+	ErrUserAlreadyExists  = errs.New(errs.ALREADY_EXISTS, "user already exists")
+	ErrUserDeactivated    = errs.New(errs.PERMISSION_DENIED, "user is deactivated")
+	ErrPermissionDenied   = errs.New(errs.PERMISSION_DENIED, "user is not allowed to perform this action")
+	ErrFailedToCreateUser = errs.New(errs.INTERNAL, "failed to create user")
+	ErrFailedToGetUser    = errs.New(errs.INTERNAL, "failed to get user")
+	ErrFailedToUpdateUser = errs.New(errs.INTERNAL, "failed to update user")
+
+	ErrMissingToken             = errs.New(errs.UNAUTHENTICATED, "missing bearer token")
+	ErrInvalidToken             = errs.New(errs.UNAUTHENTICATED, "invalid bearer token")
+	ErrInvalidClientCertificate = errs.New(errs.UNAUTHENTICATED, "client certificate does not identify a user")
+	ErrUnauthenticated          = errs.New(errs.UNAUTHENTICATED, "request is not authenticated")
 
 	ErrMarketNotFound = errs.New(errs.NOT_FOUND, "market not found")
+	ErrMarketDisabled = errs.New(errs.FAILED_PRECONDITION, "market is disabled")
+	ErrMarketDeleted  = errs.New(errs.FAILED_PRECONDITION, "market is deleted")
 
 	ErrFailedSerializeRedis   = errs.New(errs.INTERNAL, "failed to serialize markets redis")
 	ErrUnavailableRedis       = errs.New(errs.UNAVAILABLE, "redis is unavailable")
@@ -22,4 +35,26 @@ var (
 	ErrDeleteRedis            = errs.New(errs.UNAVAILABLE, "failed to delete redis key")
 
 	ErrFailedToUpdateOrderStatus = errs.New(errs.INTERNAL, "failed to update order status")
+
+	ErrInvalidCancelReason    = errs.New(errs.INVALID_ARGUMENT, "invalid cancel reason")
+	ErrOrderCannotBeCancelled = errs.New(errs.FAILED_PRECONDITION, "order can not be cancelled in current status")
+	ErrFailedToCancelOrder    = errs.New(errs.INTERNAL, "failed to cancel order")
+
+	ErrInvalidOrderCursor = errs.New(errs.INVALID_ARGUMENT, "invalid order cursor")
+
+	ErrInvalidOutboxEvent = errs.New(errs.INTERNAL, "invalid outbox event")
+
+	ErrInvalidResumeToken = errs.New(errs.INVALID_ARGUMENT, "invalid resume token")
+	ErrResumeNotSupported = errs.New(errs.FAILED_PRECONDITION, "order status transport does not support resume tokens")
+
+	ErrInvalidIdempotencyKey  = errs.New(errs.INVALID_ARGUMENT, "invalid idempotency key")
+	ErrIdempotencyKeyConflict = errs.New(errs.ALREADY_EXISTS, "idempotency key was already used with a different request")
+
+	ErrInvalidClientOrderID       = errs.New(errs.INVALID_ARGUMENT, "invalid client order id")
+	ErrClientOrderIDAlreadyExists = errs.New(errs.ALREADY_EXISTS, "order with this client order id already exists")
 )
+
+// IllegalStatusTransition wraps a model.TransitionError so callers can still reach it with errors.As.
+func IllegalStatusTransition(err error) *errs.CustomError {
+	return errs.New(errs.FAILED_PRECONDITION, err.Error(), err)
+}
@@ -0,0 +1,132 @@
+package order_service
+
+import (
+	"context"
+	"crypto/x509"
+	"strings"
+
+	"OrderService/internal/auth"
+	errs "OrderService/internal/errors"
+
+	errors "github.com/erdedan1/shared/errs"
+	log "github.com/erdedan1/shared/logger"
+	"google.golang.org/grpc"
+	grpc_codes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	authorizationHeader = "authorization"
+	bearerPrefix        = "bearer "
+)
+
+// publicServicePrefixes are exempt from authentication: orchestrators probe health
+// anonymously and reflection only describes the API. Reflection is served only when
+// it is enabled.
+var publicServicePrefixes = []string{
+	"/grpc.health.v1.Health/",
+	"/grpc.reflection.",
+}
+
+// grpcAuthenticator verifies the bearer token of every call and puts the principal into the context.
+type grpcAuthenticator struct {
+	verifier *auth.Verifier
+	log      log.Logger
+}
+
+func newGRPCAuthenticator(verifier *auth.Verifier, log log.Logger) *grpcAuthenticator {
+	return &grpcAuthenticator{
+		verifier: verifier,
+		log:      log,
+	}
+}
+
+func (a *grpcAuthenticator) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := a.authenticate(ctx, info.FullMethod)
+		if err != nil {
+			return nil, status.Error(grpc_codes.Code(err.Code), err.Message)
+		}
+		return handler(ctx, req)
+	}
+}
+
+func (a *grpcAuthenticator) Stream() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := a.authenticate(ss.Context(), info.FullMethod)
+		if err != nil {
+			return status.Error(grpc_codes.Code(err.Code), err.Message)
+		}
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func (a *grpcAuthenticator) authenticate(ctx context.Context, fullMethod string) (context.Context, *errors.CustomError) {
+	const method = "authenticate"
+
+	for _, prefix := range publicServicePrefixes {
+		if strings.HasPrefix(fullMethod, prefix) {
+			return ctx, nil
+		}
+	}
+
+	token := bearerToken(ctx)
+	if token == "" {
+		// a caller with a verified client certificate is identified by it
+		if cert := verifiedClientCertificate(ctx); cert != nil {
+			principal, err := auth.PrincipalFromCertificate(cert)
+			if err != nil {
+				a.log.Error("GRPCAuthenticator", method, "client certificate rejected", err, "method", fullMethod, "subject", cert.Subject.String())
+				return nil, errs.ErrInvalidClientCertificate
+			}
+			return auth.NewContext(ctx, principal), nil
+		}
+
+		a.log.Debug("GRPCAuthenticator", method, "missing bearer token", "method", fullMethod)
+		return nil, errs.ErrMissingToken
+	}
+
+	principal, err := a.verifier.Verify(token)
+	if err != nil {
+		a.log.Error("GRPCAuthenticator", method, "bearer token rejected", err, "method", fullMethod)
+		return nil, errs.ErrInvalidToken
+	}
+
+	return auth.NewContext(ctx, principal), nil
+}
+
+func bearerToken(ctx context.Context) string {
+	value := incomingMetadataValue(ctx, authorizationHeader)
+	if len(value) <= len(bearerPrefix) || !strings.EqualFold(value[:len(bearerPrefix)], bearerPrefix) {
+		return ""
+	}
+
+	return strings.TrimSpace(value[len(bearerPrefix):])
+}
+
+// verifiedClientCertificate is the leaf of the client chain the TLS handshake verified, if any.
+func verifiedClientCertificate(ctx context.Context) *x509.Certificate {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return nil
+	}
+
+	return tlsInfo.State.VerifiedChains[0][0]
+}
+
+// authenticatedStream hands the context carrying the principal to stream handlers.
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
@@ -3,123 +3,65 @@ package order_service
 import (
 	"context"
 	"strings"
-	"sync"
-	"time"
+
+	"OrderService/pkg/circuitbreaker"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
-type breakerState string
-
-const (
-	breakerStateClosed   breakerState = "closed"
-	breakerStateOpen     breakerState = "open"
-	breakerStateHalfOpen breakerState = "half-open"
-)
+// serverBreakerGroup labels the breakers guarding the handlers of this server.
+const serverBreakerGroup = "grpc_server"
 
+// grpcCircuitBreaker keeps one breaker per full method name, so a method that keeps
+// failing does not take the healthy ones down with it.
 type grpcCircuitBreaker struct {
-	mu sync.Mutex
-
-	state               breakerState
-	consecutiveFailures uint32
-	openedAt            time.Time
-	halfOpenAttempts    uint32
-
-	failureThreshold uint32
-	halfOpenMaxCalls uint32
-	openTimeout      time.Duration
+	breakers *circuitbreaker.Group
 }
 
-func newGRPCCircuitBreaker(consecutiveFailures uint32, halfOpenRequests uint32, openTimeout time.Duration) *grpcCircuitBreaker {
-	return &grpcCircuitBreaker{
-		state:            breakerStateClosed,
-		failureThreshold: consecutiveFailures,
-		halfOpenMaxCalls: halfOpenRequests,
-		openTimeout:      openTimeout,
-	}
+func newGRPCCircuitBreaker(breakers *circuitbreaker.Group) *grpcCircuitBreaker {
+	return &grpcCircuitBreaker{breakers: breakers}
 }
 
 func (b *grpcCircuitBreaker) Unary() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
-		if err := b.beforeRequest(); err != nil {
-			return nil, err
+		done, err := b.breakers.Get(info.FullMethod).Allow()
+		if err != nil {
+			return nil, status.Error(codes.Unavailable, err.Error())
 		}
 
+		// deferred, see the chain in NewGRPCServer; a half-open trial that never reports
+		// back would keep the breaker open for good
+		failure := true
+		defer func() {
+			done(failure)
+		}()
+
 		response, err := handler(ctx, req)
-		b.afterRequest(err)
+		failure = shouldCountFailure(err)
 		return response, err
 	}
 }
 
 func (b *grpcCircuitBreaker) Stream() grpc.StreamServerInterceptor {
 	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
-		if err := b.beforeRequest(); err != nil {
-			return err
+		done, err := b.breakers.Get(info.FullMethod).Allow()
+		if err != nil {
+			return status.Error(codes.Unavailable, err.Error())
 		}
 
-		err := handler(srv, ss)
-		b.afterRequest(err)
-		return err
-	}
-}
-
-func (b *grpcCircuitBreaker) beforeRequest() error {
-	b.mu.Lock()
-	defer b.mu.Unlock()
-
-	now := time.Now()
-	if b.state == breakerStateOpen {
-		if now.Sub(b.openedAt) < b.openTimeout {
-			return status.Error(codes.Unavailable, "cycle breaker is open")
-		}
-
-		b.state = breakerStateHalfOpen
-		b.halfOpenAttempts = 0
-	}
+		failure := true
+		defer func() {
+			done(failure)
+		}()
 
-	if b.state == breakerStateHalfOpen {
-		if b.halfOpenAttempts >= b.halfOpenMaxCalls {
-			return status.Error(codes.Unavailable, "cycle breaker is open")
-		}
-		b.halfOpenAttempts++
-	}
-
-	return nil
-}
-
-func (b *grpcCircuitBreaker) afterRequest(err error) {
-	b.mu.Lock()
-	defer b.mu.Unlock()
-
-	if err == nil || !shouldCountFailure(err) {
-		b.consecutiveFailures = 0
-		if b.state == breakerStateHalfOpen {
-			b.state = breakerStateClosed
-			b.halfOpenAttempts = 0
-		}
-		return
-	}
-
-	if b.state == breakerStateHalfOpen {
-		b.tripOpen()
-		return
-	}
-
-	b.consecutiveFailures++
-	if b.consecutiveFailures >= b.failureThreshold {
-		b.tripOpen()
+		err = handler(srv, ss)
+		failure = shouldCountFailure(err)
+		return err
 	}
 }
 
-func (b *grpcCircuitBreaker) tripOpen() {
-	b.state = breakerStateOpen
-	b.openedAt = time.Now()
-	b.consecutiveFailures = 0
-	b.halfOpenAttempts = 0
-}
-
 func shouldCountFailure(err error) bool {
 	st, ok := status.FromError(err)
 	if !ok {
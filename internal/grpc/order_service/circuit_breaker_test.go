@@ -0,0 +1,47 @@
+package order_service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"OrderService/pkg/circuitbreaker"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+)
+
+func TestCircuitBreaker_CountsPanicAsFailure(t *testing.T) {
+	group := circuitbreaker.NewGroup(circuitbreaker.Settings{
+		Window:           circuitbreaker.Duration{Duration: time.Minute},
+		MinRequests:      1,
+		FailureRatio:     0.5,
+		OpenTimeout:      circuitbreaker.Duration{Duration: 10 * time.Millisecond},
+		HalfOpenRequests: 1,
+	}, nil, nil)
+	interceptor := newGRPCCircuitBreaker(group).Unary()
+	info := &grpc.UnaryServerInfo{FullMethod: "/order.v1.OrderService/CreateOrder"}
+
+	panicking := func(context.Context, any) (any, error) {
+		panic("handler bug")
+	}
+
+	assert.Panics(t, func() {
+		_, _ = interceptor(context.Background(), nil, info, panicking)
+	})
+	assert.Equal(t, circuitbreaker.StateOpen, group.Get(info.FullMethod).State())
+
+	// the half-open trial panics too, it must still report back and open the breaker again
+	time.Sleep(20 * time.Millisecond)
+	assert.Panics(t, func() {
+		_, _ = interceptor(context.Background(), nil, info, panicking)
+	})
+	assert.Equal(t, circuitbreaker.StateOpen, group.Get(info.FullMethod).State())
+
+	time.Sleep(20 * time.Millisecond)
+	_, err := interceptor(context.Background(), nil, info, func(context.Context, any) (any, error) {
+		return "ok", nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, circuitbreaker.StateClosed, group.Get(info.FullMethod).State())
+}
@@ -0,0 +1,76 @@
+package order_service
+
+import (
+	"context"
+	"time"
+
+	"OrderService/config"
+	"OrderService/internal/concurrency"
+	"OrderService/internal/metrics"
+
+	pbOrder "github.com/erdedan1/protocol/proto/order_service/gen/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// highPriorityMethods are the writes, shed last: a client that cannot place or cancel an
+// order, or an operator that cannot fix one, loses more than a client that has to poll
+// again.
+var highPriorityMethods = map[string]bool{
+	"/" + pbOrder.OrderService_ServiceDesc.ServiceName + "/CreateOrder":      true,
+	"/" + pbOrder.OrderService_ServiceDesc.ServiceName + "/CancelOrder":      true,
+	"/" + pbOrder.OrderService_ServiceDesc.ServiceName + "/ForceOrderStatus": true,
+}
+
+// grpcConcurrencyLimiter sheds unary calls once the adaptive in-flight limit is reached.
+// Streams are left alone, they are long-lived by design and would hold their slot for
+// as long as they are open.
+type grpcConcurrencyLimiter struct {
+	limiter *concurrency.AIMD
+}
+
+func newGRPCConcurrencyLimiter(cfg config.ConcurrencyLimiterConfig) *grpcConcurrencyLimiter {
+	l := &grpcConcurrencyLimiter{
+		limiter: concurrency.NewAIMD(
+			cfg.InitialLimit,
+			cfg.MinLimit,
+			cfg.MaxLimit,
+			cfg.BackoffRatio,
+			cfg.LatencyThreshold,
+			cfg.ReadShare,
+		),
+	}
+	metrics.ConcurrencyLimit.Set(float64(l.limiter.Limit()))
+
+	return l
+}
+
+func (l *grpcConcurrencyLimiter) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		priority, priorityLabel := concurrency.PriorityLow, "low"
+		if highPriorityMethods[info.FullMethod] {
+			priority, priorityLabel = concurrency.PriorityHigh, "high"
+		}
+
+		if !l.limiter.Acquire(priority) {
+			metrics.ConcurrencyShed.WithLabelValues(info.FullMethod, priorityLabel).Inc()
+			return nil, status.Error(codes.Unavailable, "server is overloaded, retry later")
+		}
+		metrics.ConcurrencyInFlight.Inc()
+
+		// deferred, see the chain in NewGRPCServer
+		start := time.Now()
+		dropped := true
+		defer func() {
+			l.limiter.Release(time.Since(start), dropped)
+			metrics.ConcurrencyInFlight.Dec()
+			metrics.ConcurrencyLimit.Set(float64(l.limiter.Limit()))
+		}()
+
+		response, err := handler(ctx, req)
+		dropped = status.Code(err) == codes.DeadlineExceeded
+
+		return response, err
+	}
+}
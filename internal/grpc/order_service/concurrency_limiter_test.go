@@ -0,0 +1,73 @@
+package order_service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"OrderService/config"
+	"OrderService/internal/concurrency"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestConcurrencyLimiter_ReleasesSlotOnPanic(t *testing.T) {
+	limiter := newGRPCConcurrencyLimiter(config.ConcurrencyLimiterConfig{
+		InitialLimit:     2,
+		MinLimit:         1,
+		MaxLimit:         4,
+		BackoffRatio:     0.5,
+		LatencyThreshold: time.Second,
+		ReadShare:        1,
+	})
+	interceptor := limiter.Unary()
+	info := &grpc.UnaryServerInfo{FullMethod: "/order.v1.OrderService/GetOrderStatus"}
+
+	panicking := func(context.Context, any) (any, error) {
+		panic("handler bug")
+	}
+	for range 3 {
+		assert.Panics(t, func() {
+			_, _ = interceptor(context.Background(), nil, info, panicking)
+		})
+	}
+
+	assert.Equal(t, 0, limiter.limiter.InFlight())
+	// a panic counts as a drop
+	assert.Equal(t, 1, limiter.limiter.Limit())
+
+	_, err := interceptor(context.Background(), nil, info, func(context.Context, any) (any, error) {
+		return "ok", nil
+	})
+	assert.NoError(t, err)
+}
+
+func TestConcurrencyLimiter_ShedsReadsBeforeWrites(t *testing.T) {
+	limiter := newGRPCConcurrencyLimiter(config.ConcurrencyLimiterConfig{
+		InitialLimit:     2,
+		MinLimit:         2,
+		MaxLimit:         2,
+		BackoffRatio:     0.5,
+		LatencyThreshold: time.Second,
+		ReadShare:        0.5,
+	})
+	interceptor := limiter.Unary()
+	ok := func(context.Context, any) (any, error) {
+		return "ok", nil
+	}
+
+	// a read in flight uses up the share of the reads
+	assert.True(t, limiter.limiter.Acquire(concurrency.PriorityLow))
+	defer limiter.limiter.Release(0, false)
+
+	_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/order.v1.OrderService/ListOrders"}, ok)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	for _, name := range []string{"CreateOrder", "CancelOrder", "ForceOrderStatus"} {
+		_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/order.v1.OrderService/" + name}, ok)
+		assert.NoError(t, err, name)
+	}
+}
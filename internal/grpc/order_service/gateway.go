@@ -0,0 +1,614 @@
+package order_service
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"OrderService/internal/dto"
+	errs "OrderService/internal/errors"
+
+	pb "github.com/erdedan1/protocol/proto/order_service/gen/v1"
+	sharedErrs "github.com/erdedan1/shared/errs"
+	log "github.com/erdedan1/shared/logger"
+	"github.com/google/uuid"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	grpc_codes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	gatewayLayer = "OrderGateway"
+
+	gatewayReadHeaderTimeout = 5 * time.Second
+	gatewayShutdownTimeout   = 10 * time.Second
+	gatewayMaxBodyBytes      = 1 << 20
+
+	// lastEventIDHeader is what an EventSource sends when it reconnects, it is the resume token.
+	lastEventIDHeader = "Last-Event-ID"
+	requestIDHeader   = "x-request-id"
+)
+
+// gatewayForwardedHeaders are the HTTP headers handed to the handlers as incoming metadata.
+var gatewayForwardedHeaders = []string{
+	authorizationHeader,
+	requestIDHeader,
+	idempotencyKeyHeader,
+	clientOrderIDHeader,
+	resumeTokenHeader,
+}
+
+var gatewayJSON = protojson.MarshalOptions{UseProtoNames: true}
+
+// errGatewayInvalidArgument answers requests the gateway cannot turn into a call.
+var errGatewayInvalidArgument = status.Error(grpc_codes.Code(errs.ErrInvalidArgument.Code), errs.ErrInvalidArgument.Message)
+
+// Gateway serves the order calls as JSON over HTTP, the subscription as Server-Sent Events. Every call runs through the interceptor chains
+// of the gRPC server it was built for, so it is logged, measured, recovered from panics,
+// authenticated, rate limited and circuit broken like a gRPC call.
+//
+//	POST /v1/orders                          CreateOrderRequest
+//	GET  /v1/orders?user_uuid=               ListOrders, filtered by market_uuid, status
+//	                                         (repeatable), order_type, created_from and
+//	                                         created_to (RFC 3339), paged by cursor and limit
+//	GET  /v1/orders/{order_uuid}?user_uuid=  GetOrderStatusRequest
+//	GET  /v1/orders/{order_uuid}/events      SubscribeOrderStatus as text/event-stream
+//	GET  /v1/orders/{order_uuid}/history     GetOrderHistory, ?user_uuid= like the status
+//	POST /v1/orders/{order_uuid}/cancel      {"user_uuid", "reason"}, CancelOrder
+//	POST /v1/orders/{order_uuid}/status      {"status"}, ForceOrderStatus, admins only
+type Gateway struct {
+	handler *Handler
+	unary   []grpc.UnaryServerInterceptor
+	stream  []grpc.StreamServerInterceptor
+	server  *http.Server
+	log     log.Logger
+}
+
+func newGateway(
+	address string,
+	handler *Handler,
+	unary []grpc.UnaryServerInterceptor,
+	stream []grpc.StreamServerInterceptor,
+	tlsConfig *tls.Config,
+	log log.Logger,
+) *Gateway {
+	g := &Gateway{
+		handler: handler,
+		unary:   unary,
+		stream:  stream,
+		log:     log,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/orders", g.createOrder)
+	mux.HandleFunc("GET /v1/orders", g.listOrders)
+	mux.HandleFunc("GET /v1/orders/{order_uuid}", g.getOrderStatus)
+	mux.HandleFunc("GET /v1/orders/{order_uuid}/events", g.subscribeOrderStatus)
+	mux.HandleFunc("GET /v1/orders/{order_uuid}/history", g.getOrderHistory)
+	mux.HandleFunc("POST /v1/orders/{order_uuid}/cancel", g.cancelOrder)
+	mux.HandleFunc("POST /v1/orders/{order_uuid}/status", g.forceOrderStatus)
+
+	g.server = &http.Server{
+		Addr:              address,
+		Handler:           mux,
+		TLSConfig:         tlsConfig,
+		ReadHeaderTimeout: gatewayReadHeaderTimeout,
+	}
+
+	return g
+}
+
+// Run serves the gateway until ctx is cancelled. Open event streams end with ctx.
+func (g *Gateway) Run(ctx context.Context) {
+	const method = "Run"
+
+	lis, err := net.Listen("tcp", g.server.Addr)
+	if err != nil {
+		g.log.Error(gatewayLayer, method, "failed to listen", err, "address", g.server.Addr)
+		return
+	}
+	if g.server.TLSConfig != nil {
+		lis = tls.NewListener(lis, g.server.TLSConfig)
+	}
+
+	g.server.BaseContext = func(net.Listener) context.Context { return ctx }
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), gatewayShutdownTimeout)
+		defer cancel()
+
+		if err := g.server.Shutdown(shutdownCtx); err != nil {
+			g.log.Error(gatewayLayer, method, "http gateway shutdown failed", err)
+		}
+	}()
+
+	g.log.Info(gatewayLayer, method, "http gateway started", "address", g.server.Addr)
+
+	if err := g.server.Serve(lis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		g.log.Error(gatewayLayer, method, "http gateway failed", err, "address", g.server.Addr)
+	}
+}
+
+func (g *Gateway) createOrder(w http.ResponseWriter, r *http.Request) {
+	request := &pb.CreateOrderRequest{}
+	if !g.decodeBody(w, r, request) {
+		return
+	}
+
+	g.callUnary(w, r, "CreateOrder", request, func(ctx context.Context, req any) (any, error) {
+		return g.handler.CreateOrder(ctx, req.(*pb.CreateOrderRequest))
+	})
+}
+
+func (g *Gateway) getOrderStatus(w http.ResponseWriter, r *http.Request) {
+	request := &pb.GetOrderStatusRequest{
+		OrderUuid: r.PathValue("order_uuid"),
+		UserUuid:  r.URL.Query().Get("user_uuid"),
+	}
+
+	g.callUnary(w, r, "GetOrderStatus", request, func(ctx context.Context, req any) (any, error) {
+		return g.handler.GetOrderStatus(ctx, req.(*pb.GetOrderStatusRequest))
+	})
+}
+
+// getOrderHistory reads the order like getOrderStatus, the history is looked up the same way.
+func (g *Gateway) getOrderHistory(w http.ResponseWriter, r *http.Request) {
+	request := &pb.GetOrderStatusRequest{
+		OrderUuid: r.PathValue("order_uuid"),
+		UserUuid:  r.URL.Query().Get("user_uuid"),
+	}
+
+	g.callUnary(w, r, "GetOrderHistory", request, func(ctx context.Context, req any) (any, error) {
+		return g.handler.getOrderHistory(ctx, req.(*pb.GetOrderStatusRequest))
+	})
+}
+
+type gatewayCancelOrderBody struct {
+	UserUUID string `json:"user_uuid"`
+	Reason   string `json:"reason"`
+}
+
+func (g *Gateway) cancelOrder(w http.ResponseWriter, r *http.Request) {
+	body := &gatewayCancelOrderBody{}
+	if !g.decodeBody(w, r, body) {
+		return
+	}
+
+	orderID, err := uuid.Parse(r.PathValue("order_uuid"))
+	if err != nil {
+		g.writeError(w, errGatewayInvalidArgument)
+		return
+	}
+	userID, err := uuid.Parse(body.UserUUID)
+	if err != nil {
+		g.writeError(w, errGatewayInvalidArgument)
+		return
+	}
+
+	request := &dto.CancelOrderRequest{
+		UserUUID:  userID,
+		OrderUUID: orderID,
+		Reason:    body.Reason,
+	}
+
+	g.callUnary(w, r, "CancelOrder", request, func(ctx context.Context, req any) (any, error) {
+		return g.handler.cancelOrder(ctx, req.(*dto.CancelOrderRequest))
+	})
+}
+
+type gatewayForceOrderStatusBody struct {
+	Status string `json:"status"`
+}
+
+func (g *Gateway) forceOrderStatus(w http.ResponseWriter, r *http.Request) {
+	body := &gatewayForceOrderStatusBody{}
+	if !g.decodeBody(w, r, body) {
+		return
+	}
+
+	orderID, err := uuid.Parse(r.PathValue("order_uuid"))
+	if err != nil {
+		g.writeError(w, errGatewayInvalidArgument)
+		return
+	}
+
+	request := &dto.ForceOrderStatusRequest{
+		OrderUUID: orderID,
+		Status:    body.Status,
+	}
+
+	g.callUnary(w, r, "ForceOrderStatus", request, func(ctx context.Context, req any) (any, error) {
+		return g.handler.forceOrderStatus(ctx, req.(*dto.ForceOrderStatusRequest))
+	})
+}
+
+func (g *Gateway) listOrders(w http.ResponseWriter, r *http.Request) {
+	request, ok := listOrdersRequest(r.URL.Query())
+	if !ok {
+		g.writeError(w, errGatewayInvalidArgument)
+		return
+	}
+
+	g.callUnary(w, r, "ListOrders", request, func(ctx context.Context, req any) (any, error) {
+		return g.handler.listOrders(ctx, req.(*dto.ListOrdersRequest))
+	})
+}
+
+// listOrdersRequest reads the filter of GET /v1/orders, only user_uuid is required.
+func listOrdersRequest(query url.Values) (*dto.ListOrdersRequest, bool) {
+	userID, err := uuid.Parse(query.Get("user_uuid"))
+	if err != nil {
+		return nil, false
+	}
+
+	request := &dto.ListOrdersRequest{
+		UserUUID:  userID,
+		Statuses:  query["status"],
+		OrderType: query.Get("order_type"),
+		Cursor:    query.Get("cursor"),
+	}
+
+	if value := query.Get("market_uuid"); value != "" {
+		if request.MarketUUID, err = uuid.Parse(value); err != nil {
+			return nil, false
+		}
+	}
+	if value := query.Get("created_from"); value != "" {
+		from, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, false
+		}
+		request.CreatedFrom = &from
+	}
+	if value := query.Get("created_to"); value != "" {
+		to, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, false
+		}
+		request.CreatedTo = &to
+	}
+	if value := query.Get("limit"); value != "" {
+		if request.Limit, err = strconv.Atoi(value); err != nil {
+			return nil, false
+		}
+	}
+
+	return request, true
+}
+
+func (g *Gateway) subscribeOrderStatus(w http.ResponseWriter, r *http.Request) {
+	const method = "subscribeOrderStatus"
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		g.writeError(w, status.Error(grpc_codes.Unimplemented, "streaming is not supported by the connection"))
+		return
+	}
+
+	request := &pb.GetOrderStatusRequest{
+		OrderUuid: r.PathValue("order_uuid"),
+		UserUuid:  r.URL.Query().Get("user_uuid"),
+	}
+	fullMethod := gatewayFullMethod("SubscribeOrderStatus")
+	transport := &gatewayTransportStream{method: fullMethod, header: metadata.MD{}}
+	events := &sseWriter{w: w, flusher: flusher, transport: transport}
+
+	handler := func(_ any, ss grpc.ServerStream) error {
+		return g.handler.subscribeOrderStatus(ss.Context(), request, events.send)
+	}
+	info := &grpc.StreamServerInfo{FullMethod: fullMethod, IsServerStream: true}
+	stream := &gatewayServerStream{ctx: g.incomingContext(r), transport: transport}
+
+	err := chainStreamInterceptors(g.stream, info, handler)(g.handler, stream)
+	if err == nil || r.Context().Err() != nil {
+		return
+	}
+
+	// before the first event the call can still fail with a plain HTTP error
+	if !events.started {
+		copyHeaders(w, transport)
+		g.writeError(w, err)
+		return
+	}
+
+	if err := events.sendError(err); err != nil {
+		g.log.Error(gatewayLayer, method, "failed to write error event", err)
+	}
+}
+
+// decodeBody reads a proto message with protojson and the bodies of the calls without
+// one with encoding/json.
+func (g *Gateway) decodeBody(w http.ResponseWriter, r *http.Request, request any) bool {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, gatewayMaxBodyBytes))
+	if err == nil {
+		if message, ok := request.(proto.Message); ok {
+			err = protojson.Unmarshal(body, message)
+		} else {
+			err = json.Unmarshal(body, request)
+		}
+	}
+	if err != nil {
+		g.writeError(w, errGatewayInvalidArgument)
+		return false
+	}
+
+	return true
+}
+
+func (g *Gateway) callUnary(w http.ResponseWriter, r *http.Request, name string, request any, handler grpc.UnaryHandler) {
+	const method = "callUnary"
+
+	fullMethod := gatewayFullMethod(name)
+	transport := &gatewayTransportStream{method: fullMethod, header: metadata.MD{}}
+	ctx := grpc.NewContextWithServerTransportStream(g.incomingContext(r), transport)
+	info := &grpc.UnaryServerInfo{Server: g.handler, FullMethod: fullMethod}
+
+	response, err := chainUnaryInterceptors(g.unary, info, handler)(ctx, request)
+
+	copyHeaders(w, transport)
+
+	if err != nil {
+		g.writeError(w, err)
+		return
+	}
+
+	body, err := encodeResponse(response)
+	if err != nil {
+		g.log.Error(gatewayLayer, method, "failed to encode response", err, "method", fullMethod)
+		g.writeError(w, status.Error(grpc_codes.Internal, "failed to encode response"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
+// encodeResponse writes proto messages with protojson and the responses of the calls
+// without one, which carry json tags, with encoding/json.
+func encodeResponse(response any) ([]byte, error) {
+	if message, ok := response.(proto.Message); ok {
+		return gatewayJSON.Marshal(message)
+	}
+
+	return json.Marshal(response)
+}
+
+// incomingContext turns the request into what a gRPC handler expects: the forwarded
+// headers as incoming metadata and the client address, with its TLS state, as the peer.
+func (g *Gateway) incomingContext(r *http.Request) context.Context {
+	md := metadata.MD{}
+	for _, key := range gatewayForwardedHeaders {
+		if value := r.Header.Get(key); value != "" {
+			md.Set(key, value)
+		}
+	}
+	if value := r.Header.Get(lastEventIDHeader); value != "" {
+		md.Set(resumeTokenHeader, value)
+	}
+
+	p := &peer.Peer{Addr: gatewayAddr(r.RemoteAddr)}
+	if r.TLS != nil {
+		p.AuthInfo = credentials.TLSInfo{State: *r.TLS}
+	}
+
+	return peer.NewContext(metadata.NewIncomingContext(r.Context(), md), p)
+}
+
+type gatewayErrorBody struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (g *Gateway) writeError(w http.ResponseWriter, err error) {
+	st := status.Convert(err)
+
+	for _, detail := range st.Details() {
+		if retry, ok := detail.(*errdetails.RetryInfo); ok {
+			seconds := math.Ceil(retry.GetRetryDelay().AsDuration().Seconds())
+			w.Header().Set("Retry-After", strconv.FormatFloat(seconds, 'f', -1, 64))
+		}
+	}
+
+	body, _ := json.Marshal(gatewayErrorBody{Code: st.Code().String(), Message: st.Message()})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpStatusFromCode(sharedErrs.Code(st.Code())))
+	_, _ = w.Write(body)
+}
+
+// httpStatusFromCode maps an error code to the HTTP status the gateway answers with.
+// The codes follow the gRPC numbering, so status errors convert directly.
+func httpStatusFromCode(code sharedErrs.Code) int {
+	switch code {
+	case sharedErrs.OK:
+		return http.StatusOK
+	case sharedErrs.INVALID_ARGUMENT, sharedErrs.FAILED_PRECONDITION, sharedErrs.OUT_OF_RANGE:
+		return http.StatusBadRequest
+	case sharedErrs.UNAUTHENTICATED:
+		return http.StatusUnauthorized
+	case sharedErrs.PERMISSION_DENIED:
+		return http.StatusForbidden
+	case sharedErrs.NOT_FOUND:
+		return http.StatusNotFound
+	case sharedErrs.ALREADY_EXISTS, sharedErrs.ABORTED:
+		return http.StatusConflict
+	case sharedErrs.RESOURCE_EXHAUSTED:
+		return http.StatusTooManyRequests
+	case sharedErrs.CANCELLED:
+		// the de facto "client closed request" status
+		return 499
+	case sharedErrs.UNIMPLEMENTED:
+		return http.StatusNotImplemented
+	case sharedErrs.UNAVAILABLE:
+		return http.StatusServiceUnavailable
+	case sharedErrs.DEADLINE_EXCEEDED:
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func copyHeaders(w http.ResponseWriter, transport *gatewayTransportStream) {
+	for key, values := range transport.headers() {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+}
+
+func gatewayFullMethod(name string) string {
+	return "/" + pb.OrderService_ServiceDesc.ServiceName + "/" + name
+}
+
+func chainUnaryInterceptors(interceptors []grpc.UnaryServerInterceptor, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) grpc.UnaryHandler {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], handler
+		handler = func(ctx context.Context, req any) (any, error) {
+			return interceptor(ctx, req, info, next)
+		}
+	}
+
+	return handler
+}
+
+func chainStreamInterceptors(interceptors []grpc.StreamServerInterceptor, info *grpc.StreamServerInfo, handler grpc.StreamHandler) grpc.StreamHandler {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], handler
+		handler = func(srv any, ss grpc.ServerStream) error {
+			return interceptor(srv, ss, info, next)
+		}
+	}
+
+	return handler
+}
+
+// sseWriter writes order status events as Server-Sent Events. The event id is the
+// resume token, so a reconnecting EventSource continues where it stopped.
+type sseWriter struct {
+	w         http.ResponseWriter
+	flusher   http.Flusher
+	transport *gatewayTransportStream
+	started   bool
+}
+
+func (s *sseWriter) send(order *dto.GetOrderStatusResponse) error {
+	data, err := gatewayJSON.Marshal(order.ToProto())
+	if err != nil {
+		return err
+	}
+
+	s.start()
+	if order.EventID != "" {
+		if _, err := fmt.Fprintf(s.w, "id: %s\n", order.EventID); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(s.w, "event: status\ndata: %s\n\n", data); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+
+	return nil
+}
+
+func (s *sseWriter) sendError(err error) error {
+	st := status.Convert(err)
+	data, _ := json.Marshal(gatewayErrorBody{Code: st.Code().String(), Message: st.Message()})
+
+	if _, err := fmt.Fprintf(s.w, "event: error\ndata: %s\n\n", data); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+
+	return nil
+}
+
+func (s *sseWriter) start() {
+	if s.started {
+		return
+	}
+	s.started = true
+
+	copyHeaders(s.w, s.transport)
+	s.w.Header().Set("Content-Type", "text/event-stream")
+	s.w.Header().Set("Cache-Control", "no-cache")
+	s.w.Header().Set("X-Accel-Buffering", "no")
+	s.w.WriteHeader(http.StatusOK)
+}
+
+// gatewayTransportStream collects the headers a unary handler sets with grpc.SetHeader.
+type gatewayTransportStream struct {
+	method string
+
+	mu     sync.Mutex
+	header metadata.MD
+}
+
+func (s *gatewayTransportStream) Method() string {
+	return s.method
+}
+
+func (s *gatewayTransportStream) SetHeader(md metadata.MD) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+func (s *gatewayTransportStream) SendHeader(md metadata.MD) error {
+	return s.SetHeader(md)
+}
+
+// SetTrailer folds the trailer into the headers, like the x-ratelimit quota, the HTTP
+// responses of the gateway are not chunked and have no trailer.
+func (s *gatewayTransportStream) SetTrailer(md metadata.MD) error {
+	return s.SetHeader(md)
+}
+
+func (s *gatewayTransportStream) headers() metadata.MD {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.header.Copy()
+}
+
+// gatewayServerStream stands in for the gRPC stream in the stream interceptors, messages
+// go out through the sseWriter instead. Headers and trailers set by the interceptors end
+// up in the HTTP response headers.
+type gatewayServerStream struct {
+	ctx       context.Context
+	transport *gatewayTransportStream
+}
+
+func (s *gatewayServerStream) SetHeader(md metadata.MD) error  { return s.transport.SetHeader(md) }
+func (s *gatewayServerStream) SendHeader(md metadata.MD) error { return s.transport.SetHeader(md) }
+func (s *gatewayServerStream) SetTrailer(md metadata.MD)       { _ = s.transport.SetHeader(md) }
+func (s *gatewayServerStream) Context() context.Context        { return s.ctx }
+func (s *gatewayServerStream) SendMsg(any) error               { return nil }
+func (s *gatewayServerStream) RecvMsg(any) error               { return io.EOF }
+
+// gatewayAddr is the remote address of an HTTP client as a net.Addr.
+type gatewayAddr string
+
+func (a gatewayAddr) Network() string { return "tcp" }
+func (a gatewayAddr) String() string  { return string(a) }
@@ -0,0 +1,148 @@
+package order_service
+
+import (
+	"context"
+
+	"OrderService/internal/dto"
+
+	pb "github.com/erdedan1/protocol/proto/order_service/gen/v1"
+	"go.opentelemetry.io/otel/codes"
+	grpc_codes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// The proto has no RPCs for the calls below yet, only the HTTP gateway serves them.
+// They fill in the authenticated requester like the RPC handlers do.
+
+func (h *Handler) cancelOrder(ctx context.Context, request *dto.CancelOrderRequest) (*dto.CancelOrderResponse, error) {
+	const method = "CancelOrder"
+
+	ctx, span := h.tracer.Start(ctx, "OrderHandler.CancelOrder")
+	defer span.End()
+
+	requesterID, err := requesterFromContext(ctx)
+	if err != nil {
+		return nil, status.Error(grpc_codes.Code(err.Code), err.Message)
+	}
+
+	request.RequesterUUID = requesterID
+
+	order, err := h.orderService.CancelOrder(ctx, request)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Message)
+
+		h.log.Error(
+			layer, method,
+			err.Error(), err,
+		)
+		return nil, status.Error(grpc_codes.Code(err.Code), err.Message)
+	}
+
+	span.SetStatus(codes.Ok, "order cancelled")
+
+	return order, nil
+}
+
+func (h *Handler) listOrders(ctx context.Context, request *dto.ListOrdersRequest) (*dto.ListOrdersResponse, error) {
+	const method = "ListOrders"
+
+	ctx, span := h.tracer.Start(ctx, "OrderHandler.ListOrders")
+	defer span.End()
+
+	requesterID, err := requesterFromContext(ctx)
+	if err != nil {
+		return nil, status.Error(grpc_codes.Code(err.Code), err.Message)
+	}
+
+	request.RequesterUUID = requesterID
+
+	orders, err := h.orderService.ListOrders(ctx, request)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Message)
+
+		h.log.Error(
+			layer, method,
+			err.Error(), err,
+		)
+		return nil, status.Error(grpc_codes.Code(err.Code), err.Message)
+	}
+
+	span.SetStatus(codes.Ok, "list orders success")
+
+	return orders, nil
+}
+
+func (h *Handler) getOrderHistory(ctx context.Context, request *pb.GetOrderStatusRequest) (*dto.GetOrderHistoryResponse, error) {
+	const method = "GetOrderHistory"
+
+	ctx, span := h.tracer.Start(ctx, "OrderHandler.GetOrderHistory")
+	defer span.End()
+
+	requesterID, err := requesterFromContext(ctx)
+	if err != nil {
+		return nil, status.Error(grpc_codes.Code(err.Code), err.Message)
+	}
+
+	dto, err := new(dto.GetOrderStatusRequest).FromProto(request)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Message)
+
+		h.log.Error(
+			layer, method,
+			err.Error(), err,
+		)
+		return nil, status.Error(grpc_codes.Code(err.Code), err.Message)
+	}
+
+	dto.RequesterUUID = requesterID
+	dto.ClientOrderID = clientOrderIDFromContext(ctx)
+
+	history, err := h.orderService.GetOrderHistory(ctx, dto)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Message)
+
+		h.log.Error(
+			layer, method,
+			err.Error(), err,
+		)
+		return nil, status.Error(grpc_codes.Code(err.Code), err.Message)
+	}
+
+	span.SetStatus(codes.Ok, "get order history success")
+
+	return history, nil
+}
+
+func (h *Handler) forceOrderStatus(ctx context.Context, request *dto.ForceOrderStatusRequest) (*pb.GetOrderStatusResponse, error) {
+	const method = "ForceOrderStatus"
+
+	ctx, span := h.tracer.Start(ctx, "OrderHandler.ForceOrderStatus")
+	defer span.End()
+
+	requesterID, err := requesterFromContext(ctx)
+	if err != nil {
+		return nil, status.Error(grpc_codes.Code(err.Code), err.Message)
+	}
+
+	request.RequesterUUID = requesterID
+
+	order, err := h.orderService.ForceOrderStatus(ctx, request)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Message)
+
+		h.log.Error(
+			layer, method,
+			err.Error(), err,
+		)
+		return nil, status.Error(grpc_codes.Code(err.Code), err.Message)
+	}
+
+	span.SetStatus(codes.Ok, "order status forced")
+
+	return order.ToProto(), nil
+}
@@ -0,0 +1,440 @@
+package order_service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"OrderService/config"
+	"OrderService/internal/auth"
+	"OrderService/internal/dto"
+	errs "OrderService/internal/errors"
+	"OrderService/internal/model"
+	"OrderService/internal/ratelimit"
+	"OrderService/mocks"
+
+	log "github.com/erdedan1/shared/logger"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const gatewayTestSecret = "gateway-secret"
+
+// newTestGateway serves the gateway of a server built the way the app builds it, so the
+// calls run through the whole interceptor chain.
+func newTestGateway(t *testing.T) (*httptest.Server, *mocks.OrderService) {
+	t.Helper()
+
+	orderService := mocks.NewOrderService(t)
+	logger, _ := log.NewLogger("debug")
+
+	server, err := NewGRPCServer(
+		config.GRPCServerConfig{
+			Address:             "127.0.0.1:0",
+			EnableGateway:       true,
+			GatewayListenAddr:   "127.0.0.1:0",
+			HealthCheckInterval: time.Second,
+			HealthCheckTimeout:  time.Second,
+		},
+		orderService,
+		mocks.NewUserRepo(t),
+		ratelimit.NewMemory(time.Minute),
+		logger,
+		noop.NewTracerProvider(),
+		config.InfrastructureConfig{
+			RateLimiter: config.RateLimiterConfig{
+				GlobalRequestsPerSecond: 1000,
+				GlobalBurst:             1000,
+				ClientRequestsPerSecond: 1000,
+				ClientBurst:             1000,
+			},
+			CircuitBreaker: config.CircuitBreakerConfig{
+				Window:           10 * time.Second,
+				WindowBuckets:    10,
+				MinRequests:      20,
+				FailureRatio:     0.5,
+				HalfOpenRequests: 3,
+				OpenTimeout:      10 * time.Second,
+			},
+			Auth: config.AuthConfig{
+				Algorithm:  config.AuthAlgorithmHS256,
+				HMACSecret: gatewayTestSecret,
+				Audience:   "order-service",
+			},
+		},
+	)
+	assert.Nil(t, err)
+
+	ts := httptest.NewServer(server.Gateway().server.Handler)
+	t.Cleanup(ts.Close)
+
+	return ts, orderService
+}
+
+func bearer(t *testing.T, userID uuid.UUID) string {
+	t.Helper()
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, auth.Claims{
+		Roles: []string{model.RoleTrader},
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   userID.String(),
+			Audience:  jwt.ClaimStrings{"order-service"},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	}).SignedString([]byte(gatewayTestSecret))
+	assert.NoError(t, err)
+
+	return "Bearer " + token
+}
+
+func gatewayRequest(t *testing.T, method, url, body string, userID uuid.UUID) *http.Response {
+	t.Helper()
+
+	request, err := http.NewRequest(method, url, strings.NewReader(body))
+	assert.NoError(t, err)
+	request.Header.Set("Authorization", bearer(t, userID))
+	request.Header.Set("X-Idempotency-Key", "key-1")
+
+	response, err := http.DefaultClient.Do(request)
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = response.Body.Close() })
+
+	return response
+}
+
+func TestGateway_CreateOrder(t *testing.T) {
+	ts, orderService := newTestGateway(t)
+
+	userID := uuid.New()
+	marketID := uuid.New()
+	orderID := uuid.New()
+
+	orderService.On("CreateOrder", mock.Anything, mock.MatchedBy(func(request *dto.CreateOrderRequest) bool {
+		return request.RequesterUUID == userID &&
+			request.UserUUID == userID &&
+			request.MarketUUID == marketID &&
+			request.Price.String() == "120.5" &&
+			request.IdempotencyKey == "key-1"
+	})).
+		Return(&dto.CreateOrderResponse{OrderUUID: orderID, Status: model.StatusCreated.ToString(), ClientOrderID: "client-1"}, nil)
+
+	body := `{"user_uuid":"` + userID.String() + `","market_uuid":"` + marketID.String() + `","price":{"value":"120.5"},"quantity":1}`
+	response := gatewayRequest(t, http.MethodPost, ts.URL+"/v1/orders", body, userID)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "client-1", response.Header.Get(clientOrderIDHeader))
+
+	var created map[string]any
+	assert.NoError(t, json.NewDecoder(response.Body).Decode(&created))
+	assert.Equal(t, orderID.String(), created["order_uuid"])
+}
+
+func TestGateway_CreateOrder_Unauthenticated(t *testing.T) {
+	ts, _ := newTestGateway(t)
+
+	response, err := http.Post(ts.URL+"/v1/orders", "application/json", strings.NewReader(`{}`))
+	assert.NoError(t, err)
+	defer response.Body.Close()
+
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+}
+
+func TestGateway_GetOrderStatus(t *testing.T) {
+	ts, orderService := newTestGateway(t)
+
+	userID := uuid.New()
+	orderID := uuid.New()
+	updatedAt := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+
+	orderService.On("GetOrderStatus", mock.Anything, mock.MatchedBy(func(request *dto.GetOrderStatusRequest) bool {
+		return request.RequesterUUID == userID && request.UserUUID == userID && request.OrderUUID == orderID
+	})).
+		Return(&dto.GetOrderStatusResponse{Status: model.StatusPaid.ToString(), UpdatedAt: &updatedAt}, nil)
+
+	response := gatewayRequest(t, http.MethodGet, ts.URL+"/v1/orders/"+orderID.String()+"?user_uuid="+userID.String(), "", userID)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	var got map[string]any
+	assert.NoError(t, json.NewDecoder(response.Body).Decode(&got))
+	assert.Equal(t, "2026-10-18T09:00:00Z", got["updated_at"])
+}
+
+func TestGateway_GetOrderStatus_RecoversFromPanic(t *testing.T) {
+	ts, orderService := newTestGateway(t)
+
+	userID := uuid.New()
+	orderService.On("GetOrderStatus", mock.Anything, mock.Anything).
+		Run(func(mock.Arguments) { panic("handler bug") })
+
+	response := gatewayRequest(t, http.MethodGet, ts.URL+"/v1/orders/"+uuid.NewString()+"?user_uuid="+userID.String(), "", userID)
+	assert.Equal(t, http.StatusInternalServerError, response.StatusCode)
+
+	// the gateway keeps serving
+	orderService.ExpectedCalls = nil
+	orderService.On("GetOrderStatus", mock.Anything, mock.Anything).
+		Return(&dto.GetOrderStatusResponse{Status: model.StatusPaid.ToString()}, nil)
+
+	response = gatewayRequest(t, http.MethodGet, ts.URL+"/v1/orders/"+uuid.NewString()+"?user_uuid="+userID.String(), "", userID)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+}
+
+func TestGateway_SubscribeOrderStatus_ResumesFromLastEventID(t *testing.T) {
+	ts, orderService := newTestGateway(t)
+
+	userID := uuid.New()
+	orderID := uuid.New()
+	paidAt := time.Now()
+
+	events := make(chan *dto.GetOrderStatusResponse, 2)
+	events <- &dto.GetOrderStatusResponse{Status: model.StatusPaid.ToString(), UpdatedAt: &paidAt, EventID: "2-0"}
+	events <- &dto.GetOrderStatusResponse{Status: model.StatusClosed.ToString(), UpdatedAt: &paidAt, EventID: "3-0"}
+	close(events)
+
+	orderService.On("SubscribeOrderStatus", mock.Anything, mock.MatchedBy(func(request *dto.GetOrderStatusRequest) bool {
+		return request.OrderUUID == orderID && request.ResumeToken == "1-0"
+	})).
+		Return((<-chan *dto.GetOrderStatusResponse)(events), nil)
+
+	request, err := http.NewRequest(http.MethodGet, ts.URL+"/v1/orders/"+orderID.String()+"/events?user_uuid="+userID.String(), nil)
+	assert.NoError(t, err)
+	request.Header.Set("Authorization", bearer(t, userID))
+	// what an EventSource sends when it reconnects
+	request.Header.Set(lastEventIDHeader, "1-0")
+
+	response, err := http.DefaultClient.Do(request)
+	assert.NoError(t, err)
+	defer response.Body.Close()
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "text/event-stream", response.Header.Get("Content-Type"))
+
+	var ids, kinds []string
+	scanner := bufio.NewScanner(response.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if id, ok := strings.CutPrefix(line, "id: "); ok {
+			ids = append(ids, id)
+		}
+		if kind, ok := strings.CutPrefix(line, "event: "); ok {
+			kinds = append(kinds, kind)
+		}
+	}
+
+	assert.Equal(t, []string{"2-0", "3-0"}, ids)
+	assert.Equal(t, []string{"status", "status"}, kinds)
+}
+
+func TestGateway_SubscribeOrderStatus_RecoversFromPanic(t *testing.T) {
+	ts, orderService := newTestGateway(t)
+
+	userID := uuid.New()
+	orderService.On("SubscribeOrderStatus", mock.Anything, mock.Anything).
+		Run(func(mock.Arguments) { panic("handler bug") })
+
+	request, err := http.NewRequestWithContext(context.Background(), http.MethodGet, ts.URL+"/v1/orders/"+uuid.NewString()+"/events?user_uuid="+userID.String(), nil)
+	assert.NoError(t, err)
+	request.Header.Set("Authorization", bearer(t, userID))
+
+	response, err := http.DefaultClient.Do(request)
+	assert.NoError(t, err)
+	defer response.Body.Close()
+
+	assert.Equal(t, http.StatusInternalServerError, response.StatusCode)
+}
+
+func TestGateway_CancelOrder(t *testing.T) {
+	ts, orderService := newTestGateway(t)
+
+	userID := uuid.New()
+	orderID := uuid.New()
+	cancelledAt := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+
+	orderService.On("CancelOrder", mock.Anything, mock.MatchedBy(func(request *dto.CancelOrderRequest) bool {
+		return request.RequesterUUID == userID &&
+			request.UserUUID == userID &&
+			request.OrderUUID == orderID &&
+			request.Reason == string(model.CancelReasonUserRequested)
+	})).
+		Return(&dto.CancelOrderResponse{
+			OrderUUID:   orderID,
+			Status:      model.StatusCancelled.ToString(),
+			Reason:      string(model.CancelReasonUserRequested),
+			CancelledBy: userID,
+			CancelledAt: &cancelledAt,
+		}, nil)
+
+	body := `{"user_uuid":"` + userID.String() + `","reason":"USER_REQUESTED"}`
+	response := gatewayRequest(t, http.MethodPost, ts.URL+"/v1/orders/"+orderID.String()+"/cancel", body, userID)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	var cancelled map[string]any
+	assert.NoError(t, json.NewDecoder(response.Body).Decode(&cancelled))
+	assert.Equal(t, orderID.String(), cancelled["order_uuid"])
+	assert.Equal(t, model.StatusCancelled.ToString(), cancelled["status"])
+	assert.Equal(t, "2026-10-18T09:00:00Z", cancelled["cancelled_at"])
+}
+
+func TestGateway_CancelOrder_InvalidOrderUUID(t *testing.T) {
+	ts, _ := newTestGateway(t)
+
+	userID := uuid.New()
+	body := `{"user_uuid":"` + userID.String() + `","reason":"USER_REQUESTED"}`
+	response := gatewayRequest(t, http.MethodPost, ts.URL+"/v1/orders/not-a-uuid/cancel", body, userID)
+
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+}
+
+func TestGateway_CancelOrder_Unauthenticated(t *testing.T) {
+	ts, _ := newTestGateway(t)
+
+	body := `{"user_uuid":"` + uuid.NewString() + `","reason":"USER_REQUESTED"}`
+	response, err := http.Post(ts.URL+"/v1/orders/"+uuid.NewString()+"/cancel", "application/json", strings.NewReader(body))
+	assert.NoError(t, err)
+	defer response.Body.Close()
+
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+}
+
+func TestGateway_ListOrders(t *testing.T) {
+	ts, orderService := newTestGateway(t)
+
+	userID := uuid.New()
+	marketID := uuid.New()
+	orderID := uuid.New()
+	createdFrom := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	orderService.On("ListOrders", mock.Anything, mock.MatchedBy(func(request *dto.ListOrdersRequest) bool {
+		return request.RequesterUUID == userID &&
+			request.UserUUID == userID &&
+			request.MarketUUID == marketID &&
+			assert.ObjectsAreEqual([]string{"CREATED", "PAID"}, request.Statuses) &&
+			request.CreatedFrom != nil && request.CreatedFrom.Equal(createdFrom) &&
+			request.CreatedTo == nil &&
+			request.Cursor == "page-2" &&
+			request.Limit == 10
+	})).
+		Return(&dto.ListOrdersResponse{
+			Orders:     []dto.OrderResponse{{OrderUUID: orderID, UserUUID: userID, MarketUUID: marketID, Status: model.StatusPaid.ToString()}},
+			NextCursor: "page-3",
+		}, nil)
+
+	query := "?user_uuid=" + userID.String() +
+		"&market_uuid=" + marketID.String() +
+		"&status=CREATED&status=PAID" +
+		"&created_from=" + createdFrom.Format(time.RFC3339) +
+		"&cursor=page-2&limit=10"
+	response := gatewayRequest(t, http.MethodGet, ts.URL+"/v1/orders"+query, "", userID)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	var listed struct {
+		Orders []struct {
+			OrderUUID string `json:"order_uuid"`
+			Status    string `json:"status"`
+		} `json:"orders"`
+		NextCursor string `json:"next_cursor"`
+	}
+	assert.NoError(t, json.NewDecoder(response.Body).Decode(&listed))
+	assert.Len(t, listed.Orders, 1)
+	assert.Equal(t, orderID.String(), listed.Orders[0].OrderUUID)
+	assert.Equal(t, model.StatusPaid.ToString(), listed.Orders[0].Status)
+	assert.Equal(t, "page-3", listed.NextCursor)
+}
+
+func TestGateway_ListOrders_InvalidFilter(t *testing.T) {
+	ts, _ := newTestGateway(t)
+
+	userID := uuid.New()
+	for name, query := range map[string]string{
+		"no user":      "",
+		"market":       "?user_uuid=" + userID.String() + "&market_uuid=nope",
+		"created_from": "?user_uuid=" + userID.String() + "&created_from=yesterday",
+		"limit":        "?user_uuid=" + userID.String() + "&limit=ten",
+	} {
+		t.Run(name, func(t *testing.T) {
+			response := gatewayRequest(t, http.MethodGet, ts.URL+"/v1/orders"+query, "", userID)
+			assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+		})
+	}
+}
+
+func TestGateway_GetOrderHistory(t *testing.T) {
+	ts, orderService := newTestGateway(t)
+
+	userID := uuid.New()
+	orderID := uuid.New()
+	createdAt := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+
+	orderService.On("GetOrderHistory", mock.Anything, mock.MatchedBy(func(request *dto.GetOrderStatusRequest) bool {
+		return request.RequesterUUID == userID && request.UserUUID == userID && request.OrderUUID == orderID
+	})).
+		Return(&dto.GetOrderHistoryResponse{
+			OrderUUID: orderID,
+			History: []dto.OrderStatusChangeResponse{
+				{ToStatus: model.StatusCreated.ToString(), ActorType: "USER", ActorUUID: &userID, ChangedAt: createdAt},
+				{FromStatus: model.StatusCreated.ToString(), ToStatus: model.StatusPaid.ToString(), ActorType: "SYSTEM", ChangedAt: createdAt.Add(time.Minute)},
+			},
+		}, nil)
+
+	response := gatewayRequest(t, http.MethodGet, ts.URL+"/v1/orders/"+orderID.String()+"/history?user_uuid="+userID.String(), "", userID)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	var history struct {
+		OrderUUID string           `json:"order_uuid"`
+		History   []map[string]any `json:"history"`
+	}
+	assert.NoError(t, json.NewDecoder(response.Body).Decode(&history))
+	assert.Equal(t, orderID.String(), history.OrderUUID)
+	assert.Len(t, history.History, 2)
+	assert.Equal(t, model.StatusCreated.ToString(), history.History[0]["to_status"])
+	assert.NotContains(t, history.History[0], "from_status")
+	assert.Equal(t, userID.String(), history.History[0]["actor_uuid"])
+	assert.Equal(t, model.StatusCreated.ToString(), history.History[1]["from_status"])
+	assert.Equal(t, "2026-10-18T09:01:00Z", history.History[1]["changed_at"])
+}
+
+func TestGateway_ForceOrderStatus(t *testing.T) {
+	ts, orderService := newTestGateway(t)
+
+	adminID := uuid.New()
+	orderID := uuid.New()
+	updatedAt := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+
+	orderService.On("ForceOrderStatus", mock.Anything, mock.MatchedBy(func(request *dto.ForceOrderStatusRequest) bool {
+		return request.RequesterUUID == adminID && request.OrderUUID == orderID && request.Status == model.StatusClosed.ToString()
+	})).
+		Return(&dto.GetOrderStatusResponse{Status: model.StatusClosed.ToString(), UpdatedAt: &updatedAt}, nil)
+
+	body := `{"status":"` + model.StatusClosed.ToString() + `"}`
+	response := gatewayRequest(t, http.MethodPost, ts.URL+"/v1/orders/"+orderID.String()+"/status", body, adminID)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	var got map[string]any
+	assert.NoError(t, json.NewDecoder(response.Body).Decode(&got))
+	assert.Equal(t, "2026-10-18T09:00:00Z", got["updated_at"])
+}
+
+func TestGateway_ForceOrderStatus_PermissionDenied(t *testing.T) {
+	ts, orderService := newTestGateway(t)
+
+	userID := uuid.New()
+	orderService.On("ForceOrderStatus", mock.Anything, mock.Anything).
+		Return(nil, errs.ErrPermissionDenied)
+
+	body := `{"status":"` + model.StatusClosed.ToString() + `"}`
+	response := gatewayRequest(t, http.MethodPost, ts.URL+"/v1/orders/"+uuid.NewString()+"/status", body, userID)
+
+	assert.Equal(t, http.StatusForbidden, response.StatusCode)
+}
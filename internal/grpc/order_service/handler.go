@@ -2,16 +2,21 @@ package order_service
 
 import (
 	"context"
-	"fmt"
 
+	"OrderService/internal/auth"
 	"OrderService/internal/dto"
+	errs "OrderService/internal/errors"
 	"OrderService/internal/usecase"
 
 	pb "github.com/erdedan1/protocol/proto/order_service/gen/v1"
+	errors "github.com/erdedan1/shared/errs"
 	log "github.com/erdedan1/shared/logger"
+	"github.com/google/uuid"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
 	grpc_codes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
@@ -39,8 +44,9 @@ func (h *Handler) CreateOrder(ctx context.Context, request *pb.CreateOrderReques
 	ctx, span := h.tracer.Start(ctx, "OrderHandler.CreateOrder")
 	defer span.End()
 
-	if !checkUser(ctx, request.UserUuid) {
-		return nil, status.Error(3, "invalid x-user-uuid")
+	requesterID, err := requesterFromContext(ctx)
+	if err != nil {
+		return nil, status.Error(grpc_codes.Code(err.Code), err.Message)
 	}
 
 	dto, err := new(dto.CreateOrderRequest).FromProto(request)
@@ -55,6 +61,10 @@ func (h *Handler) CreateOrder(ctx context.Context, request *pb.CreateOrderReques
 		return nil, status.Error(grpc_codes.Code(err.Code), err.Message)
 	}
 
+	dto.RequesterUUID = requesterID
+	dto.IdempotencyKey = idempotencyKeyFromContext(ctx)
+	dto.ClientOrderID = clientOrderIDFromContext(ctx)
+
 	order, err := h.orderService.CreateOrder(ctx, dto)
 	if err != nil {
 		span.RecordError(err)
@@ -67,6 +77,10 @@ func (h *Handler) CreateOrder(ctx context.Context, request *pb.CreateOrderReques
 		return nil, status.Error(grpc_codes.Code(err.Code), err.Message)
 	}
 
+	if order.ClientOrderID != "" {
+		_ = grpc.SetHeader(ctx, metadata.Pairs(clientOrderIDHeader, order.ClientOrderID))
+	}
+
 	span.SetStatus(codes.Ok, "order success created")
 
 	return order.ToProto(), nil
@@ -78,8 +92,9 @@ func (h *Handler) GetOrderStatus(ctx context.Context, request *pb.GetOrderStatus
 	ctx, span := h.tracer.Start(ctx, "OrderHandler.GetOrderStatus")
 	defer span.End()
 
-	if !checkUser(ctx, request.UserUuid) {
-		return nil, status.Error(3, "invalid x-user-uuid")
+	requesterID, err := requesterFromContext(ctx)
+	if err != nil {
+		return nil, status.Error(grpc_codes.Code(err.Code), err.Message)
 	}
 
 	dto, err := new(dto.GetOrderStatusRequest).FromProto(request)
@@ -94,6 +109,9 @@ func (h *Handler) GetOrderStatus(ctx context.Context, request *pb.GetOrderStatus
 		return nil, status.Error(grpc_codes.Code(err.Code), err.Message)
 	}
 
+	dto.RequesterUUID = requesterID
+	dto.ClientOrderID = clientOrderIDFromContext(ctx)
+
 	order, err := h.orderService.GetOrderStatus(ctx, dto)
 	if err != nil {
 		span.RecordError(err)
@@ -112,17 +130,40 @@ func (h *Handler) GetOrderStatus(ctx context.Context, request *pb.GetOrderStatus
 }
 
 func (h *Handler) SubscribeOrderStatus(request *pb.GetOrderStatusRequest, stream pb.OrderService_SubscribeOrderStatusServer) error {
-	const method = "SubscribeOrderStatus"
+	// the response message has no room for the event id. The updated_at of every message
+	// is the time its event occurred at, which resumes after it as well, and the id of the
+	// last one delivered is handed back in the trailer
+	var lastEventID string
+	defer func() {
+		if lastEventID != "" {
+			stream.SetTrailer(metadata.Pairs(resumeTokenHeader, lastEventID))
+		}
+	}()
 
-	ctx := stream.Context()
+	return h.subscribeOrderStatus(stream.Context(), request, func(order *dto.GetOrderStatusResponse) error {
+		if err := stream.Send(order.ToProto()); err != nil {
+			return err
+		}
+		if order.EventID != "" {
+			lastEventID = order.EventID
+		}
+		return nil
+	})
+}
 
-	if !checkUser(ctx, request.UserUuid) {
-		return status.Error(3, "invalid x-user-uuid")
-	}
+// subscribeOrderStatus hands every status event of the order to send until the
+// subscription ends. The gRPC stream and the HTTP gateway only differ in send.
+func (h *Handler) subscribeOrderStatus(ctx context.Context, request *pb.GetOrderStatusRequest, send func(*dto.GetOrderStatusResponse) error) error {
+	const method = "SubscribeOrderStatus"
 
 	ctx, span := h.tracer.Start(ctx, "OrderHandler.SubscribeOrderStatus")
 	defer span.End()
 
+	requesterID, err := requesterFromContext(ctx)
+	if err != nil {
+		return status.Error(grpc_codes.Code(err.Code), err.Message)
+	}
+
 	dto, err := new(dto.GetOrderStatusRequest).FromProto(request)
 	if err != nil {
 		span.RecordError(err)
@@ -135,6 +176,10 @@ func (h *Handler) SubscribeOrderStatus(request *pb.GetOrderStatusRequest, stream
 		return status.Error(grpc_codes.Code(err.Code), err.Message)
 	}
 
+	dto.RequesterUUID = requesterID
+	dto.ResumeToken = resumeTokenFromContext(ctx)
+	dto.ClientOrderID = clientOrderIDFromContext(ctx)
+
 	ch, err := h.orderService.SubscribeOrderStatus(ctx, dto)
 	if err != nil {
 		span.RecordError(err)
@@ -157,7 +202,7 @@ func (h *Handler) SubscribeOrderStatus(request *pb.GetOrderStatusRequest, stream
 				return nil
 			}
 
-			err := stream.Send(order.ToProto())
+			err := send(order)
 			if err != nil {
 				span.RecordError(err)
 				span.SetStatus(codes.Error, err.Error())
@@ -172,12 +217,40 @@ func (h *Handler) SubscribeOrderStatus(request *pb.GetOrderStatusRequest, stream
 	}
 }
 
-func checkUser(ctx context.Context, requestUserUUID string) bool {
-	userUUID := clientKeyFromContext(ctx)
-	fmt.Println(userUUID)
-	fmt.Println(requestUserUUID)
-	if userUUID != "x-user-uuid"+requestUserUUID {
-		return false
+const (
+	resumeTokenHeader    = "x-resume-token"
+	idempotencyKeyHeader = "x-idempotency-key"
+	// clientOrderIDHeader carries the client order id, the proto messages have no field for it.
+	clientOrderIDHeader = "x-client-order-id"
+)
+
+func resumeTokenFromContext(ctx context.Context) string {
+	return incomingMetadataValue(ctx, resumeTokenHeader)
+}
+
+func idempotencyKeyFromContext(ctx context.Context) string {
+	return incomingMetadataValue(ctx, idempotencyKeyHeader)
+}
+
+func clientOrderIDFromContext(ctx context.Context) string {
+	return incomingMetadataValue(ctx, clientOrderIDHeader)
+}
+
+func incomingMetadataValue(ctx context.Context, key string) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	return firstMetadataValue(md, key)
+}
+
+// requesterFromContext is the caller the auth interceptor authenticated.
+func requesterFromContext(ctx context.Context) (uuid.UUID, *errors.CustomError) {
+	principal, ok := auth.FromContext(ctx)
+	if !ok {
+		return uuid.Nil, errs.ErrUnauthenticated
 	}
-	return true
+
+	return principal.UserID, nil
 }
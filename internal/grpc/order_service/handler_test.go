@@ -0,0 +1,99 @@
+package order_service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"OrderService/internal/auth"
+	"OrderService/internal/dto"
+	"OrderService/internal/model"
+	"OrderService/mocks"
+
+	pb "github.com/erdedan1/protocol/proto/order_service/gen/v1"
+	log "github.com/erdedan1/shared/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel/trace/noop"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// fakeStatusStream records what the handler sends on a SubscribeOrderStatus stream.
+type fakeStatusStream struct {
+	grpc.ServerStream
+
+	ctx     context.Context
+	sent    []*pb.GetOrderStatusResponse
+	trailer metadata.MD
+}
+
+func (s *fakeStatusStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *fakeStatusStream) Send(response *pb.GetOrderStatusResponse) error {
+	s.sent = append(s.sent, response)
+	return nil
+}
+
+func (s *fakeStatusStream) SetTrailer(md metadata.MD) {
+	s.trailer = metadata.Join(s.trailer, md)
+}
+
+func TestHandler_SubscribeOrderStatus(t *testing.T) {
+	orderService := mocks.NewOrderService(t)
+	logger, _ := log.NewLogger("debug")
+	handler := New(orderService, logger, noop.NewTracerProvider())
+
+	userID := uuid.New()
+	orderID := uuid.New()
+	paidAt := time.Now().Add(-time.Second)
+	closedAt := time.Now()
+
+	ctx := auth.NewContext(context.Background(), &auth.Principal{UserID: userID, Roles: []string{model.RoleTrader}})
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(resumeTokenHeader, "1-0"))
+
+	events := make(chan *dto.GetOrderStatusResponse, 2)
+	events <- &dto.GetOrderStatusResponse{Status: model.StatusPaid.ToString(), UpdatedAt: &paidAt, EventID: "2-0"}
+	events <- &dto.GetOrderStatusResponse{Status: model.StatusClosed.ToString(), UpdatedAt: &closedAt, EventID: "3-0"}
+	close(events)
+
+	orderService.On("SubscribeOrderStatus", mock.Anything, mock.MatchedBy(func(request *dto.GetOrderStatusRequest) bool {
+		return request.RequesterUUID == userID &&
+			request.UserUUID == userID &&
+			request.OrderUUID == orderID &&
+			request.ResumeToken == "1-0"
+	})).
+		Return((<-chan *dto.GetOrderStatusResponse)(events), nil)
+
+	stream := &fakeStatusStream{ctx: ctx}
+	err := handler.SubscribeOrderStatus(&pb.GetOrderStatusRequest{
+		UserUuid:  userID.String(),
+		OrderUuid: orderID.String(),
+	}, stream)
+
+	assert.NoError(t, err)
+	assert.Len(t, stream.sent, 2)
+	// every message carries the time of its event, which resumes after it
+	assert.True(t, stream.sent[0].UpdatedAt.AsTime().Equal(paidAt))
+	assert.True(t, stream.sent[1].UpdatedAt.AsTime().Equal(closedAt))
+	assert.Equal(t, []string{"3-0"}, stream.trailer.Get(resumeTokenHeader))
+}
+
+func TestHandler_SubscribeOrderStatus_Unauthenticated(t *testing.T) {
+	orderService := mocks.NewOrderService(t)
+	logger, _ := log.NewLogger("debug")
+	handler := New(orderService, logger, noop.NewTracerProvider())
+
+	stream := &fakeStatusStream{ctx: context.Background()}
+	err := handler.SubscribeOrderStatus(&pb.GetOrderStatusRequest{
+		UserUuid:  uuid.NewString(),
+		OrderUuid: uuid.NewString(),
+	}, stream)
+
+	assert.Error(t, err)
+	assert.Empty(t, stream.sent)
+	assert.Empty(t, stream.trailer)
+}
@@ -2,51 +2,80 @@ package order_service
 
 import (
 	"context"
+	"math"
 	"net"
-	"sync"
+	"strconv"
 	"time"
 
+	"OrderService/config"
+	"OrderService/internal/auth"
+	errs "OrderService/internal/errors"
+	"OrderService/internal/metrics"
+	"OrderService/internal/ratelimit"
+	"OrderService/internal/usecase"
+
+	errors "github.com/erdedan1/shared/errs"
+	log "github.com/erdedan1/shared/logger"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 )
 
-type rateBucket struct {
-	availableTokens  float64
-	lastRefillAtTime time.Time
-}
+const (
+	// globalKey is the bucket shared by every call to this instance.
+	globalKey = "global"
+
+	rateLimitLimitHeader     = "x-ratelimit-limit"
+	rateLimitRemainingHeader = "x-ratelimit-remaining"
+	rateLimitResetHeader     = "x-ratelimit-reset"
+)
 
+// grpcRateLimiter takes a token from the instance wide bucket and the cost of the call
+// from the bucket of the calling client, as resolved by the policies. Client buckets
+// live in the configured backend, the global one is always local since it protects this
+// instance only. The state of the client bucket goes back in the x-ratelimit trailers.
 type grpcRateLimiter struct {
-	mu           sync.Mutex
-	globalRPS    float64
-	globalBurst  float64
-	clientRPS    float64
-	clientBurst  float64
-	globalBucket rateBucket
-	clients      map[string]*rateBucket
+	log      log.Logger
+	global   ratelimit.Limiter
+	clients  ratelimit.Limiter
+	policies *ratelimit.Policies
+	// users resolves the roles the policies go by, like authorization does.
+	users      usecase.UserRepo
+	globalRate ratelimit.Rate
 }
 
-func newGRPCRateLimiter(globalRPS, clientRPS, clientBurst, globalBurst float64) *grpcRateLimiter {
-	now := time.Now()
+func newGRPCRateLimiter(
+	clients ratelimit.Limiter,
+	policies *ratelimit.Policies,
+	users usecase.UserRepo,
+	cfg config.RateLimiterConfig,
+	log log.Logger,
+) *grpcRateLimiter {
 	return &grpcRateLimiter{
-		globalRPS:   globalRPS,
-		globalBurst: globalBurst,
-		clientRPS:   clientRPS,
-		clientBurst: clientBurst,
-		globalBucket: rateBucket{
-			availableTokens:  globalBurst,
-			lastRefillAtTime: now,
+		log:      log,
+		global:   ratelimit.NewMemory(cfg.ClientIdleTTL),
+		clients:  clients,
+		policies: policies,
+		users:    users,
+		globalRate: ratelimit.Rate{
+			PerSecond: cfg.GlobalRequestsPerSecond,
+			Burst:     cfg.GlobalBurst,
 		},
-		clients: make(map[string]*rateBucket),
 	}
 }
 
 func (l *grpcRateLimiter) Unary() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
-		if !l.allow(clientKeyFromContext(ctx)) {
-			return nil, status.Error(codes.ResourceExhausted, "rate limit exceeded")
+		ctx, trailer, err := l.limit(ctx, info.FullMethod)
+		if trailer != nil {
+			_ = grpc.SetTrailer(ctx, trailer)
+		}
+		if err != nil {
+			return nil, err
 		}
 		return handler(ctx, req)
 	}
@@ -54,53 +83,119 @@ func (l *grpcRateLimiter) Unary() grpc.UnaryServerInterceptor {
 
 func (l *grpcRateLimiter) Stream() grpc.StreamServerInterceptor {
 	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
-		if !l.allow(clientKeyFromContext(ss.Context())) {
-			return status.Error(codes.ResourceExhausted, "rate limit exceeded")
+		ctx, trailer, err := l.limit(ss.Context(), info.FullMethod)
+		if trailer != nil {
+			ss.SetTrailer(trailer)
+		}
+		if err != nil {
+			return err
 		}
-		return handler(srv, ss)
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// limit takes the tokens of the call. It returns the context for the handler, carrying the
+// stored user when it was looked up, the quota trailer, if the client bucket was consulted,
+// and a ResourceExhausted error carrying RetryInfo when the call is rejected.
+func (l *grpcRateLimiter) limit(ctx context.Context, fullMethod string) (context.Context, metadata.MD, error) {
+	const method = "limit"
+
+	global, _ := l.global.Take(ctx, globalKey, l.globalRate, 1)
+	if !global.Allowed {
+		metrics.RateLimiterRejections.WithLabelValues(fullMethod).Inc()
+		return ctx, nil, rateLimitExceeded(global.RetryAfter)
+	}
+
+	ctx, roles := l.roles(ctx)
+	rule := l.policies.Resolve(fullMethod, roles)
+
+	clientKey := clientKeyFromContext(ctx)
+	if rule.Scope != ratelimit.DefaultScope {
+		clientKey += "|" + rule.Scope
+	}
+
+	client, err := l.clients.Take(ctx, clientKey, rule.Rate, rule.Cost)
+	if err != nil {
+		// a limiter that cannot decide must not take the service down with it
+		l.log.Error("GRPCRateLimiter", method, "failed to take client tokens", err, "client", clientKey)
+		return ctx, nil, nil
 	}
+
+	trailer := quotaTrailer(rule.Rate, client.Remaining)
+	if !client.Allowed {
+		metrics.RateLimiterRejections.WithLabelValues(fullMethod).Inc()
+		return ctx, trailer, rateLimitExceeded(client.RetryAfter)
+	}
+
+	return ctx, trailer, nil
 }
 
-func (l *grpcRateLimiter) allow(clientKey string) bool {
-	l.mu.Lock()
-	defer l.mu.Unlock()
+// roles are the stored roles of the caller. The roles of a token or a certificate are not
+// trusted, a caller that cannot be looked up or is deactivated gets the rates of no role.
+// The user goes on in the returned context, authorization takes it from there instead of
+// reading it again.
+func (l *grpcRateLimiter) roles(ctx context.Context) (context.Context, []string) {
+	const method = "roles"
+
+	if !l.policies.UsesRoles() {
+		return ctx, nil
+	}
 
-	now := time.Now()
-	if !allowBucket(&l.globalBucket, now, l.globalRPS, l.globalBurst) {
-		return false
+	principal, ok := auth.FromContext(ctx)
+	if !ok {
+		return ctx, nil
 	}
-	bucket, exists := l.clients[clientKey]
-	if !exists {
-		bucket = &rateBucket{
-			availableTokens:  l.clientBurst,
-			lastRefillAtTime: now,
+
+	user, ok := auth.UserFromContext(ctx, principal.UserID)
+	if !ok {
+		var err *errors.CustomError
+		user, err = l.users.GetUserById(ctx, principal.UserID)
+		if err != nil {
+			if err != errs.ErrUserNotFound {
+				l.log.Error("GRPCRateLimiter", method, "failed to get user roles", err, "user_id", principal.UserID)
+			}
+			return ctx, nil
 		}
-		l.clients[clientKey] = bucket
+		ctx = auth.NewUserContext(ctx, user)
+	}
+
+	if !user.IsActive() {
+		return ctx, nil
 	}
 
-	return allowBucket(bucket, now, l.clientRPS, l.clientBurst)
+	return ctx, user.Roles
 }
 
-func allowBucket(bucket *rateBucket, now time.Time, requestPerSec, burst float64) bool {
-	elapsedSecond := now.Sub(bucket.lastRefillAtTime).Seconds()
-	bucket.lastRefillAtTime = now
-	bucket.availableTokens = min(burst, bucket.availableTokens+elapsedSecond*requestPerSec)
+// quotaTrailer reports the bucket size, the whole tokens left and the seconds until the
+// bucket is full again.
+func quotaTrailer(rate ratelimit.Rate, remaining float64) metadata.MD {
+	reset := 0.0
+	if rate.PerSecond > 0 {
+		reset = math.Ceil((rate.Burst - remaining) / rate.PerSecond)
+	}
+
+	return metadata.Pairs(
+		rateLimitLimitHeader, strconv.FormatFloat(rate.Burst, 'f', -1, 64),
+		rateLimitRemainingHeader, strconv.FormatFloat(math.Floor(remaining), 'f', -1, 64),
+		rateLimitResetHeader, strconv.FormatFloat(reset, 'f', -1, 64),
+	)
+}
+
+func rateLimitExceeded(retryAfter time.Duration) error {
+	st := status.New(codes.ResourceExhausted, "rate limit exceeded")
 
-	if bucket.availableTokens < 1 {
-		return false
+	detailed, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)})
+	if err != nil {
+		return st.Err()
 	}
-	bucket.availableTokens--
-	return true
+
+	return detailed.Err()
 }
 
+// clientKeyFromContext buckets authenticated calls by user, anything else by peer address.
 func clientKeyFromContext(ctx context.Context) string {
-	if md, ok := metadata.FromIncomingContext(ctx); ok {
-		if clientID := firstMetadataValue(md, "x-client-id"); clientID != "" {
-			return "client-id" + clientID
-		}
-		if userUUID := firstMetadataValue(md, "x-user-uuid"); userUUID != "" {
-			return "x-user-uuid" + userUUID
-		}
+	if principal, ok := auth.FromContext(ctx); ok {
+		return "user:" + principal.UserID.String()
 	}
 
 	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
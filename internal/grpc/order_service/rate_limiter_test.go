@@ -0,0 +1,140 @@
+package order_service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"OrderService/config"
+	"OrderService/internal/auth"
+	errs "OrderService/internal/errors"
+	"OrderService/internal/model"
+	"OrderService/internal/ratelimit"
+	"OrderService/mocks"
+
+	log "github.com/erdedan1/shared/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const rateLimitTestMethod = "/order.v1.OrderService/CreateOrder"
+
+// newTestRateLimiter lets a client without a role do one call, admins many.
+func newTestRateLimiter(t *testing.T, users *mocks.UserRepo) *grpcRateLimiter {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "policies.json")
+	err := os.WriteFile(path, []byte(`{
+		"default": {"requests_per_second": 0.001, "burst": 1},
+		"roles": {"`+model.RoleAdmin+`": {"requests_per_second": 1000, "burst": 1000}}
+	}`), 0o600)
+	assert.NoError(t, err)
+
+	policies, err := ratelimit.LoadPolicies(path, ratelimit.Rate{PerSecond: 1, Burst: 1})
+	assert.NoError(t, err)
+
+	logger, _ := log.NewLogger("debug")
+
+	return newGRPCRateLimiter(ratelimit.NewMemory(time.Minute), policies, users, config.RateLimiterConfig{
+		GlobalRequestsPerSecond: 1000,
+		GlobalBurst:             1000,
+		ClientIdleTTL:           time.Minute,
+	}, logger)
+}
+
+// callsAllowed is how many of n calls of the principal the limiter lets through.
+func callsAllowed(limiter *grpcRateLimiter, principal *auth.Principal, n int) int {
+	ctx := auth.NewContext(context.Background(), principal)
+
+	allowed := 0
+	for range n {
+		_, _, err := limiter.limit(ctx, rateLimitTestMethod)
+		if err == nil {
+			allowed++
+		} else if status.Code(err) != codes.ResourceExhausted {
+			return -1
+		}
+	}
+	return allowed
+}
+
+func TestGRPCRateLimiter_UsesStoredRoles(t *testing.T) {
+	deactivatedAt := time.Now()
+
+	tests := []struct {
+		name   string
+		stored *model.User
+		want   int
+	}{
+		{name: "stored admin", stored: &model.User{Roles: []string{model.RoleAdmin}}, want: 5},
+		// the token claims admin, the user is a trader now
+		{name: "stored trader", stored: &model.User{Roles: []string{model.RoleTrader}}, want: 1},
+		{name: "deactivated admin", stored: &model.User{Roles: []string{model.RoleAdmin}, DeactivatedAt: &deactivatedAt}, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := mocks.NewUserRepo(t)
+			limiter := newTestRateLimiter(t, users)
+
+			userID := uuid.New()
+			tt.stored.ID = userID
+			users.On("GetUserById", mock.Anything, userID).Return(tt.stored, nil)
+
+			principal := &auth.Principal{UserID: userID, Roles: []string{model.RoleAdmin}}
+			assert.Equal(t, tt.want, callsAllowed(limiter, principal, 5))
+		})
+	}
+}
+
+func TestGRPCRateLimiter_PassesStoredUserOn(t *testing.T) {
+	users := mocks.NewUserRepo(t)
+	limiter := newTestRateLimiter(t, users)
+
+	userID := uuid.New()
+	stored := &model.User{ID: userID, Roles: []string{model.RoleAdmin}}
+	users.On("GetUserById", mock.Anything, userID).Return(stored, nil).Once()
+
+	ctx := auth.NewContext(context.Background(), &auth.Principal{UserID: userID})
+	ctx, _, err := limiter.limit(ctx, rateLimitTestMethod)
+	assert.NoError(t, err)
+
+	// authorization finds the user without reading it again
+	user, ok := auth.UserFromContext(ctx, userID)
+	assert.True(t, ok)
+	assert.Same(t, stored, user)
+
+	_, _, err = limiter.limit(ctx, rateLimitTestMethod)
+	assert.NoError(t, err)
+}
+
+func TestGRPCRateLimiter_UnknownUserGetsDefaultRate(t *testing.T) {
+	users := mocks.NewUserRepo(t)
+	limiter := newTestRateLimiter(t, users)
+
+	userID := uuid.New()
+	users.On("GetUserById", mock.Anything, userID).Return(nil, errs.ErrUserNotFound)
+
+	principal := &auth.Principal{UserID: userID, Roles: []string{model.RoleAdmin}}
+	assert.Equal(t, 1, callsAllowed(limiter, principal, 5))
+}
+
+func TestGRPCRateLimiter_SkipsLookupWithoutRolePolicies(t *testing.T) {
+	// no expectations, any lookup fails the test
+	users := mocks.NewUserRepo(t)
+	logger, _ := log.NewLogger("debug")
+
+	limiter := newGRPCRateLimiter(ratelimit.NewMemory(time.Minute), ratelimit.NewPolicies(ratelimit.Rate{PerSecond: 1000, Burst: 1000}), users, config.RateLimiterConfig{
+		GlobalRequestsPerSecond: 1000,
+		GlobalBurst:             1000,
+		ClientIdleTTL:           time.Minute,
+	}, logger)
+
+	principal := &auth.Principal{UserID: uuid.New(), Roles: []string{model.RoleAdmin}}
+	assert.Equal(t, 3, callsAllowed(limiter, principal, 3))
+}
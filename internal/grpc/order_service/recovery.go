@@ -0,0 +1,30 @@
+package order_service
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	log "github.com/erdedan1/shared/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const recoveryLayer = "RecoveryInterceptor"
+
+// streamRecoveryInterceptor turns a panic in a stream handler into an Internal error, the
+// shared recovery interceptor only covers unary calls.
+func streamRecoveryInterceptor(logger log.Logger) grpc.StreamServerInterceptor {
+	const method = "Stream"
+
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				logger.Error(recoveryLayer, method, "stream handler panicked", fmt.Errorf("%v", r), "method", info.FullMethod, "stack", string(debug.Stack()))
+				err = status.Error(codes.Internal, "internal error")
+			}
+		}()
+
+		return handler(srv, ss)
+	}
+}
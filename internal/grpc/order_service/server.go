@@ -1,11 +1,21 @@
 package order_service
 
 import (
+	"context"
+	"crypto/tls"
 	"errors"
 	"net"
+	"time"
 
 	"OrderService/config"
+	"OrderService/internal/auth"
+	"OrderService/internal/breakers"
+	"OrderService/internal/health"
+	"OrderService/internal/metrics"
+	"OrderService/internal/ratelimit"
 	"OrderService/internal/usecase"
+	"OrderService/pkg/certs"
+	"OrderService/pkg/circuitbreaker"
 
 	pbOrder "github.com/erdedan1/protocol/proto/order_service/gen/v1"
 	"github.com/erdedan1/shared/errs"
@@ -15,6 +25,9 @@ import (
 	log "github.com/erdedan1/shared/logger"
 	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
 )
 
 type GRPCServer struct {
@@ -22,46 +35,175 @@ type GRPCServer struct {
 	log     log.Logger
 	server  *grpc.Server
 	lis     net.Listener
+	health  *health.Checker
+	// gateway is the HTTP/JSON front of the handler, nil when disabled.
+	gateway  *Gateway
+	breakers *circuitbreaker.Group
+	// tlsConfig is shared with the other listeners, nil for a plaintext server.
+	tlsConfig *tls.Config
+	// drainDelay is how long Stop keeps serving after reporting NOT_SERVING.
+	drainDelay time.Duration
 }
 
-func NewGRPCServer(address string, orderService usecase.OrderService, logger log.Logger, tp trace.TracerProvider, cfg config.InfrastructureConfig) (*GRPCServer, *errs.CustomError) {
-	rateLimiter := newGRPCRateLimiter(
-		cfg.RateLimiter.GlobalRequestsPerSecond,
-		cfg.RateLimiter.GlobalBurst,
-		cfg.RateLimiter.ClientRequestsPerSecond,
-		cfg.RateLimiter.ClientBurst,
-	)
+// NewGRPCServer builds the order service server. It also serves grpc.health.v1, backed by
+// the dependency probes, reflection and the HTTP gateway when they are enabled.
+func NewGRPCServer(
+	serverCfg config.GRPCServerConfig,
+	orderService usecase.OrderService,
+	userRepo usecase.UserRepo,
+	clientLimiter ratelimit.Limiter,
+	logger log.Logger,
+	tp trace.TracerProvider,
+	cfg config.InfrastructureConfig,
+	probes ...health.Probe,
+) (*GRPCServer, *errs.CustomError) {
+	policies, err := rateLimitPolicies(cfg.RateLimiter)
+	if err != nil {
+		return nil, err
+	}
+	rateLimiter := newGRPCRateLimiter(clientLimiter, policies, userRepo, cfg.RateLimiter, logger)
 
-	cycleBreaker := newGRPCCircuitBreaker(
-		uint32(cfg.CircuitBreaker.ConsecutiveFailures),
-		cfg.CircuitBreaker.HalfOpenRequests,
-		cfg.CircuitBreaker.OpenTimeout,
-	)
+	breakerGroup, err := breakers.NewGroup(serverBreakerGroup, cfg.CircuitBreaker, logger)
+	if err != nil {
+		return nil, err
+	}
+	cycleBreaker := newGRPCCircuitBreaker(breakerGroup)
 
-	server := grpc.NewServer(
-		grpc.ChainUnaryInterceptor(
-			requestid.XRequestIDServerInterceptor(),
-			pbLogger.LoggerServerInterceptor(logger),
-			recovery.RecoveryServerInterceptor(logger),
-			rateLimiter.Unary(),
-			cycleBreaker.Unary(),
-		),
-		grpc.ChainStreamInterceptor(
-			rateLimiter.Stream(),
-			cycleBreaker.Stream(),
-		),
-	)
+	verifier, err := auth.NewVerifier(cfg.Auth)
+	if err != nil {
+		return nil, err
+	}
+	authenticator := newGRPCAuthenticator(verifier, logger)
+
+	tlsConfig, err := serverTLSConfig(serverCfg, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	var options []grpc.ServerOption
+	if tlsConfig != nil {
+		options = append(options, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	// the gateway runs the same chains, so its calls are logged, measured and recovered
+	// like gRPC calls.
+	//
+	// Recovery sits outside the limiters and the breaker: a panic in a handler unwinds
+	// through them before it is turned into an error. Whatever they must give back, an
+	// in-flight slot or a half-open trial, is given back in a defer, or it would leak.
+	unary := []grpc.UnaryServerInterceptor{
+		requestid.XRequestIDServerInterceptor(),
+		metrics.UnaryServerInterceptor(),
+		pbLogger.LoggerServerInterceptor(logger),
+		recovery.RecoveryServerInterceptor(logger),
+		authenticator.Unary(),
+		rateLimiter.Unary(),
+	}
+	if cfg.ConcurrencyLimiter.Enabled {
+		unary = append(unary, newGRPCConcurrencyLimiter(cfg.ConcurrencyLimiter).Unary())
+	}
+	unary = append(unary, cycleBreaker.Unary())
+	stream := []grpc.StreamServerInterceptor{
+		metrics.StreamServerInterceptor(),
+		streamRecoveryInterceptor(logger),
+		authenticator.Stream(),
+		rateLimiter.Stream(),
+		cycleBreaker.Stream(),
+	}
+
+	server := grpc.NewServer(append(options,
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+	)...)
 
 	handler := New(orderService, logger, tp)
 	pbOrder.RegisterOrderServiceServer(server, handler)
 
+	if serverCfg.EnableReflection {
+		reflection.Register(server)
+	}
+
+	checker := health.NewChecker(
+		probes,
+		[]string{pbOrder.OrderService_ServiceDesc.ServiceName},
+		serverCfg.HealthCheckInterval,
+		serverCfg.HealthCheckTimeout,
+		logger,
+	)
+	healthpb.RegisterHealthServer(server, checker.Server())
+
+	var gateway *Gateway
+	if serverCfg.EnableGateway {
+		gateway = newGateway(serverCfg.GatewayListenAddr, handler, unary, stream, tlsConfig, logger)
+	}
+
 	return &GRPCServer{
-		address: address,
-		log:     logger,
-		server:  server,
+		address:    serverCfg.Address,
+		log:        logger,
+		server:     server,
+		health:     checker,
+		gateway:    gateway,
+		breakers:   breakerGroup,
+		tlsConfig:  tlsConfig,
+		drainDelay: serverCfg.ShutdownDrainDelay,
 	}, nil
 }
 
+// Breakers are the per-method breakers guarding the handlers.
+func (s *GRPCServer) Breakers() *circuitbreaker.Group {
+	return s.breakers
+}
+
+// TLSConfig is the server's TLS setup, reloading certificates, for the other listeners
+// of the service. It is nil when the server runs plaintext.
+func (s *GRPCServer) TLSConfig() *tls.Config {
+	return s.tlsConfig
+}
+
+// Gateway is the HTTP gateway to run next to the server, nil when it is disabled.
+func (s *GRPCServer) Gateway() *Gateway {
+	return s.gateway
+}
+
+func rateLimitPolicies(cfg config.RateLimiterConfig) (*ratelimit.Policies, *errs.CustomError) {
+	clientRate := ratelimit.Rate{
+		PerSecond: cfg.ClientRequestsPerSecond,
+		Burst:     cfg.ClientBurst,
+	}
+	if cfg.PolicyFile == "" {
+		return ratelimit.NewPolicies(clientRate), nil
+	}
+
+	policies, err := ratelimit.LoadPolicies(cfg.PolicyFile, clientRate)
+	if err != nil {
+		return nil, errs.New(errs.INVALID_ARGUMENT, "failed to load rate limit policies", err)
+	}
+
+	return policies, nil
+}
+
+// serverTLSConfig turns on TLS when a server certificate is configured, and mTLS when a
+// client CA bundle is. It is nil for a plaintext server.
+func serverTLSConfig(cfg config.GRPCServerConfig, log log.Logger) (*tls.Config, *errs.CustomError) {
+	if cfg.TLSCertFile == "" {
+		if cfg.TLSClientCAFile != "" {
+			return nil, errs.New(errs.INVALID_ARGUMENT, "client certificate verification needs a server certificate")
+		}
+		return nil, nil
+	}
+
+	reloader, err := certs.NewReloader(certs.Files{
+		CertFile: cfg.TLSCertFile,
+		KeyFile:  cfg.TLSKeyFile,
+		CAFile:   cfg.TLSClientCAFile,
+	}, cfg.TLSReloadInterval, log)
+	if err != nil {
+		return nil, errs.New(errs.INTERNAL, "failed to load server certificates", err)
+	}
+
+	return certs.ServerConfig(reloader, cfg.TLSRequireClientCert), nil
+}
+
 func (s *GRPCServer) Start() *errs.CustomError {
 	const method = "GRPCServer.Start"
 
@@ -72,6 +214,8 @@ func (s *GRPCServer) Start() *errs.CustomError {
 	}
 	s.lis = lis
 
+	go s.health.Run(context.Background())
+
 	err = s.server.Serve(lis)
 	if err != nil {
 		s.log.Error("GRPCServer", method, "grpc serve error", err)
@@ -86,6 +230,13 @@ func (s *GRPCServer) Stop() {
 	if s.server == nil {
 		return
 	}
+
+	s.health.Shutdown()
+	if s.drainDelay > 0 {
+		s.log.Info("GRPCServer", method, "draining before shutdown", "delay", s.drainDelay.String())
+		time.Sleep(s.drainDelay)
+	}
+
 	s.server.GracefulStop()
 	s.log.Info("GRPCServer", method, "grpc server stopped gracefully")
 }
@@ -2,19 +2,31 @@ package spot_instrument_service
 
 import (
 	"OrderService/config"
+	"OrderService/pkg/circuitbreaker"
 	grpc_client "OrderService/pkg/client/grpc"
 
+	pb "github.com/erdedan1/protocol/proto/spot_instrument_service/gen/v1"
 	requestid "github.com/erdedan1/shared/interceptors/request_id"
+	log "github.com/erdedan1/shared/logger"
 	"google.golang.org/grpc"
 )
 
+// SetupSpotInstrumentClient dials the spot instrument service. Every MarketService method
+// only reads, so all of them are retried, and breakers guards each of them.
 func SetupSpotInstrumentClient(
 	cfg *config.Config,
+	breakers *circuitbreaker.Group,
+	log log.Logger,
 ) (grpc_client.IGRPCClient, error) {
 	conn, err := grpc_client.New(
 		cfg.GRPCApi.SpotInstrumentServiceHost,
 		cfg,
-		grpc.WithUnaryInterceptor(requestid.XRequestIDClientInterceptor()),
+		log,
+		grpc.WithChainUnaryInterceptor(
+			requestid.XRequestIDClientInterceptor(),
+			grpc_client.UnaryCircuitBreakerInterceptor(breakers),
+		),
+		grpc_client.WithRetry(cfg.GRPCClient, grpc_client.MethodName{Service: pb.MarketService_ServiceDesc.ServiceName}),
 	)
 	if err != nil {
 		return nil, err
@@ -2,35 +2,77 @@ package spot_instrument_service
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"OrderService/config"
+	"OrderService/internal/breakers"
 	"OrderService/internal/dto"
+	"OrderService/internal/model"
+	"OrderService/internal/usecase"
+	"OrderService/pkg/circuitbreaker"
 	grpc_client "OrderService/pkg/client/grpc"
 
 	pb "github.com/erdedan1/protocol/proto/spot_instrument_service/gen/v1"
 	"github.com/erdedan1/shared/errs"
+	log "github.com/erdedan1/shared/logger"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 )
 
+const layer = "MarketService"
+
+// BreakerGroup labels the breakers guarding the calls to the spot instrument service.
+const BreakerGroup = "spot_instrument_service"
+
 type marketService struct {
-	client pb.MarketServiceClient
-	conn   grpc_client.IGRPCClient
-	trace  trace.Tracer
+	client       pb.MarketServiceClient
+	conn         grpc_client.IGRPCClient
+	breakers     *circuitbreaker.Group
+	lastKnown    usecase.MarketCacheRepo
+	lastKnownTTL time.Duration
+	log          log.Logger
+	trace        trace.Tracer
 }
 
-func NewMarketService(cfg *config.Config, tp trace.TracerProvider) (*marketService, *errs.CustomError) {
-	conn, err := SetupSpotInstrumentClient(cfg)
+func NewMarketService(
+	cfg *config.Config,
+	lastKnown usecase.MarketCacheRepo,
+	log log.Logger,
+	tp trace.TracerProvider,
+) (*marketService, *errs.CustomError) {
+	breakerGroup, cErr := breakers.NewGroup(BreakerGroup, cfg.Infrastructure.CircuitBreaker, log)
+	if cErr != nil {
+		return nil, cErr
+	}
+
+	conn, err := SetupSpotInstrumentClient(cfg, breakerGroup, log)
 	if err != nil {
 		return nil, errs.New(errs.UNAVAILABLE, err.Error(), err)
 	}
 
 	return &marketService{
-		client: pb.NewMarketServiceClient(conn),
-		conn:   conn,
-		trace:  tp.Tracer("order-service/MarketService"),
+		client:       pb.NewMarketServiceClient(conn),
+		conn:         conn,
+		breakers:     breakerGroup,
+		lastKnown:    lastKnown,
+		lastKnownTTL: cfg.GRPCApi.SpotInstrumentFallbackTTL,
+		log:          log,
+		trace:        tp.Tracer("order-service/MarketService"),
 	}, nil
 }
 
+// Conn is the connection to the spot instrument service, for health probing.
+func (s *marketService) Conn() grpc_client.IGRPCClient {
+	return s.conn
+}
+
+// Breakers are the per-method breakers guarding the calls to the spot instrument service.
+func (s *marketService) Breakers() *circuitbreaker.Group {
+	return s.breakers
+}
+
 func (s *marketService) Close() error {
 	if s.conn == nil {
 		return nil
@@ -39,12 +81,17 @@ func (s *marketService) Close() error {
 	return s.conn.Close()
 }
 
+// ViewMarketsByRoles asks the spot instrument service for the markets of the role. While
+// the breaker to the service is open it serves the markets of the last successful call.
 func (s *marketService) ViewMarketsByRoles(ctx context.Context, request *dto.ViewMarketsRequest) ([]dto.ViewMarketsResponse, *errs.CustomError) {
 	ctx, span := s.trace.Start(ctx, "OrderService.ViewMarketsByRoles")
 	defer span.End()
 
 	resp, err := s.client.ViewMarketsByRoles(ctx, request.ToProto())
 	if err != nil {
+		if errors.Is(err, circuitbreaker.ErrOpen) {
+			return s.lastKnownMarkets(ctx, request, err)
+		}
 		return nil, errs.New(errs.UNAVAILABLE, err.Error(), err)
 	}
 
@@ -57,5 +104,27 @@ func (s *marketService) ViewMarketsByRoles(ctx context.Context, request *dto.Vie
 		marketsResp = append(marketsResp, *dtoM)
 	}
 
+	// a failed write only costs the fallback its freshness, the markets are still good
+	_ = s.lastKnown.Set(ctx, model.LastKnownMarketsKey(request.UserRole), marketsResp, s.lastKnownTTL)
+
 	return marketsResp, nil
 }
+
+func (s *marketService) lastKnownMarkets(ctx context.Context, request *dto.ViewMarketsRequest, cause error) ([]dto.ViewMarketsResponse, *errs.CustomError) {
+	const method = "lastKnownMarkets"
+	span := trace.SpanFromContext(ctx)
+
+	markets, err := s.lastKnown.Get(ctx, model.LastKnownMarketsKey(request.UserRole))
+	if err != nil || markets == nil {
+		span.RecordError(cause)
+		span.SetStatus(codes.Error, cause.Error())
+
+		s.log.Error(layer, method, "breaker is open and no last known markets", cause, "role", request.UserRole)
+		return nil, errs.New(errs.UNAVAILABLE, cause.Error(), cause)
+	}
+
+	span.SetAttributes(attribute.Bool("markets.fallback", true))
+	s.log.Info(layer, method, "breaker is open, serving last known markets", "role", request.UserRole)
+
+	return markets, nil
+}
@@ -0,0 +1,125 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	log "github.com/erdedan1/shared/logger"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const layer = "HealthChecker"
+
+// Probe checks one dependency of the service. Name is also the service name the
+// dependency is reported under, so each one can be queried on its own. Only the critical
+// ones, without which no call can be served, take the whole service down.
+type Probe struct {
+	Name     string
+	Check    func(ctx context.Context) error
+	Critical bool
+}
+
+// Checker runs the probes periodically and keeps the grpc.health.v1 statuses up to date.
+// The overall status, reported for the empty service name and for every name passed as
+// served, is SERVING while the critical probes pass. The others are only reported under
+// their own names, the service degrades without them instead of going away.
+type Checker struct {
+	server   *health.Server
+	probes   []Probe
+	served   []string
+	interval time.Duration
+	timeout  time.Duration
+	log      log.Logger
+
+	stopOnce sync.Once
+	stopped  chan struct{}
+}
+
+func NewChecker(probes []Probe, served []string, interval, timeout time.Duration, log log.Logger) *Checker {
+	c := &Checker{
+		server:   health.NewServer(),
+		probes:   probes,
+		served:   served,
+		interval: interval,
+		timeout:  timeout,
+		log:      log,
+		stopped:  make(chan struct{}),
+	}
+	// nothing is served until the first round of probes passed
+	c.setOverall(healthpb.HealthCheckResponse_NOT_SERVING)
+
+	return c
+}
+
+// Server is the grpc.health.v1 implementation to register on the gRPC server.
+func (c *Checker) Server() healthpb.HealthServer {
+	return c.server
+}
+
+// Run probes the dependencies right away and then once per interval until ctx is
+// cancelled or the checker is shut down.
+func (c *Checker) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		c.check(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.stopped:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Shutdown reports every service as NOT_SERVING for good, so load balancers stop
+// routing new calls here while the server drains.
+func (c *Checker) Shutdown() {
+	const method = "Shutdown"
+
+	c.stopOnce.Do(func() {
+		// the health server ignores every status change after this, probes included
+		c.server.Shutdown()
+		close(c.stopped)
+		c.log.Info(layer, method, "serving status set to NOT_SERVING")
+	})
+}
+
+func (c *Checker) check(ctx context.Context) {
+	const method = "check"
+
+	healthy := true
+	for _, probe := range c.probes {
+		probeCtx, cancel := context.WithTimeout(ctx, c.timeout)
+		err := probe.Check(probeCtx)
+		cancel()
+
+		if err != nil {
+			c.log.Error(layer, method, "dependency probe failed", err, "dependency", probe.Name)
+			c.server.SetServingStatus(probe.Name, healthpb.HealthCheckResponse_NOT_SERVING)
+			if probe.Critical {
+				healthy = false
+			}
+			continue
+		}
+		c.server.SetServingStatus(probe.Name, healthpb.HealthCheckResponse_SERVING)
+	}
+
+	if healthy {
+		c.setOverall(healthpb.HealthCheckResponse_SERVING)
+	} else {
+		c.setOverall(healthpb.HealthCheckResponse_NOT_SERVING)
+	}
+}
+
+func (c *Checker) setOverall(status healthpb.HealthCheckResponse_ServingStatus) {
+	c.server.SetServingStatus("", status)
+	for _, name := range c.served {
+		c.server.SetServingStatus(name, status)
+	}
+}
@@ -0,0 +1,85 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"OrderService/internal/health"
+
+	log "github.com/erdedan1/shared/logger"
+	"github.com/stretchr/testify/assert"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// servingStatus is UNKNOWN for a service the checker has not reported yet.
+func servingStatus(checker *health.Checker, service string) healthpb.HealthCheckResponse_ServingStatus {
+	resp, err := checker.Server().Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+	if err != nil {
+		return healthpb.HealthCheckResponse_UNKNOWN
+	}
+	return resp.GetStatus()
+}
+
+func TestHealthChecker_FollowsProbesAndShutdown(t *testing.T) {
+	logger, _ := log.NewLogger("debug")
+
+	var postgresDown, redisDown atomic.Bool
+	redisDown.Store(true)
+
+	checker := health.NewChecker(
+		[]health.Probe{
+			{Name: "postgres", Critical: true, Check: func(context.Context) error {
+				if postgresDown.Load() {
+					return errors.New("connection refused")
+				}
+				return nil
+			}},
+			{Name: "redis", Check: func(context.Context) error {
+				if redisDown.Load() {
+					return errors.New("connection refused")
+				}
+				return nil
+			}},
+		},
+		[]string{"order.v1.OrderService"},
+		10*time.Millisecond,
+		time.Second,
+		logger,
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go checker.Run(ctx)
+
+	// redis is reported on its own, the service keeps serving without it
+	assert.Eventually(t, func() bool {
+		return servingStatus(checker, "redis") == healthpb.HealthCheckResponse_NOT_SERVING &&
+			servingStatus(checker, "") == healthpb.HealthCheckResponse_SERVING
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, servingStatus(checker, "postgres"))
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, servingStatus(checker, "order.v1.OrderService"))
+
+	redisDown.Store(false)
+	assert.Eventually(t, func() bool {
+		return servingStatus(checker, "redis") == healthpb.HealthCheckResponse_SERVING
+	}, time.Second, 5*time.Millisecond)
+
+	postgresDown.Store(true)
+	assert.Eventually(t, func() bool {
+		return servingStatus(checker, "order.v1.OrderService") == healthpb.HealthCheckResponse_NOT_SERVING
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(checker, ""))
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(checker, "postgres"))
+
+	postgresDown.Store(false)
+	assert.Eventually(t, func() bool {
+		return servingStatus(checker, "") == healthpb.HealthCheckResponse_SERVING
+	}, time.Second, 5*time.Millisecond)
+
+	checker.Shutdown()
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(checker, ""))
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(checker, "postgres"))
+}
@@ -0,0 +1,59 @@
+package health
+
+import (
+	"context"
+	"fmt"
+
+	"OrderService/internal/connection"
+	"OrderService/pkg/cache"
+	grpc_client "OrderService/pkg/client/grpc"
+
+	"github.com/jmoiron/sqlx"
+	"google.golang.org/grpc/connectivity"
+)
+
+const (
+	PostgresProbeName       = "postgres"
+	RedisProbeName          = "redis"
+	SpotInstrumentProbeName = "spot_instrument_service"
+)
+
+// PostgresProbe is critical, orders are kept there. Redis and the spot instrument service
+// only back caches, events and market lookups that have fallbacks.
+func PostgresProbe(db *sqlx.DB) Probe {
+	return Probe{
+		Name: PostgresProbeName,
+		Check: func(ctx context.Context) error {
+			return connection.Ping(ctx, db)
+		},
+		Critical: true,
+	}
+}
+
+func RedisProbe(client cache.RedisClient) Probe {
+	return Probe{
+		Name: RedisProbeName,
+		Check: func(ctx context.Context) error {
+			return client.Ping(ctx).Err()
+		},
+	}
+}
+
+// GRPCClientProbe passes while the client connection is usable. An idle connection is
+// asked to reconnect and counts as healthy, it only dials on demand.
+func GRPCClientProbe(name string, conn grpc_client.IGRPCClient) Probe {
+	return Probe{
+		Name: name,
+		Check: func(context.Context) error {
+			switch state := conn.GetState(); state {
+			case connectivity.Ready:
+				return nil
+			case connectivity.Idle:
+				conn.Connect()
+				return nil
+			default:
+				return fmt.Errorf("connection to %s is %s", conn.Target(), state)
+			}
+		},
+	}
+}
@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	callTypeUnary  = "unary"
+	callTypeStream = "stream"
+)
+
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+
+		response, err := handler(ctx, req)
+		observeGRPC(info.FullMethod, callTypeUnary, start, err)
+
+		return response, err
+	}
+}
+
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+
+		err := handler(srv, ss)
+		observeGRPC(info.FullMethod, callTypeStream, start, err)
+
+		return err
+	}
+}
+
+func observeGRPC(method, callType string, start time.Time, err error) {
+	code := status.Code(err)
+
+	GRPCRequests.WithLabelValues(method, callType, code.String()).Inc()
+	GRPCRequestDuration.WithLabelValues(method, callType).Observe(time.Since(start).Seconds())
+
+	if code != codes.OK {
+		GRPCRequestErrors.WithLabelValues(method, callType, code.String()).Inc()
+	}
+}
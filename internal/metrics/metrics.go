@@ -1 +1,143 @@
 package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+const namespace = "order_service"
+
+// Registry holds every collector of the service, it is what the metrics server exposes.
+var Registry = prometheus.NewRegistry()
+
+var (
+	GRPCRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "grpc_server",
+		Name:      "requests_total",
+		Help:      "Handled gRPC requests by method, call type and status code.",
+	}, []string{"method", "type", "code"})
+
+	GRPCRequestErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "grpc_server",
+		Name:      "request_errors_total",
+		Help:      "gRPC requests that finished with a non-OK status code.",
+	}, []string{"method", "type", "code"})
+
+	GRPCRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "grpc_server",
+		Name:      "request_duration_seconds",
+		Help:      "gRPC request latency, for streams the lifetime of the stream.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "type"})
+
+	RateLimiterRejections = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "rate_limiter",
+		Name:      "rejections_total",
+		Help:      "Requests rejected by the rate limiter.",
+	}, []string{"method"})
+
+	ConcurrencyLimit = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "concurrency_limiter",
+		Name:      "limit",
+		Help:      "Calls the adaptive concurrency limiter currently allows in flight.",
+	})
+
+	ConcurrencyInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "concurrency_limiter",
+		Name:      "in_flight",
+		Help:      "Calls currently in flight through the concurrency limiter.",
+	})
+
+	ConcurrencyShed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "concurrency_limiter",
+		Name:      "shed_total",
+		Help:      "Calls shed by the concurrency limiter by method and priority.",
+	}, []string{"method", "priority"})
+
+	CircuitBreakerState = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "circuit_breaker",
+		Name:      "state",
+		Help:      "Circuit breaker state by breaker group and name: 0 closed, 1 half-open, 2 open.",
+	}, []string{"group", "breaker"})
+
+	CircuitBreakerTransitions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "circuit_breaker",
+		Name:      "transitions_total",
+		Help:      "Circuit breaker state changes by breaker group, name and new state.",
+	}, []string{"group", "breaker", "state"})
+
+	OrdersCreated = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "orders",
+		Name:      "created_total",
+		Help:      "Created orders by market and order type.",
+	}, []string{"market", "type"})
+
+	OrderStatusTransitions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "orders",
+		Name:      "status_transitions_total",
+		Help:      "Order status transitions by source status, target status and actor type.",
+	}, []string{"from", "to", "actor"})
+
+	ActiveSubscriptions = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "orders",
+		Name:      "status_subscriptions_active",
+		Help:      "Open SubscribeOrderStatus streams.",
+	})
+
+	RedisCommandDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "redis",
+		Name:      "command_duration_seconds",
+		Help:      "Redis command latency by command and result.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+	}, []string{"command", "result"})
+
+	CacheLookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "cache",
+		Name:      "lookups_total",
+		Help:      "Cache lookups by cache, tier and result: hit, stale or miss.",
+	}, []string{"cache", "tier", "result"})
+
+	PostgresQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "postgres",
+		Name:      "query_duration_seconds",
+		Help:      "Postgres repository call latency by operation.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		GRPCRequests,
+		GRPCRequestErrors,
+		GRPCRequestDuration,
+		RateLimiterRejections,
+		ConcurrencyLimit,
+		ConcurrencyInFlight,
+		ConcurrencyShed,
+		CircuitBreakerState,
+		CircuitBreakerTransitions,
+		OrdersCreated,
+		OrderStatusTransitions,
+		ActiveSubscriptions,
+		RedisCommandDuration,
+		CacheLookups,
+		PostgresQueryDuration,
+	)
+}
@@ -0,0 +1,81 @@
+package metrics_test
+
+import (
+	"testing"
+	"time"
+
+	"OrderService/internal/metrics"
+
+	"github.com/google/uuid"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+)
+
+func gather(t *testing.T) map[string]*dto.MetricFamily {
+	t.Helper()
+
+	families, err := metrics.Registry.Gather()
+	assert.NoError(t, err)
+
+	byName := make(map[string]*dto.MetricFamily, len(families))
+	for _, family := range families {
+		byName[family.GetName()] = family
+	}
+	return byName
+}
+
+func TestRegistry_RecordsCollectors(t *testing.T) {
+	metrics.GRPCRequests.WithLabelValues("/order.v1.OrderService/CreateOrder", "unary", "OK").Inc()
+	metrics.RateLimiterRejections.WithLabelValues("/order.v1.OrderService/CreateOrder").Inc()
+	metrics.ConcurrencyInFlight.Set(3)
+	metrics.CircuitBreakerState.WithLabelValues("order_service", "CreateOrder").Set(2)
+	metrics.OrdersCreated.WithLabelValues("5b1f7d3e-8f5e-4c0b-9a57-2f1d3c4b5a69", "limit").Inc()
+	metrics.OrderStatusTransitions.WithLabelValues("CREATED", "PAID", "SYSTEM").Inc()
+	metrics.CacheLookups.WithLabelValues("markets", "local", "hit").Inc()
+	metrics.ObservePostgres("CreateOrder", time.Now())
+
+	families := gather(t)
+
+	for _, name := range []string{
+		"go_goroutines",
+		"process_start_time_seconds",
+		"order_service_grpc_server_requests_total",
+		"order_service_rate_limiter_rejections_total",
+		"order_service_concurrency_limiter_in_flight",
+		"order_service_circuit_breaker_state",
+		"order_service_orders_created_total",
+		"order_service_orders_status_transitions_total",
+		"order_service_cache_lookups_total",
+		"order_service_postgres_query_duration_seconds",
+	} {
+		assert.Contains(t, families, name)
+	}
+
+	assert.Equal(t, 3.0, families["order_service_concurrency_limiter_in_flight"].GetMetric()[0].GetGauge().GetValue())
+	assert.NotZero(t, families["order_service_postgres_query_duration_seconds"].GetMetric()[0].GetHistogram().GetSampleCount())
+}
+
+func TestOrdersCreated_IsLabelledByMarketAndType(t *testing.T) {
+	market := uuid.NewString()
+
+	metrics.OrdersCreated.WithLabelValues(market, "market").Inc()
+
+	family := gather(t)["order_service_orders_created_total"]
+	assert.NotNil(t, family)
+
+	var found bool
+	for _, metric := range family.GetMetric() {
+		labels := map[string]string{}
+		for _, label := range metric.GetLabel() {
+			labels[label.GetName()] = label.GetValue()
+		}
+		assert.Len(t, labels, 2)
+
+		if labels["market"] == market {
+			found = true
+			assert.Equal(t, "market", labels["type"])
+			assert.Equal(t, 1.0, metric.GetCounter().GetValue())
+		}
+	}
+	assert.True(t, found)
+}
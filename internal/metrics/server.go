@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	log "github.com/erdedan1/shared/logger"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	serverLayer = "MetricsServer"
+
+	readHeaderTimeout = 5 * time.Second
+	shutdownTimeout   = 5 * time.Second
+)
+
+// Server exposes Registry in the Prometheus text format on /metrics.
+type Server struct {
+	server *http.Server
+	log    log.Logger
+}
+
+func NewServer(address string, log log.Logger) *Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry}))
+
+	return &Server{
+		server: &http.Server{
+			Addr:              address,
+			Handler:           mux,
+			ReadHeaderTimeout: readHeaderTimeout,
+		},
+		log: log,
+	}
+}
+
+// Run serves metrics until ctx is cancelled.
+func (s *Server) Run(ctx context.Context) {
+	const method = "Run"
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		if err := s.server.Shutdown(shutdownCtx); err != nil {
+			s.log.Error(serverLayer, method, "metrics server shutdown failed", err)
+		}
+	}()
+
+	s.log.Info(serverLayer, method, "metrics server started", "address", s.server.Addr)
+
+	if err := s.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		s.log.Error(serverLayer, method, "metrics server failed", err, "address", s.server.Addr)
+	}
+}
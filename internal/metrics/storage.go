@@ -0,0 +1,67 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// ObservePostgres records the latency of a repository call, meant to be deferred:
+//
+//	defer metrics.ObservePostgres("CreateOrder", time.Now())
+func ObservePostgres(operation string, start time.Time) {
+	PostgresQueryDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+}
+
+type redisStartKey struct{}
+
+// RedisHook times every command sent through a go-redis client.
+type RedisHook struct{}
+
+var _ redis.Hook = RedisHook{}
+
+func (RedisHook) BeforeProcess(ctx context.Context, _ redis.Cmder) (context.Context, error) {
+	return context.WithValue(ctx, redisStartKey{}, time.Now()), nil
+}
+
+func (RedisHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	observeRedis(ctx, strings.ToLower(cmd.Name()), cmd.Err())
+	return nil
+}
+
+func (RedisHook) BeforeProcessPipeline(ctx context.Context, _ []redis.Cmder) (context.Context, error) {
+	return context.WithValue(ctx, redisStartKey{}, time.Now()), nil
+}
+
+func (RedisHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	var err error
+	for _, cmd := range cmds {
+		if cmdErr := cmd.Err(); cmdErr != nil {
+			err = cmdErr
+			break
+		}
+	}
+
+	observeRedis(ctx, "pipeline", err)
+	return nil
+}
+
+func observeRedis(ctx context.Context, command string, err error) {
+	start, ok := ctx.Value(redisStartKey{}).(time.Time)
+	if !ok {
+		return
+	}
+
+	result := "ok"
+	switch {
+	case errors.Is(err, redis.Nil):
+		result = "nil"
+	case err != nil:
+		result = "error"
+	}
+
+	RedisCommandDuration.WithLabelValues(command, result).Observe(time.Since(start).Seconds())
+}
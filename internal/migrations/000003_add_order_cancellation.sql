@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS cancel_reason VARCHAR(32),
+    ADD COLUMN IF NOT EXISTS cancelled_by UUID,
+    ADD COLUMN IF NOT EXISTS cancelled_at TIMESTAMPTZ;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS cancelled_at,
+    DROP COLUMN IF EXISTS cancelled_by,
+    DROP COLUMN IF EXISTS cancel_reason;
+-- +goose StatementEnd
\ No newline at end of file
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS orders_created_at_id_idx ON orders (created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS orders_user_created_at_id_idx ON orders (user_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS orders_market_created_at_id_idx ON orders (market_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS orders_status_created_at_id_idx ON orders (order_status, created_at DESC, id DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS orders_status_created_at_id_idx;
+DROP INDEX IF EXISTS orders_market_created_at_id_idx;
+DROP INDEX IF EXISTS orders_user_created_at_id_idx;
+DROP INDEX IF EXISTS orders_created_at_id_idx;
+-- +goose StatementEnd
\ No newline at end of file
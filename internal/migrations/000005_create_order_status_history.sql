@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS order_status_history (
+    id BIGSERIAL PRIMARY KEY,
+    order_id UUID NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+    from_status VARCHAR(32),
+    to_status VARCHAR(32) NOT NULL,
+    actor_type VARCHAR(16) NOT NULL,
+    actor_id UUID,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS order_status_history_order_id_idx ON order_status_history (order_id, changed_at, id);
+
+INSERT INTO order_status_history (order_id, from_status, to_status, actor_type, changed_at)
+SELECT id, NULL, order_status, 'SYSTEM', COALESCE(updated_at, created_at)
+FROM orders;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS order_status_history;
+-- +goose StatementEnd
\ No newline at end of file
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS next_transition_at TIMESTAMPTZ;
+
+-- orders that were mid-lifecycle before the worker existed continue right away
+UPDATE orders
+SET next_transition_at = NOW()
+WHERE deleted_at IS NULL
+    AND order_status IN (
+        'CREATED', 'PENDING', 'WAIT_SELLER', 'PAID', 'ON_HOLD',
+        'PROCESSING', 'PACKED', 'OUT_OF_DELIVERY', 'ON_THE_WAY'
+    );
+
+-- delivered orders close once their return window (model.ReturnWindow) has passed
+UPDATE orders
+SET next_transition_at = COALESCE(updated_at, created_at) + INTERVAL '14 days'
+WHERE deleted_at IS NULL
+    AND order_status = 'DELIVERED';
+
+CREATE INDEX IF NOT EXISTS orders_next_transition_at_idx ON orders (next_transition_at)
+    WHERE next_transition_at IS NOT NULL AND deleted_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS orders_next_transition_at_idx;
+ALTER TABLE orders DROP COLUMN IF EXISTS next_transition_at;
+-- +goose StatementEnd
\ No newline at end of file
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS client_order_id VARCHAR(64);
+
+CREATE UNIQUE INDEX IF NOT EXISTS orders_user_client_order_id_idx ON orders (user_id, client_order_id)
+    WHERE client_order_id IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS orders_user_client_order_id_idx;
+ALTER TABLE orders DROP COLUMN IF EXISTS client_order_id;
+-- +goose StatementEnd
\ No newline at end of file
@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS outbox_delivered_at_idx ON outbox (delivered_at) WHERE delivered_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS outbox_delivered_at_idx;
+-- +goose StatementEnd
\ No newline at end of file
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- events the relay gave up on, kept for operators and never claimed again
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS dead_lettered_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS outbox_dead_lettered_at_idx ON outbox (dead_lettered_at) WHERE dead_lettered_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS outbox_dead_lettered_at_idx;
+ALTER TABLE outbox DROP COLUMN IF EXISTS dead_lettered_at;
+-- +goose StatementEnd
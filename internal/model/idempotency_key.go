@@ -0,0 +1,30 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IdempotencyKey ties a client supplied key to the order it created, so a retried
+// CreateOrder replays the original result instead of creating a duplicate order.
+type IdempotencyKey struct {
+	UserID         uuid.UUID   `db:"user_id"`
+	Key            string      `db:"idempotency_key"`
+	RequestHash    string      `db:"request_hash"`
+	OrderID        uuid.UUID   `db:"order_id"`
+	OrderStatus    OrderStatus `db:"order_status"`
+	OrderCreatedAt time.Time   `db:"order_created_at"`
+	CreatedAt      time.Time   `db:"created_at"`
+	ExpiresAt      time.Time   `db:"expires_at"`
+}
+
+// Order returns the order as it was when the key was first used.
+func (k *IdempotencyKey) Order() *Order {
+	return &Order{
+		ID:        k.OrderID,
+		UserUUID:  k.UserID,
+		Status:    k.OrderStatus,
+		CreatedAt: new(k.OrderCreatedAt),
+	}
+}
@@ -0,0 +1,22 @@
+package model
+
+// RoleMarketsKeyPrefix keys the cached markets of a role, shared by all its users.
+const RoleMarketsKeyPrefix = "markets:role:"
+
+func RoleMarketsKey(role string) string {
+	return RoleMarketsKeyPrefix + role
+}
+
+// LastKnownMarketsKeyPrefix keys the markets of the last successful call to the spot
+// instrument service per role, served while its breaker is open.
+const LastKnownMarketsKeyPrefix = "markets:last_known:"
+
+func LastKnownMarketsKey(role string) string {
+	return LastKnownMarketsKeyPrefix + role
+}
+
+// MarketsInvalidation tells every replica which cached markets went stale. No roles
+// stands for the markets of every role.
+type MarketsInvalidation struct {
+	Roles []string `json:"roles,omitempty"`
+}
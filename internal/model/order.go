@@ -18,23 +18,36 @@ type Order struct {
 	CreatedAt  *time.Time      `db:"created_at"`
 	UpdatedAt  *time.Time      `db:"updated_at"`
 	DeletedAt  *time.Time      `db:"deleted_at"`
+
+	// ClientOrderID is the order id assigned by the client, unique per user.
+	ClientOrderID *string `db:"client_order_id"`
+
+	CancelReason *CancelReason `db:"cancel_reason"`
+	CancelledBy  *uuid.UUID    `db:"cancelled_by"`
+	CancelledAt  *time.Time    `db:"cancelled_at"`
+
+	// NextTransitionAt is when the lifecycle worker takes the next automatic step, nil when there is none.
+	NextTransitionAt *time.Time `db:"next_transition_at"`
 }
 
 type OrderStatus string
 
 const (
-	StatusCreated       OrderStatus = "CREATED"
-	StatusPending       OrderStatus = "PENDING"
-	StatusWaitSeller    OrderStatus = "WAIT_SELLER"
-	StatusPaid          OrderStatus = "PAID"
-	StatusOnHold        OrderStatus = "ON_HOLD"
-	StatusProcessing    OrderStatus = "PROCESSING"
-	StatusPacked        OrderStatus = "PACKED"
-	StatusOutOfDelivery OrderStatus = "OUT_OF_DELIVERY"
-	StatusOnTheWay      OrderStatus = "ON_THE_WAY"
-	StatusDelivered     OrderStatus = "DELIVERED"
-	StatusClosed        OrderStatus = "CLOSED"
-	StatusUnspecified   OrderStatus = "UNSPECIFIED"
+	StatusCreated         OrderStatus = "CREATED"
+	StatusPending         OrderStatus = "PENDING"
+	StatusWaitSeller      OrderStatus = "WAIT_SELLER"
+	StatusPaid            OrderStatus = "PAID"
+	StatusOnHold          OrderStatus = "ON_HOLD"
+	StatusProcessing      OrderStatus = "PROCESSING"
+	StatusPacked          OrderStatus = "PACKED"
+	StatusOutOfDelivery   OrderStatus = "OUT_OF_DELIVERY"
+	StatusOnTheWay        OrderStatus = "ON_THE_WAY"
+	StatusDelivered       OrderStatus = "DELIVERED"
+	StatusClosed          OrderStatus = "CLOSED"
+	StatusCancelled       OrderStatus = "CANCELLED"
+	StatusReturnRequested OrderStatus = "RETURN_REQUESTED"
+	StatusReturned        OrderStatus = "RETURNED"
+	StatusUnspecified     OrderStatus = "UNSPECIFIED"
 )
 
 func (o OrderStatus) ToString() string {
@@ -61,6 +74,12 @@ func (o OrderStatus) ToString() string {
 		return "DELIVERED"
 	case StatusClosed:
 		return "CLOSED"
+	case StatusCancelled:
+		return "CANCELLED"
+	case StatusReturnRequested:
+		return "RETURN_REQUESTED"
+	case StatusReturned:
+		return "RETURNED"
 	default:
 		return "UNSPECIFIED"
 	}
@@ -78,38 +97,34 @@ func (o OrderStatus) IsValid() bool {
 		StatusOutOfDelivery,
 		StatusOnTheWay,
 		StatusDelivered,
-		StatusClosed:
+		StatusClosed,
+		StatusCancelled,
+		StatusReturnRequested,
+		StatusReturned:
 		return true
 	default:
 		return false
 	}
 }
 
+// IsTerminal reports whether the order can no longer change its status.
+func (o OrderStatus) IsTerminal() bool {
+	return OrderWorkflow.IsTerminal(o)
+}
+
+// IsCancellable reports whether an order in this status may still be cancelled.
+func (o OrderStatus) IsCancellable() bool {
+	_, ok := OrderWorkflow.Transition(o, StatusCancelled)
+	return ok
+}
+
+func CancellableStatuses() []OrderStatus {
+	return OrderWorkflow.Sources(StatusCancelled)
+}
+
+// NextOrderStatus returns the status the order lifecycle moves to automatically.
 func NextOrderStatus(current OrderStatus) (OrderStatus, bool) {
-	switch current {
-	case StatusCreated:
-		return StatusPending, true
-	case StatusPending:
-		return StatusWaitSeller, true
-	case StatusWaitSeller:
-		return StatusPaid, true
-	case StatusPaid:
-		return StatusOnHold, true
-	case StatusOnHold:
-		return StatusProcessing, true
-	case StatusProcessing:
-		return StatusPacked, true
-	case StatusPacked:
-		return StatusOutOfDelivery, true
-	case StatusOutOfDelivery:
-		return StatusOnTheWay, true
-	case StatusOnTheWay:
-		return StatusDelivered, true
-	case StatusDelivered:
-		return StatusClosed, true
-	default:
-		return current, false
-	}
+	return OrderWorkflow.Next(current)
 }
 
 func NewOrder(
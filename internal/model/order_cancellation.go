@@ -0,0 +1,34 @@
+package model
+
+import (
+	"time"
+)
+
+type CancelReason string
+
+const (
+	CancelReasonUserRequested  CancelReason = "USER_REQUESTED"
+	CancelReasonPaymentFailed  CancelReason = "PAYMENT_FAILED"
+	CancelReasonOutOfStock     CancelReason = "OUT_OF_STOCK"
+	CancelReasonFraudSuspected CancelReason = "FRAUD_SUSPECTED"
+	CancelReasonAdminDecision  CancelReason = "ADMIN_DECISION"
+)
+
+func (r CancelReason) IsValid() bool {
+	switch r {
+	case CancelReasonUserRequested,
+		CancelReasonPaymentFailed,
+		CancelReasonOutOfStock,
+		CancelReasonFraudSuspected,
+		CancelReasonAdminDecision:
+		return true
+	default:
+		return false
+	}
+}
+
+type OrderCancellation struct {
+	Reason      CancelReason
+	Actor       OrderStatusActor
+	CancelledAt time.Time
+}
@@ -0,0 +1,110 @@
+package model
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidOrderCursor = errors.New("invalid order cursor")
+
+// OrderCursor points at the last order of a page in (created_at, id) order.
+type OrderCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+func (c OrderCursor) Encode() string {
+	raw := strconv.FormatInt(c.CreatedAt.UnixNano(), 10) + ":" + c.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodeOrderCursor(cursor string) (*OrderCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidOrderCursor
+	}
+
+	nanos, id, found := strings.Cut(string(raw), ":")
+	if !found {
+		return nil, ErrInvalidOrderCursor
+	}
+
+	unixNano, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidOrderCursor
+	}
+
+	orderID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, ErrInvalidOrderCursor
+	}
+
+	return &OrderCursor{CreatedAt: time.Unix(0, unixNano).UTC(), ID: orderID}, nil
+}
+
+// OrderFilter selects orders for listing. Zero values mean "any".
+// Orders are returned newest first, starting right after the After cursor.
+type OrderFilter struct {
+	UserUUID    uuid.UUID
+	MarketUUID  uuid.UUID
+	Statuses    []OrderStatus
+	Type        string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	After       *OrderCursor
+	Limit       int
+}
+
+func (f OrderFilter) Matches(order *Order) bool {
+	if f.UserUUID != uuid.Nil && order.UserUUID != f.UserUUID {
+		return false
+	}
+	if f.MarketUUID != uuid.Nil && order.MarketUUID != f.MarketUUID {
+		return false
+	}
+	if f.Type != "" && order.Type != f.Type {
+		return false
+	}
+	if len(f.Statuses) != 0 && !containsStatus(f.Statuses, order.Status) {
+		return false
+	}
+	if order.CreatedAt == nil {
+		return false
+	}
+	if f.CreatedFrom != nil && order.CreatedAt.Before(*f.CreatedFrom) {
+		return false
+	}
+	if f.CreatedTo != nil && !order.CreatedAt.Before(*f.CreatedTo) {
+		return false
+	}
+	if f.After != nil && !OrderBefore(order, f.After) {
+		return false
+	}
+
+	return true
+}
+
+// OrderBefore reports whether the order goes after the cursor in newest-first order.
+func OrderBefore(order *Order, cursor *OrderCursor) bool {
+	if order.CreatedAt.Equal(cursor.CreatedAt) {
+		return bytes.Compare(order.ID[:], cursor.ID[:]) < 0
+	}
+
+	return order.CreatedAt.Before(cursor.CreatedAt)
+}
+
+func containsStatus(statuses []OrderStatus, status OrderStatus) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+
+	return false
+}
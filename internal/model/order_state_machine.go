@@ -0,0 +1,244 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ReturnWindow is how long after delivery a customer may still request a return.
+const ReturnWindow = 14 * 24 * time.Hour
+
+// TransitionGuard is an extra condition an order has to satisfy to take a transition.
+type TransitionGuard struct {
+	Name  string
+	Check func(order *Order, now time.Time) bool
+}
+
+// OrderTransition is an allowed edge of the order workflow. Automatic transitions
+// are taken by the order lifecycle, the rest are triggered by users or admins.
+type OrderTransition struct {
+	From      OrderStatus
+	To        OrderStatus
+	Automatic bool
+	// Delay is how long an automatic transition waits after From was entered, zero
+	// means one lifecycle step.
+	Delay time.Duration
+	Guard *TransitionGuard
+}
+
+// TransitionError is returned when an order status change is not allowed by the workflow.
+type TransitionError struct {
+	From   OrderStatus
+	To     OrderStatus
+	Reason string
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("illegal order status transition %s -> %s: %s", e.From, e.To, e.Reason)
+}
+
+type OrderStateMachine struct {
+	initial     OrderStatus
+	terminal    map[OrderStatus]struct{}
+	transitions []OrderTransition
+}
+
+func NewOrderStateMachine(initial OrderStatus, terminal []OrderStatus, transitions []OrderTransition) *OrderStateMachine {
+	sm := &OrderStateMachine{
+		initial:     initial,
+		terminal:    make(map[OrderStatus]struct{}, len(terminal)),
+		transitions: transitions,
+	}
+	for _, status := range terminal {
+		sm.terminal[status] = struct{}{}
+	}
+
+	return sm
+}
+
+var withinReturnWindow = &TransitionGuard{
+	Name: "within return window",
+	Check: func(order *Order, now time.Time) bool {
+		return order.UpdatedAt != nil && now.Sub(*order.UpdatedAt) <= ReturnWindow
+	},
+}
+
+// OrderWorkflow is the order status workflow every status write is checked against.
+var OrderWorkflow = NewOrderStateMachine(
+	StatusCreated,
+	[]OrderStatus{StatusClosed, StatusCancelled, StatusReturned},
+	[]OrderTransition{
+		{From: StatusCreated, To: StatusPending, Automatic: true},
+		{From: StatusPending, To: StatusWaitSeller, Automatic: true},
+		{From: StatusWaitSeller, To: StatusPaid, Automatic: true},
+		{From: StatusPaid, To: StatusOnHold, Automatic: true},
+		{From: StatusOnHold, To: StatusProcessing, Automatic: true},
+		{From: StatusProcessing, To: StatusPacked, Automatic: true},
+		{From: StatusPacked, To: StatusOutOfDelivery, Automatic: true},
+		{From: StatusOutOfDelivery, To: StatusOnTheWay, Automatic: true},
+		{From: StatusOnTheWay, To: StatusDelivered, Automatic: true},
+		// closing waits for the return window, so a return can still be requested
+		{From: StatusDelivered, To: StatusClosed, Automatic: true, Delay: ReturnWindow},
+
+		{From: StatusCreated, To: StatusCancelled},
+		{From: StatusPending, To: StatusCancelled},
+		{From: StatusWaitSeller, To: StatusCancelled},
+		{From: StatusPaid, To: StatusCancelled},
+		{From: StatusOnHold, To: StatusCancelled},
+
+		{From: StatusDelivered, To: StatusReturnRequested, Guard: withinReturnWindow},
+		{From: StatusReturnRequested, To: StatusReturned},
+		{From: StatusReturnRequested, To: StatusClosed},
+	},
+)
+
+func (sm *OrderStateMachine) Initial() OrderStatus {
+	return sm.initial
+}
+
+func (sm *OrderStateMachine) IsTerminal(status OrderStatus) bool {
+	_, ok := sm.terminal[status]
+	return ok
+}
+
+func (sm *OrderStateMachine) Transitions() []OrderTransition {
+	return append([]OrderTransition(nil), sm.transitions...)
+}
+
+func (sm *OrderStateMachine) Transition(from, to OrderStatus) (OrderTransition, bool) {
+	for _, t := range sm.transitions {
+		if t.From == from && t.To == to {
+			return t, true
+		}
+	}
+
+	return OrderTransition{}, false
+}
+
+// Next returns the target of the automatic transition leaving the given status.
+func (sm *OrderStateMachine) Next(from OrderStatus) (OrderStatus, bool) {
+	t, ok := sm.automatic(from)
+	return t.To, ok
+}
+
+// NextTransitionAt returns when an order that entered the given status at the given
+// time is due for its next automatic transition, or nil if the status has none.
+func (sm *OrderStateMachine) NextTransitionAt(status OrderStatus, enteredAt time.Time, step time.Duration) *time.Time {
+	t, ok := sm.automatic(status)
+	if !ok {
+		return nil
+	}
+
+	if t.Delay > 0 {
+		return new(enteredAt.Add(t.Delay))
+	}
+	return new(enteredAt.Add(step))
+}
+
+func (sm *OrderStateMachine) automatic(from OrderStatus) (OrderTransition, bool) {
+	for _, t := range sm.transitions {
+		if t.From == from && t.Automatic {
+			return t, true
+		}
+	}
+
+	return OrderTransition{From: from, To: from}, false
+}
+
+// Sources returns every status the workflow allows to move into the given one.
+func (sm *OrderStateMachine) Sources(to OrderStatus) []OrderStatus {
+	var sources []OrderStatus
+	for _, t := range sm.transitions {
+		if t.To == to {
+			sources = append(sources, t.From)
+		}
+	}
+
+	return sources
+}
+
+// CanTransition checks that the order may move to the given status right now.
+func (sm *OrderStateMachine) CanTransition(order *Order, to OrderStatus, now time.Time) error {
+	from := order.Status
+
+	if sm.IsTerminal(from) {
+		return &TransitionError{From: from, To: to, Reason: "order is in a terminal status"}
+	}
+
+	t, ok := sm.Transition(from, to)
+	if !ok {
+		return &TransitionError{From: from, To: to, Reason: "transition is not allowed"}
+	}
+
+	if t.Guard != nil && !t.Guard.Check(order, now) {
+		return &TransitionError{From: from, To: to, Reason: "guard failed: " + t.Guard.Name}
+	}
+
+	return nil
+}
+
+// Mermaid renders the workflow as a Mermaid state diagram.
+func (sm *OrderStateMachine) Mermaid() string {
+	var b strings.Builder
+
+	b.WriteString("stateDiagram-v2\n")
+	fmt.Fprintf(&b, "    [*] --> %s\n", sm.initial)
+	for _, t := range sm.transitions {
+		fmt.Fprintf(&b, "    %s --> %s : %s\n", t.From, t.To, transitionLabel(t))
+	}
+	for _, status := range sm.terminalStatuses() {
+		fmt.Fprintf(&b, "    %s --> [*]\n", status)
+	}
+
+	return b.String()
+}
+
+// Graphviz renders the workflow as a Graphviz DOT digraph.
+func (sm *OrderStateMachine) Graphviz() string {
+	var b strings.Builder
+
+	b.WriteString("digraph OrderWorkflow {\n")
+	b.WriteString("    rankdir=LR;\n")
+	b.WriteString("    node [shape=box, style=rounded];\n")
+	fmt.Fprintf(&b, "    %q [style=\"rounded,bold\"];\n", sm.initial)
+	for _, status := range sm.terminalStatuses() {
+		fmt.Fprintf(&b, "    %q [shape=doublecircle];\n", status)
+	}
+	for _, t := range sm.transitions {
+		style := "solid"
+		if !t.Automatic {
+			style = "dashed"
+		}
+		fmt.Fprintf(&b, "    %q -> %q [label=%q, style=%s];\n", t.From, t.To, transitionLabel(t), style)
+	}
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+func (sm *OrderStateMachine) terminalStatuses() []OrderStatus {
+	var statuses []OrderStatus
+	seen := make(map[OrderStatus]struct{}, len(sm.terminal))
+	for _, t := range sm.transitions {
+		if _, ok := seen[t.To]; ok || !sm.IsTerminal(t.To) {
+			continue
+		}
+		seen[t.To] = struct{}{}
+		statuses = append(statuses, t.To)
+	}
+
+	return statuses
+}
+
+func transitionLabel(t OrderTransition) string {
+	label := "manual"
+	if t.Automatic {
+		label = "auto"
+	}
+	if t.Guard != nil {
+		label += " [" + t.Guard.Name + "]"
+	}
+
+	return label
+}
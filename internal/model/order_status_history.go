@@ -0,0 +1,57 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ActorType string
+
+const (
+	ActorSystem ActorType = "SYSTEM"
+	ActorUser   ActorType = "USER"
+	ActorAdmin  ActorType = "ADMIN"
+)
+
+// OrderStatusActor is whoever caused an order status change.
+// System changes, made by the order lifecycle, have no ID.
+type OrderStatusActor struct {
+	Type ActorType
+	ID   *uuid.UUID
+}
+
+func SystemActor() OrderStatusActor {
+	return OrderStatusActor{Type: ActorSystem}
+}
+
+func UserActor(id uuid.UUID) OrderStatusActor {
+	return OrderStatusActor{Type: ActorUser, ID: &id}
+}
+
+func AdminActor(id uuid.UUID) OrderStatusActor {
+	return OrderStatusActor{Type: ActorAdmin, ID: &id}
+}
+
+// OrderStatusChange is one entry of the order status timeline.
+// FromStatus is empty for the entry that created the order.
+type OrderStatusChange struct {
+	ID         int64        `db:"id"`
+	OrderID    uuid.UUID    `db:"order_id"`
+	FromStatus *OrderStatus `db:"from_status"`
+	ToStatus   OrderStatus  `db:"to_status"`
+	ActorType  ActorType    `db:"actor_type"`
+	ActorID    *uuid.UUID   `db:"actor_id"`
+	ChangedAt  time.Time    `db:"changed_at"`
+}
+
+func NewOrderStatusChange(orderID uuid.UUID, from *OrderStatus, to OrderStatus, actor OrderStatusActor, changedAt time.Time) OrderStatusChange {
+	return OrderStatusChange{
+		OrderID:    orderID,
+		FromStatus: from,
+		ToStatus:   to,
+		ActorType:  actor.Type,
+		ActorID:    actor.ID,
+		ChangedAt:  changedAt,
+	}
+}
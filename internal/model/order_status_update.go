@@ -0,0 +1,11 @@
+package model
+
+import "time"
+
+// OrderStatusUpdate is a status change written to an order together with the actor
+// that made it and the moment the lifecycle worker should pick the order up next.
+type OrderStatusUpdate struct {
+	Status           OrderStatus
+	Actor            OrderStatusActor
+	NextTransitionAt *time.Time
+}
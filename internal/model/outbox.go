@@ -0,0 +1,47 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const EventOrderStatusChanged = "order.status_changed"
+
+// OutboxEvent is an event stored in the same transaction as the change that produced it
+// and delivered to subscribers later by the outbox relay.
+type OutboxEvent struct {
+	ID          int64     `db:"id"`
+	AggregateID uuid.UUID `db:"aggregate_id"`
+	EventType   string    `db:"event_type"`
+	Payload     []byte    `db:"payload"`
+	Attempts    int       `db:"attempts"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+type OrderStatusEvent struct {
+	// ID is the position of the event in the status stream. It is only set by
+	// transports that can replay events and is passed back by clients to resume.
+	ID         string      `json:"-"`
+	OrderID    uuid.UUID   `json:"order_id"`
+	Status     OrderStatus `json:"status"`
+	OccurredAt time.Time   `json:"occurred_at"`
+}
+
+func NewOrderStatusOutboxEvent(orderID uuid.UUID, status OrderStatus, occurredAt time.Time) (OutboxEvent, error) {
+	payload, err := json.Marshal(OrderStatusEvent{
+		OrderID:    orderID,
+		Status:     status,
+		OccurredAt: occurredAt,
+	})
+	if err != nil {
+		return OutboxEvent{}, err
+	}
+
+	return OutboxEvent{
+		AggregateID: orderID,
+		EventType:   EventOrderStatusChanged,
+		Payload:     payload,
+	}, nil
+}
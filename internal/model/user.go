@@ -1,11 +1,35 @@
 package model
 
 import (
+	"slices"
+	"time"
+
 	"github.com/google/uuid"
 )
 
+// Roles a user can hold, named after the proto UserRole values.
+const (
+	RoleTrader = "USER_ROLE_TRADER"
+	RoleAdmin  = "USER_ROLE_ADMIN"
+)
+
 type User struct {
-	ID   uuid.UUID
-	Name string
-	Role string
+	ID    uuid.UUID
+	Name  string
+	Roles []string
+
+	// DeactivatedAt is set once the user is deactivated, such users can not place orders.
+	DeactivatedAt *time.Time
+}
+
+func (u *User) HasRole(role string) bool {
+	return slices.Contains(u.Roles, role)
+}
+
+func (u *User) HasAnyRole(roles ...string) bool {
+	return slices.ContainsFunc(roles, u.HasRole)
+}
+
+func (u *User) IsActive() bool {
+	return u.DeactivatedAt == nil
 }
@@ -0,0 +1,65 @@
+package policy
+
+import (
+	"slices"
+
+	"OrderService/internal/model"
+)
+
+// Action is something a user asks the service to do, every RPC is authorized as one.
+type Action string
+
+const (
+	ActionCreateOrder      Action = "order:create"
+	ActionViewOrder        Action = "order:view"
+	ActionViewAnyOrder     Action = "order:view_any"
+	ActionCancelOrder      Action = "order:cancel"
+	ActionCancelAnyOrder   Action = "order:cancel_any"
+	ActionForceOrderStatus Action = "order:force_status"
+	// ActionOperateService covers the operator endpoints of the admin server.
+	ActionOperateService Action = "service:operate"
+)
+
+// Policy maps actions to the roles allowed to take them. It is always evaluated against
+// the stored user, never against a role the client claims to have.
+type Policy struct {
+	rules map[Action][]string
+}
+
+func New(rules map[Action][]string) *Policy {
+	p := &Policy{rules: make(map[Action][]string, len(rules))}
+	for action, roles := range rules {
+		p.rules[action] = slices.Clone(roles)
+	}
+
+	return p
+}
+
+// Default is the policy the service runs with: traders work with their own orders,
+// admins may also act on other users' orders, force order statuses and operate the service.
+func Default() *Policy {
+	return New(map[Action][]string{
+		ActionCreateOrder:      {model.RoleTrader, model.RoleAdmin},
+		ActionViewOrder:        {model.RoleTrader, model.RoleAdmin},
+		ActionViewAnyOrder:     {model.RoleAdmin},
+		ActionCancelOrder:      {model.RoleTrader, model.RoleAdmin},
+		ActionCancelAnyOrder:   {model.RoleAdmin},
+		ActionForceOrderStatus: {model.RoleAdmin},
+		ActionOperateService:   {model.RoleAdmin},
+	})
+}
+
+// Allows reports whether the user may take the action. Deactivated users and actions
+// without a rule are denied.
+func (p *Policy) Allows(user *model.User, action Action) bool {
+	if user == nil || !user.IsActive() {
+		return false
+	}
+
+	return user.HasAnyRole(p.rules[action]...)
+}
+
+// AllowsRole reports whether the role is one the action is allowed to.
+func (p *Policy) AllowsRole(role string, action Action) bool {
+	return slices.Contains(p.rules[action], role)
+}
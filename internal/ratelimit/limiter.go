@@ -0,0 +1,37 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Rate is the shape of a token bucket: it refills PerSecond tokens a second up to Burst.
+type Rate struct {
+	PerSecond float64
+	Burst     float64
+}
+
+// Result is the outcome of taking tokens from a bucket.
+type Result struct {
+	Allowed bool
+	// Remaining is what is left in the bucket after the call.
+	Remaining float64
+	// RetryAfter is how long until the bucket holds enough tokens, zero when allowed.
+	RetryAfter time.Duration
+}
+
+// Limiter keeps a token bucket per key.
+type Limiter interface {
+	// Take takes cost tokens from the bucket of key, creating a full one shaped by rate
+	// if there is none. A rejected call takes nothing.
+	Take(ctx context.Context, key string, rate Rate, cost float64) (Result, error)
+}
+
+// retryAfter is how long a bucket refilling at rate needs to get from tokens to cost.
+func retryAfter(tokens, cost float64, rate Rate) time.Duration {
+	if tokens >= cost || rate.PerSecond <= 0 {
+		return 0
+	}
+
+	return time.Duration((cost - tokens) / rate.PerSecond * float64(time.Second))
+}
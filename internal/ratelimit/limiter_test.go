@@ -0,0 +1,85 @@
+package ratelimit_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"OrderService/internal/model"
+	"OrderService/internal/ratelimit"
+
+	log "github.com/erdedan1/shared/logger"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryLimiter_TakesAndEvictsIdleBuckets(t *testing.T) {
+	ctx := context.Background()
+	limiter := ratelimit.NewMemory(50 * time.Millisecond)
+	rate := ratelimit.Rate{PerSecond: 1, Burst: 2}
+
+	for range 2 {
+		result, err := limiter.Take(ctx, "user:1", rate, 1)
+		assert.NoError(t, err)
+		assert.True(t, result.Allowed)
+	}
+
+	result, err := limiter.Take(ctx, "user:1", rate, 1)
+	assert.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Greater(t, result.RetryAfter, time.Duration(0))
+
+	_, _ = limiter.Take(ctx, "user:2", rate, 1)
+	assert.Equal(t, 2, limiter.Len())
+
+	time.Sleep(60 * time.Millisecond)
+	_, _ = limiter.Take(ctx, "user:3", rate, 1)
+	assert.Equal(t, 1, limiter.Len())
+}
+
+func TestRedisLimiter_FallsBackToLocalBuckets(t *testing.T) {
+	logger, _ := log.NewLogger("debug")
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1})
+	defer client.Close()
+
+	limiter := ratelimit.NewRedis(client, "ratelimit:", time.Minute, 50*time.Millisecond, logger)
+	rate := ratelimit.Rate{PerSecond: 0.001, Burst: 1}
+
+	result, err := limiter.Take(context.Background(), "user:1", rate, 1)
+	assert.NoError(t, err)
+	assert.True(t, result.Allowed)
+
+	result, err = limiter.Take(context.Background(), "user:1", rate, 1)
+	assert.NoError(t, err)
+	assert.False(t, result.Allowed)
+}
+
+func TestPolicies_ResolveMostSpecificRate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policies.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{
+		"default": {"requests_per_second": 10, "burst": 20},
+		"roles": {"USER_ROLE_ADMIN": {"requests_per_second": 100, "burst": 200}},
+		"methods": {
+			"/order.v1.OrderService/SubscribeOrderStatus": {"cost": 5},
+			"/order.v1.OrderService/CreateOrder": {"roles": {"USER_ROLE_TRADER": {"requests_per_second": 1, "burst": 2}}}
+		}
+	}`), 0o600))
+
+	policies, err := ratelimit.LoadPolicies(path, ratelimit.Rate{PerSecond: 50, Burst: 100})
+	assert.NoError(t, err)
+
+	rule := policies.Resolve("/order.v1.OrderService/GetOrderStatus", nil)
+	assert.Equal(t, ratelimit.Rule{Scope: ratelimit.DefaultScope, Rate: ratelimit.Rate{PerSecond: 10, Burst: 20}, Cost: 1}, rule)
+
+	rule = policies.Resolve("/order.v1.OrderService/SubscribeOrderStatus", []string{model.RoleAdmin})
+	assert.Equal(t, ratelimit.Rule{Scope: ratelimit.DefaultScope, Rate: ratelimit.Rate{PerSecond: 100, Burst: 200}, Cost: 5}, rule)
+
+	rule = policies.Resolve("/order.v1.OrderService/CreateOrder", []string{model.RoleTrader})
+	assert.Equal(t, ratelimit.Rule{Scope: "/order.v1.OrderService/CreateOrder", Rate: ratelimit.Rate{PerSecond: 1, Burst: 2}, Cost: 1}, rule)
+
+	assert.NoError(t, os.WriteFile(path, []byte(`{"default": {"requests_per_second": 0, "burst": 1}}`), 0o600))
+	_, err = ratelimit.LoadPolicies(path, ratelimit.Rate{PerSecond: 50, Burst: 100})
+	assert.Error(t, err)
+}
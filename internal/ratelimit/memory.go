@@ -0,0 +1,83 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type bucket struct {
+	tokens     float64
+	refilledAt time.Time
+}
+
+// Memory keeps the buckets in process. Buckets idle for longer than idleTTL are dropped,
+// with idleTTL above Burst/PerSecond they would have been full again anyway.
+type Memory struct {
+	idleTTL time.Duration
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	sweptAt time.Time
+}
+
+func NewMemory(idleTTL time.Duration) *Memory {
+	return &Memory{
+		idleTTL: idleTTL,
+		buckets: make(map[string]*bucket),
+		sweptAt: time.Now(),
+	}
+}
+
+func (m *Memory) Take(_ context.Context, key string, rate Rate, cost float64) (Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	m.sweep(now)
+
+	b, exists := m.buckets[key]
+	if !exists {
+		b = &bucket{
+			tokens:     rate.Burst,
+			refilledAt: now,
+		}
+		m.buckets[key] = b
+	}
+
+	elapsed := now.Sub(b.refilledAt).Seconds()
+	b.refilledAt = now
+	b.tokens = min(rate.Burst, b.tokens+elapsed*rate.PerSecond)
+
+	if b.tokens < cost {
+		return Result{
+			Remaining:  b.tokens,
+			RetryAfter: retryAfter(b.tokens, cost, rate),
+		}, nil
+	}
+	b.tokens -= cost
+
+	return Result{Allowed: true, Remaining: b.tokens}, nil
+}
+
+// Len is the number of buckets currently kept.
+func (m *Memory) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return len(m.buckets)
+}
+
+// sweep drops idle buckets, at most once per idleTTL so the cost stays amortized.
+func (m *Memory) sweep(now time.Time) {
+	if now.Sub(m.sweptAt) < m.idleTTL {
+		return
+	}
+	m.sweptAt = now
+
+	for key, b := range m.buckets {
+		if now.Sub(b.refilledAt) >= m.idleTTL {
+			delete(m.buckets, key)
+		}
+	}
+}
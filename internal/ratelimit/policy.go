@@ -0,0 +1,196 @@
+package ratelimit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// DefaultScope is the bucket scope of calls without a method specific rate, they all
+// share one bucket per client.
+const DefaultScope = "*"
+
+// RateSpec is a rate as written in the policy file.
+type RateSpec struct {
+	RequestsPerSecond float64 `json:"requests_per_second"`
+	Burst             float64 `json:"burst"`
+}
+
+// MethodSpec tunes one RPC. Cost is the number of tokens a call takes, a method with its
+// own Rate or Roles gets a bucket of its own instead of sharing the client bucket.
+type MethodSpec struct {
+	Cost  float64             `json:"cost"`
+	Rate  *RateSpec           `json:"rate"`
+	Roles map[string]RateSpec `json:"roles"`
+}
+
+// PolicyFile is the JSON layout of the rate limit policy file:
+//
+//	{
+//	  "default": {"requests_per_second": 50, "burst": 100},
+//	  "roles": {"USER_ROLE_ADMIN": {"requests_per_second": 200, "burst": 400}},
+//	  "methods": {
+//	    "/order.v1.OrderService/SubscribeOrderStatus": {"cost": 10},
+//	    "/order.v1.OrderService/CreateOrder": {"roles": {"USER_ROLE_TRADER": {"requests_per_second": 5, "burst": 10}}}
+//	  }
+//	}
+type PolicyFile struct {
+	Default *RateSpec             `json:"default"`
+	Roles   map[string]RateSpec   `json:"roles"`
+	Methods map[string]MethodSpec `json:"methods"`
+}
+
+// Rule is what applies to one call: the tokens it takes and the bucket it takes them from.
+type Rule struct {
+	// Scope tells the buckets of a client apart, calls with the same scope share one.
+	Scope string
+	Rate  Rate
+	Cost  float64
+}
+
+// Policies resolve the rule of a call by its full method name and the roles of the caller.
+// The most specific rate wins: method and role, method, role, default. A caller with
+// several matching roles gets the most generous of them.
+type Policies struct {
+	file     PolicyFile
+	fallback Rate
+}
+
+// NewPolicies are policies without a file, every call costs one token of the fallback rate.
+func NewPolicies(fallback Rate) *Policies {
+	return &Policies{fallback: fallback}
+}
+
+// LoadPolicies reads the policy file at path. Rates missing from the file fall back to fallback.
+func LoadPolicies(path string, fallback Rate) (*Policies, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read rate limit policy file: %w", err)
+	}
+
+	var file PolicyFile
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return nil, fmt.Errorf("parse rate limit policy file: %w", err)
+	}
+
+	if err := file.validate(); err != nil {
+		return nil, err
+	}
+
+	return &Policies{file: file, fallback: fallback}, nil
+}
+
+// UsesRoles tells whether any rate depends on the roles of the caller, if none does
+// Resolve can be called without them.
+func (p *Policies) UsesRoles() bool {
+	if len(p.file.Roles) > 0 {
+		return true
+	}
+	for _, method := range p.file.Methods {
+		if len(method.Roles) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *Policies) Resolve(fullMethod string, roles []string) Rule {
+	rule := Rule{
+		Scope: DefaultScope,
+		Rate:  p.fallback,
+		Cost:  1,
+	}
+
+	if p.file.Default != nil {
+		rule.Rate = p.file.Default.rate()
+	}
+	if rate, ok := mostGenerous(p.file.Roles, roles); ok {
+		rule.Rate = rate
+	}
+
+	method, ok := p.file.Methods[fullMethod]
+	if !ok {
+		return rule
+	}
+
+	if method.Cost > 0 {
+		rule.Cost = method.Cost
+	}
+	if method.Rate != nil {
+		rule.Scope = fullMethod
+		rule.Rate = method.Rate.rate()
+	}
+	if rate, ok := mostGenerous(method.Roles, roles); ok {
+		rule.Scope = fullMethod
+		rule.Rate = rate
+	}
+
+	// a call costing more than the bucket holds could never pass
+	rule.Cost = min(rule.Cost, rule.Rate.Burst)
+
+	return rule
+}
+
+func mostGenerous(specs map[string]RateSpec, roles []string) (Rate, bool) {
+	var (
+		best  Rate
+		found bool
+	)
+	for _, role := range roles {
+		spec, ok := specs[role]
+		if !ok {
+			continue
+		}
+		if rate := spec.rate(); !found || rate.PerSecond > best.PerSecond {
+			best, found = rate, true
+		}
+	}
+
+	return best, found
+}
+
+func (s RateSpec) rate() Rate {
+	return Rate{PerSecond: s.RequestsPerSecond, Burst: s.Burst}
+}
+
+func (s RateSpec) validate() error {
+	if s.RequestsPerSecond <= 0 || s.Burst <= 0 {
+		return errors.New("requests_per_second and burst must be positive")
+	}
+
+	return nil
+}
+
+func (f PolicyFile) validate() error {
+	if f.Default != nil {
+		if err := f.Default.validate(); err != nil {
+			return fmt.Errorf("default rate: %w", err)
+		}
+	}
+
+	for role, spec := range f.Roles {
+		if err := spec.validate(); err != nil {
+			return fmt.Errorf("rate of role %s: %w", role, err)
+		}
+	}
+
+	for name, method := range f.Methods {
+		if method.Cost < 0 {
+			return fmt.Errorf("cost of method %s must not be negative", name)
+		}
+		if method.Rate != nil {
+			if err := method.Rate.validate(); err != nil {
+				return fmt.Errorf("rate of method %s: %w", name, err)
+			}
+		}
+		for role, spec := range method.Roles {
+			if err := spec.validate(); err != nil {
+				return fmt.Errorf("rate of role %s on method %s: %w", role, name, err)
+			}
+		}
+	}
+
+	return nil
+}
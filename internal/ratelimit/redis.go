@@ -0,0 +1,168 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"OrderService/pkg/cache"
+
+	log "github.com/erdedan1/shared/logger"
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	layer = "RedisRateLimiter"
+
+	// redisRetryInterval is how long the limiter stays on the local fallback after Redis failed.
+	redisRetryInterval = time.Second
+)
+
+// takeScript is the token bucket of Memory run atomically inside Redis. The clock is the
+// one of Redis, so replicas with skewed clocks still agree on the refill.
+//
+// KEYS[1] bucket, ARGV rate per second, burst, cost, idle ttl in milliseconds.
+// Returns allowed (0/1), the remaining tokens as a string and the retry delay in milliseconds.
+var takeScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local cost = tonumber(ARGV[3])
+local ttl = tonumber(ARGV[4])
+
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = burst
+	ts = now
+end
+
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate / 1000)
+
+local allowed = 0
+local retry = 0
+if tokens >= cost then
+	tokens = tokens - cost
+	allowed = 1
+elseif rate > 0 then
+	retry = math.ceil((cost - tokens) * 1000 / rate)
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], ttl)
+
+return {allowed, tostring(tokens), retry}
+`)
+
+// Redis shares the buckets between all replicas, so a client gets the configured rate
+// once and not once per replica. Idle buckets expire after idleTTL. While Redis is
+// unreachable the buckets of the local fallback are used instead.
+type Redis struct {
+	client    cache.RedisClient
+	keyPrefix string
+	idleTTL   time.Duration
+	timeout   time.Duration
+	fallback  *Memory
+	log       log.Logger
+
+	mu        sync.Mutex
+	downUntil time.Time
+}
+
+func NewRedis(client cache.RedisClient, keyPrefix string, idleTTL, timeout time.Duration, log log.Logger) *Redis {
+	return &Redis{
+		client:    client,
+		keyPrefix: keyPrefix,
+		idleTTL:   idleTTL,
+		timeout:   timeout,
+		fallback:  NewMemory(idleTTL),
+		log:       log,
+	}
+}
+
+func (r *Redis) Take(ctx context.Context, key string, rate Rate, cost float64) (Result, error) {
+	const method = "Take"
+
+	if r.isDown() {
+		return r.fallback.Take(ctx, key, rate, cost)
+	}
+
+	result, err := r.take(ctx, key, rate, cost)
+	if err != nil {
+		if r.markDown() {
+			r.log.Error(layer, method, "redis rate limiter unavailable, falling back to local buckets", err)
+		}
+		return r.fallback.Take(ctx, key, rate, cost)
+	}
+
+	if r.markUp() {
+		r.log.Info(layer, method, "redis rate limiter recovered")
+	}
+
+	return result, nil
+}
+
+func (r *Redis) take(ctx context.Context, key string, rate Rate, cost float64) (Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	reply, err := takeScript.Run(ctx, r.client, []string{r.keyPrefix + key},
+		rate.PerSecond,
+		rate.Burst,
+		cost,
+		r.idleTTL.Milliseconds(),
+	).Slice()
+	if err != nil {
+		return Result{}, err
+	}
+
+	if len(reply) != 3 {
+		return Result{}, errors.New("unexpected rate limiter script reply")
+	}
+	allowed, _ := reply[0].(int64)
+	remainingRaw, _ := reply[1].(string)
+	retryMillis, _ := reply[2].(int64)
+
+	remaining, err := strconv.ParseFloat(remainingRaw, 64)
+	if err != nil {
+		return Result{}, err
+	}
+
+	return Result{
+		Allowed:    allowed == 1,
+		Remaining:  remaining,
+		RetryAfter: time.Duration(retryMillis) * time.Millisecond,
+	}, nil
+}
+
+func (r *Redis) isDown() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return time.Now().Before(r.downUntil)
+}
+
+// markDown reports whether Redis was considered up until now.
+func (r *Redis) markDown() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	wasUp := r.downUntil.IsZero()
+	r.downUntil = time.Now().Add(redisRetryInterval)
+	return wasUp
+}
+
+// markUp reports whether Redis was considered down until now.
+func (r *Redis) markUp() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	wasDown := !r.downUntil.IsZero()
+	r.downUntil = time.Time{}
+	return wasDown
+}
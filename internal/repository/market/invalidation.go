@@ -0,0 +1,264 @@
+package market
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	errs "OrderService/internal/errors"
+	"OrderService/internal/model"
+	"OrderService/pkg/cache"
+
+	sharedErrs "github.com/erdedan1/shared/errs"
+	log "github.com/erdedan1/shared/logger"
+	"github.com/go-redis/redis/v8"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	invalidatorLayer = "MarketCacheInvalidator"
+
+	resubscribeDelay = time.Second
+	scanCount        = 100
+)
+
+// subscription is the part of a *redis.PubSub the invalidator listens through.
+type subscription interface {
+	Receive(ctx context.Context) (interface{}, error)
+	Channel(opts ...redis.ChannelOption) <-chan *redis.Message
+	Close() error
+}
+
+// Invalidator drops stale markets from the cache when a change is announced on the
+// invalidation channel. Every replica runs one: whichever gets a message deletes the keys
+// from Redis, which is idempotent, and evicts its local copies, which only it can do.
+type Invalidator struct {
+	client    cache.RedisClient
+	subscribe func(ctx context.Context, channel string) subscription
+	local     *tieredMarketsCache
+	channel   string
+	log       log.Logger
+	tracer    trace.Tracer
+}
+
+func NewInvalidator(
+	client cache.RedisClient,
+	local *tieredMarketsCache,
+	channel string,
+	log log.Logger,
+	tp trace.TracerProvider,
+) *Invalidator {
+	return &Invalidator{
+		client: client,
+		subscribe: func(ctx context.Context, channel string) subscription {
+			return client.Subscribe(ctx, channel)
+		},
+		local:   local,
+		channel: channel,
+		log:     log,
+		tracer:  tp.Tracer("order-service/MarketCacheInvalidator"),
+	}
+}
+
+// Run listens on the invalidation channel until ctx is cancelled, subscribing again
+// whenever the subscription fails.
+func (i *Invalidator) Run(ctx context.Context) {
+	for {
+		i.listen(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(resubscribeDelay):
+		}
+	}
+}
+
+func (i *Invalidator) listen(ctx context.Context) {
+	const method = "listen"
+
+	pubsub := i.subscribe(ctx, i.channel)
+	defer func() {
+		_ = pubsub.Close()
+	}()
+
+	if _, err := pubsub.Receive(ctx); err != nil {
+		if ctx.Err() == nil {
+			i.log.Error(invalidatorLayer, method, "failed to subscribe to market invalidations", err, "channel", i.channel)
+		}
+		return
+	}
+
+	// pub/sub keeps no history, what was announced while unsubscribed may be cached locally
+	i.local.Purge()
+	i.log.Info(invalidatorLayer, method, "listening for market invalidations", "channel", i.channel)
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+
+			var invalidation model.MarketsInvalidation
+			if msg.Payload != "" {
+				if err := json.Unmarshal([]byte(msg.Payload), &invalidation); err != nil {
+					// not knowing what changed, everything is dropped
+					i.log.Error(invalidatorLayer, method, "invalid market invalidation payload", err, "payload", msg.Payload)
+					invalidation = model.MarketsInvalidation{}
+				}
+			}
+
+			_, _ = i.Invalidate(ctx, invalidation)
+		}
+	}
+}
+
+// Invalidate drops the markets of the roles, or of every role, from Redis and from the
+// local tier of this replica, the last known ones served while the spot instrument service
+// is unavailable included. It returns the number of Redis keys deleted.
+func (i *Invalidator) Invalidate(ctx context.Context, invalidation model.MarketsInvalidation) (int64, *sharedErrs.CustomError) {
+	const method = "Invalidate"
+
+	ctx, span := i.tracer.Start(ctx, "MarketCacheInvalidator.Invalidate")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.StringSlice("roles", invalidation.Roles),
+	)
+
+	keys := make([]string, 0, 2*len(invalidation.Roles))
+	for _, role := range invalidation.Roles {
+		// evicted first, so this replica stops serving them even if Redis fails
+		i.local.Evict(model.RoleMarketsKey(role))
+		keys = append(keys, model.RoleMarketsKey(role), model.LastKnownMarketsKey(role))
+	}
+	if len(keys) == 0 {
+		i.local.Purge()
+	}
+
+	deleted, err := i.deleteRemote(ctx, keys)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, errs.ErrDeleteRedis.Message)
+
+		i.log.Error(invalidatorLayer, method, errs.ErrDeleteRedis.Message, err, "roles", invalidation.Roles)
+		return deleted, errs.ErrDeleteRedis
+	}
+
+	span.SetStatus(codes.Ok, "markets invalidated")
+	i.log.Info(invalidatorLayer, method, "markets invalidated", "roles", invalidation.Roles, "deleted", deleted)
+
+	return deleted, nil
+}
+
+// Publish announces the invalidation to every replica, this one included.
+func (i *Invalidator) Publish(ctx context.Context, invalidation model.MarketsInvalidation) *sharedErrs.CustomError {
+	const method = "Publish"
+
+	ctx, span := i.tracer.Start(ctx, "MarketCacheInvalidator.Publish")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("channel", i.channel),
+	)
+
+	payload, err := json.Marshal(invalidation)
+	if err != nil {
+		span.RecordError(errs.ErrFailedSerializeRedis)
+		span.SetStatus(codes.Error, errs.ErrFailedSerializeRedis.Message)
+
+		i.log.Error(invalidatorLayer, method, "failed to marshal invalidation", err)
+		return errs.ErrFailedSerializeRedis
+	}
+
+	if err := i.client.Publish(ctx, i.channel, payload).Err(); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, errs.ErrUnavailableRedis.Message)
+
+		i.log.Error(invalidatorLayer, method, err.Error(), err, "channel", i.channel)
+		return errs.ErrUnavailableRedis
+	}
+
+	span.SetStatus(codes.Ok, "invalidation published")
+	return nil
+}
+
+// deleteRemote deletes keys from Redis, or every role's markets when there are none.
+func (i *Invalidator) deleteRemote(ctx context.Context, keys []string) (int64, error) {
+	if len(keys) > 0 {
+		return i.client.Del(ctx, keys...).Result()
+	}
+
+	var deleted int64
+	for _, prefix := range []string{model.RoleMarketsKeyPrefix, model.LastKnownMarketsKeyPrefix} {
+		n, err := i.deletePrefix(ctx, prefix)
+		deleted += n
+		if err != nil {
+			return deleted, err
+		}
+	}
+
+	return deleted, nil
+}
+
+func (i *Invalidator) deletePrefix(ctx context.Context, prefix string) (int64, error) {
+	var (
+		deleted int64
+		cursor  uint64
+	)
+	for {
+		batch, next, err := i.client.Scan(ctx, cursor, prefix+"*", scanCount).Result()
+		if err != nil {
+			return deleted, err
+		}
+
+		if len(batch) > 0 {
+			n, err := i.client.Del(ctx, batch...).Result()
+			deleted += n
+			if err != nil {
+				return deleted, err
+			}
+		}
+
+		if next == 0 {
+			return deleted, nil
+		}
+		cursor = next
+	}
+}
+
+// FlushHandler drops the markets of the roles in the request body, or of every role when
+// there is no body, here and on every other replica.
+func (i *Invalidator) FlushHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var invalidation model.MarketsInvalidation
+		if err := json.NewDecoder(r.Body).Decode(&invalidation); err != nil && !errors.Is(err, io.EOF) {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		deleted, err := i.Invalidate(r.Context(), invalidation)
+		if err != nil {
+			http.Error(w, err.Message, http.StatusServiceUnavailable)
+			return
+		}
+
+		// the other replicas only hear of it through the channel
+		if err := i.Publish(r.Context(), invalidation); err != nil {
+			http.Error(w, err.Message, http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]int64{"deleted_keys": deleted})
+	})
+}
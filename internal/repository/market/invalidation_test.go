@@ -0,0 +1,339 @@
+package market
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"OrderService/config"
+	"OrderService/internal/admin"
+	"OrderService/internal/auth"
+	"OrderService/internal/dto"
+	"OrderService/internal/model"
+	"OrderService/mocks"
+	"OrderService/pkg/cache"
+
+	log "github.com/erdedan1/shared/logger"
+	"github.com/go-redis/redis/v8"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// fakeRedis keeps plain string keys in memory, enough for the market cache.
+type fakeRedis struct {
+	cache.RedisClient
+
+	mu        sync.Mutex
+	data      map[string]string
+	published []string
+}
+
+func newFakeRedis() *fakeRedis {
+	return &fakeRedis{data: make(map[string]string)}
+}
+
+func (r *fakeRedis) Get(_ context.Context, key string) *redis.StringCmd {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	value, ok := r.data[key]
+	if !ok {
+		return redis.NewStringResult("", redis.Nil)
+	}
+	return redis.NewStringResult(value, nil)
+}
+
+func (r *fakeRedis) Set(_ context.Context, key string, value interface{}, _ time.Duration) *redis.StatusCmd {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.data[key] = string(value.([]byte))
+	return redis.NewStatusResult("OK", nil)
+}
+
+func (r *fakeRedis) Del(_ context.Context, keys ...string) *redis.IntCmd {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted int64
+	for _, key := range keys {
+		if _, ok := r.data[key]; ok {
+			delete(r.data, key)
+			deleted++
+		}
+	}
+	return redis.NewIntResult(deleted, nil)
+}
+
+func (r *fakeRedis) Scan(_ context.Context, _ uint64, match string, _ int64) *redis.ScanCmd {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var keys []string
+	for key := range r.data {
+		if strings.HasPrefix(key, strings.TrimSuffix(match, "*")) {
+			keys = append(keys, key)
+		}
+	}
+	return redis.NewScanCmdResult(keys, 0, nil)
+}
+
+func (r *fakeRedis) Publish(_ context.Context, _ string, message interface{}) *redis.IntCmd {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.published = append(r.published, string(message.([]byte)))
+	return redis.NewIntResult(1, nil)
+}
+
+func (r *fakeRedis) keys() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	keys := make([]string, 0, len(r.data))
+	for key := range r.data {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// fakeSubscription hands the messages sent on it to the invalidator, or fails to subscribe.
+type fakeSubscription struct {
+	err      error
+	messages chan *redis.Message
+	closed   chan struct{}
+}
+
+func (s *fakeSubscription) Receive(context.Context) (interface{}, error) {
+	return nil, s.err
+}
+
+func (s *fakeSubscription) Channel(...redis.ChannelOption) <-chan *redis.Message {
+	return s.messages
+}
+
+func (s *fakeSubscription) Close() error {
+	close(s.closed)
+	return nil
+}
+
+func newTestInvalidator(t *testing.T, client *fakeRedis) (*tieredMarketsCache, *Invalidator) {
+	t.Helper()
+
+	logger, _ := log.NewLogger("debug")
+	tp := noop.NewTracerProvider()
+
+	tiered := NewTieredMarketsCache(NewMarketsCache(client, logger, tp), config.MarketCacheConfig{
+		LocalCapacity: 8,
+		LocalTTL:      time.Minute,
+		LoadTimeout:   time.Second,
+	}, logger, tp)
+
+	return tiered, NewInvalidator(client, tiered, "markets:invalidate", logger, tp)
+}
+
+func TestInvalidator_DropsMarketsFromBothTiers(t *testing.T) {
+	ctx := context.Background()
+
+	client := newFakeRedis()
+	for _, role := range []string{model.RoleTrader, model.RoleAdmin} {
+		client.data[model.LastKnownMarketsKey(role)] = "[]"
+	}
+	tiered, invalidator := newTestInvalidator(t, client)
+
+	markets := []dto.ViewMarketsResponse{{UUID: uuid.New(), Enabled: true}}
+	for _, role := range []string{model.RoleTrader, model.RoleAdmin} {
+		assert.Nil(t, tiered.Set(ctx, model.RoleMarketsKey(role), markets, time.Minute))
+	}
+
+	deleted, err := invalidator.Invalidate(ctx, model.MarketsInvalidation{Roles: []string{model.RoleTrader}})
+	assert.Nil(t, err)
+	// the last known markets go too, the breaker fallback must not serve them either
+	assert.Equal(t, int64(2), deleted)
+	assert.NotContains(t, client.data, model.LastKnownMarketsKey(model.RoleTrader))
+	assert.Contains(t, client.data, model.LastKnownMarketsKey(model.RoleAdmin))
+
+	got, err := tiered.Get(ctx, model.RoleMarketsKey(model.RoleTrader))
+	assert.Nil(t, err)
+	assert.Nil(t, got)
+	got, err = tiered.Get(ctx, model.RoleMarketsKey(model.RoleAdmin))
+	assert.Nil(t, err)
+	assert.Equal(t, markets, got)
+
+	// no roles drops the markets of every role
+	deleted, err = invalidator.Invalidate(ctx, model.MarketsInvalidation{})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), deleted)
+
+	got, err = tiered.Get(ctx, model.RoleMarketsKey(model.RoleAdmin))
+	assert.Nil(t, err)
+	assert.Nil(t, got)
+	assert.Empty(t, client.data)
+}
+
+func TestInvalidator_RunDropsAnnouncedMarkets(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := newFakeRedis()
+	tiered, invalidator := newTestInvalidator(t, client)
+
+	subscriptions := make(chan *fakeSubscription, 3)
+	subscriptions <- &fakeSubscription{err: errors.New("connection refused"), closed: make(chan struct{})}
+	for range 2 {
+		subscriptions <- &fakeSubscription{messages: make(chan *redis.Message), closed: make(chan struct{})}
+	}
+	subscribed := make(chan *fakeSubscription, 3)
+	invalidator.subscribe = func(_ context.Context, channel string) subscription {
+		assert.Equal(t, "markets:invalidate", channel)
+		sub := <-subscriptions
+		subscribed <- sub
+		return sub
+	}
+
+	markets := []dto.ViewMarketsResponse{{UUID: uuid.New(), Enabled: true}}
+	for _, role := range []string{model.RoleTrader, model.RoleAdmin} {
+		assert.Nil(t, tiered.Set(ctx, model.RoleMarketsKey(role), markets, time.Minute))
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		invalidator.Run(ctx)
+	}()
+
+	// the failed subscription is closed and subscribed again
+	failed := <-subscribed
+	<-failed.closed
+	first := <-subscribed
+
+	first.messages <- &redis.Message{Channel: "markets:invalidate", Payload: `{"roles":["` + model.RoleTrader + `"]}`}
+	assert.Eventually(t, func() bool {
+		keys := client.keys()
+		return len(keys) == 1 && keys[0] == model.RoleMarketsKey(model.RoleAdmin)
+	}, time.Second, 5*time.Millisecond)
+
+	// a payload it can not read drops everything
+	first.messages <- &redis.Message{Channel: "markets:invalidate", Payload: "{"}
+	assert.Eventually(t, func() bool {
+		return len(client.keys()) == 0
+	}, time.Second, 5*time.Millisecond)
+
+	// a closed channel subscribes again, purging what may have been missed meanwhile
+	assert.Nil(t, tiered.Set(ctx, model.RoleMarketsKey(model.RoleAdmin), markets, time.Minute))
+	client.mu.Lock()
+	delete(client.data, model.RoleMarketsKey(model.RoleAdmin))
+	client.mu.Unlock()
+
+	close(first.messages)
+	<-first.closed
+	<-subscribed
+
+	assert.Eventually(t, func() bool {
+		got, err := tiered.Get(ctx, model.RoleMarketsKey(model.RoleAdmin))
+		return err == nil && got == nil
+	}, 2*time.Second, 5*time.Millisecond)
+
+	cancel()
+	<-stopped
+}
+
+func TestInvalidator_FlushHandler(t *testing.T) {
+	client := newFakeRedis()
+	client.data[model.RoleMarketsKey(model.RoleTrader)] = "[]"
+	_, invalidator := newTestInvalidator(t, client)
+
+	handler := invalidator.FlushHandler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/market-cache/flush", strings.NewReader("roles")))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Empty(t, client.published)
+
+	rec = httptest.NewRecorder()
+	body := `{"roles":["` + model.RoleTrader + `"]}`
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/market-cache/flush", strings.NewReader(body)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"deleted_keys":1}`, rec.Body.String())
+	assert.Empty(t, client.keys())
+	// the other replicas hear of it
+	assert.Equal(t, []string{body}, client.published)
+
+	// no body flushes every role
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/market-cache/flush", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"deleted_keys":0}`, rec.Body.String())
+	assert.Equal(t, "{}", client.published[1])
+}
+
+func TestInvalidator_FlushHandlerNeedsStoredAdmin(t *testing.T) {
+	const secret = "admin-secret"
+
+	deactivatedAt := time.Now()
+
+	tests := []struct {
+		name   string
+		stored *model.User
+		want   int
+	}{
+		{name: "stored admin", stored: &model.User{Roles: []string{model.RoleAdmin}}, want: http.StatusOK},
+		// the token still claims admin, the user is a trader now
+		{name: "stored trader", stored: &model.User{Roles: []string{model.RoleTrader}}, want: http.StatusForbidden},
+		{name: "deactivated admin", stored: &model.User{Roles: []string{model.RoleAdmin}, DeactivatedAt: &deactivatedAt}, want: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newFakeRedis()
+			client.data[model.RoleMarketsKey(model.RoleTrader)] = "[]"
+			_, invalidator := newTestInvalidator(t, client)
+
+			verifier, cerr := auth.NewVerifier(config.AuthConfig{
+				Algorithm:  config.AuthAlgorithmHS256,
+				HMACSecret: secret,
+				Audience:   "order-service",
+			})
+			assert.Nil(t, cerr)
+
+			users := mocks.NewUserRepo(t)
+			userID := uuid.New()
+			tt.stored.ID = userID
+			users.On("GetUserById", mock.Anything, userID).Return(tt.stored, nil)
+
+			logger, _ := log.NewLogger("debug")
+			server := admin.NewServer("127.0.0.1:0", verifier, users, nil, logger)
+			server.Handle("POST /admin/market-cache/flush", invalidator.FlushHandler())
+
+			token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, auth.Claims{
+				Roles: []string{model.RoleAdmin},
+				RegisteredClaims: jwt.RegisteredClaims{
+					Subject:   userID.String(),
+					Audience:  jwt.ClaimStrings{"order-service"},
+					ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+				},
+			}).SignedString([]byte(secret))
+			assert.NoError(t, err)
+
+			request := httptest.NewRequest(http.MethodPost, "/admin/market-cache/flush", nil)
+			request.Header.Set("Authorization", "Bearer "+token)
+			rec := httptest.NewRecorder()
+			server.ServeHTTP(rec, request)
+
+			assert.Equal(t, tt.want, rec.Code)
+			if tt.want != http.StatusOK {
+				// nothing was flushed, here or on the other replicas
+				assert.Len(t, client.keys(), 1)
+				assert.Empty(t, client.published)
+			}
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 
 	"OrderService/internal/dto"
 	errs "OrderService/internal/errors"
+	"OrderService/internal/usecase"
 	"OrderService/pkg/cache"
 
 	error "github.com/erdedan1/shared/errs"
@@ -136,3 +137,26 @@ func (c *redisMarketsCache) Del(ctx context.Context, key string) *error.CustomEr
 
 	return nil
 }
+
+func (c *redisMarketsCache) GetOrLoad(
+	ctx context.Context,
+	key string,
+	ttl time.Duration,
+	load usecase.MarketsLoader,
+) ([]dto.ViewMarketsResponse, *error.CustomError) {
+	markets, err := c.Get(ctx, key)
+	if err != nil || markets != nil {
+		return markets, err
+	}
+
+	markets, err = load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.Set(ctx, key, markets, ttl); err != nil {
+		return nil, err
+	}
+
+	return markets, nil
+}
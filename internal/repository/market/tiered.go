@@ -0,0 +1,191 @@
+package market
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"OrderService/config"
+	"OrderService/internal/dto"
+	"OrderService/internal/metrics"
+	"OrderService/internal/usecase"
+	"OrderService/pkg/cache"
+
+	sharedErrs "github.com/erdedan1/shared/errs"
+	log "github.com/erdedan1/shared/logger"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	tieredLayer = "TieredMarketCache"
+
+	// cacheName labels the lookup metrics of the market cache.
+	cacheName = "markets"
+)
+
+// tieredMarketsCache keeps the markets in process in front of the Redis cache. Misses of
+// a key are coalesced, so one Redis GET, and on a miss there one load, serves all the
+// requests waiting for it. Local entries outlive their TTL for a stale window in which
+// they are served while being refreshed.
+type tieredMarketsCache struct {
+	local  *cache.Tiered[[]dto.ViewMarketsResponse]
+	remote usecase.MarketCacheRepo
+	log    log.Logger
+	tracer trace.Tracer
+}
+
+func NewTieredMarketsCache(
+	remote usecase.MarketCacheRepo,
+	cfg config.MarketCacheConfig,
+	log log.Logger,
+	tp trace.TracerProvider,
+) *tieredMarketsCache {
+	localLookups := metrics.CacheLookups.MustCurryWith(map[string]string{"cache": cacheName, "tier": "local"})
+
+	return &tieredMarketsCache{
+		local: cache.NewTiered[[]dto.ViewMarketsResponse](cache.TieredOptions{
+			Capacity:    cfg.LocalCapacity,
+			TTL:         cfg.LocalTTL,
+			StaleTTL:    cfg.StaleTTL,
+			LoadTimeout: cfg.LoadTimeout,
+			OnLookup: func(lookup cache.Lookup) {
+				localLookups.WithLabelValues(string(lookup)).Inc()
+			},
+		}),
+		remote: remote,
+		log:    log,
+		tracer: tp.Tracer("order-service/TieredMarketCache"),
+	}
+}
+
+func (c *tieredMarketsCache) Set(ctx context.Context, key string, value []dto.ViewMarketsResponse, ttl time.Duration) *sharedErrs.CustomError {
+	if err := c.remote.Set(ctx, key, value, ttl); err != nil {
+		return err
+	}
+	c.local.Set(key, value)
+
+	return nil
+}
+
+func (c *tieredMarketsCache) Get(ctx context.Context, key string) ([]dto.ViewMarketsResponse, *sharedErrs.CustomError) {
+	return c.get(ctx, key, cache.Source[[]dto.ViewMarketsResponse]{
+		Load: func(ctx context.Context) ([]dto.ViewMarketsResponse, bool, error) {
+			markets, err := c.remoteGet(ctx, key)
+			if err != nil {
+				return nil, false, err
+			}
+
+			// a literal nil, a nil *CustomError in the error interface would not be nil
+			return markets, markets != nil, nil
+		},
+	})
+}
+
+func (c *tieredMarketsCache) GetOrLoad(
+	ctx context.Context,
+	key string,
+	ttl time.Duration,
+	load usecase.MarketsLoader,
+) ([]dto.ViewMarketsResponse, *sharedErrs.CustomError) {
+	// set by Load when Redis missed too, only then does Store write the markets there
+	var loaded bool
+
+	return c.get(ctx, key, cache.Source[[]dto.ViewMarketsResponse]{
+		Load: func(ctx context.Context) ([]dto.ViewMarketsResponse, bool, error) {
+			markets, err := c.remoteGet(ctx, key)
+			if err != nil {
+				return nil, false, err
+			}
+			if markets != nil {
+				return markets, true, nil
+			}
+
+			markets, err = load(ctx)
+			if err != nil {
+				return nil, false, err
+			}
+			loaded = true
+
+			return markets, true, nil
+		},
+		// skipped when key was invalidated during the load, the markets may predate it
+		Store: func(ctx context.Context, markets []dto.ViewMarketsResponse) error {
+			if !loaded {
+				return nil
+			}
+			if err := c.remote.Set(ctx, key, markets, ttl); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	})
+}
+
+func (c *tieredMarketsCache) Del(ctx context.Context, key string) *sharedErrs.CustomError {
+	// dropped locally first, a failed remote delete must not leave this instance serving it
+	c.local.Delete(key)
+
+	return c.remote.Del(ctx, key)
+}
+
+// Evict drops the markets of key kept in process, Redis is left alone.
+func (c *tieredMarketsCache) Evict(key string) {
+	c.local.Delete(key)
+}
+
+// Purge drops every market kept in process, Redis is left alone.
+func (c *tieredMarketsCache) Purge() {
+	c.local.Purge()
+}
+
+func (c *tieredMarketsCache) get(
+	ctx context.Context,
+	key string,
+	source cache.Source[[]dto.ViewMarketsResponse],
+) ([]dto.ViewMarketsResponse, *sharedErrs.CustomError) {
+	const method = "get"
+
+	ctx, span := c.tracer.Start(ctx, "TieredMarketCache.Get")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("key", key),
+	)
+
+	markets, _, err := c.local.Get(ctx, key, source)
+	if err != nil {
+		var customErr *sharedErrs.CustomError
+		if !errors.As(err, &customErr) {
+			customErr = sharedErrs.New(sharedErrs.INTERNAL, err.Error(), err)
+		}
+
+		span.RecordError(customErr)
+		span.SetStatus(codes.Error, customErr.Message)
+
+		c.log.Error(tieredLayer, method, "failed to get markets", customErr, "key", key)
+		return nil, customErr
+	}
+
+	span.SetStatus(codes.Ok, "markets success get")
+
+	return markets, nil
+}
+
+// remoteGet looks key up in Redis and counts the outcome.
+func (c *tieredMarketsCache) remoteGet(ctx context.Context, key string) ([]dto.ViewMarketsResponse, *sharedErrs.CustomError) {
+	markets, err := c.remote.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	result := cache.LookupMiss
+	if markets != nil {
+		result = cache.LookupHit
+	}
+	metrics.CacheLookups.WithLabelValues(cacheName, "redis", string(result)).Inc()
+
+	return markets, nil
+}
@@ -2,6 +2,7 @@ package order
 
 import (
 	"context"
+	"slices"
 	"sync"
 	"time"
 
@@ -17,36 +18,80 @@ import (
 )
 
 type Repo struct {
-	Orders map[uuid.UUID]*model.Order
-	mu     *sync.RWMutex
-	log    log.Logger
-	tracer trace.Tracer
+	Orders          map[uuid.UUID]*model.Order
+	History         map[uuid.UUID][]model.OrderStatusChange
+	IdempotencyKeys map[idempotencyKeyID]model.IdempotencyKey
+	mu              *sync.RWMutex
+	log             log.Logger
+	tracer          trace.Tracer
+}
+
+type idempotencyKeyID struct {
+	userID uuid.UUID
+	key    string
 }
 
 func NewRepo(logger log.Logger, tp trace.TracerProvider) *Repo {
 	return &Repo{
-		Orders: make(map[uuid.UUID]*model.Order),
-		mu:     &sync.RWMutex{},
-		log:    logger,
-		tracer: tp.Tracer("order-service/Repo"),
+		Orders:          make(map[uuid.UUID]*model.Order),
+		History:         make(map[uuid.UUID][]model.OrderStatusChange),
+		IdempotencyKeys: make(map[idempotencyKeyID]model.IdempotencyKey),
+		mu:              &sync.RWMutex{},
+		log:             logger,
+		tracer:          tp.Tracer("order-service/Repo"),
 	}
 }
 
 const layerInMemory = "OrderRepo"
 
-func (r *Repo) CreateOrder(ctx context.Context, order *model.Order) (*model.Order, *errors.CustomError) {
+func (r *Repo) CreateOrder(ctx context.Context, order *model.Order, key *model.IdempotencyKey) (*model.Order, *errors.CustomError) {
 	ctx, span := r.tracer.Start(ctx, "OrderRepo.CreateOrder")
 	defer span.End()
 
 	r.mu.Lock()
 	defer r.mu.Unlock()
+
+	if key != nil {
+		stored, found := r.IdempotencyKeys[idempotencyKeyID{key.UserID, key.Key}]
+		if found && stored.ExpiresAt.After(time.Now()) {
+			if stored.RequestHash != key.RequestHash {
+				span.SetStatus(codes.Error, errs.ErrIdempotencyKeyConflict.Message)
+				return nil, errs.ErrIdempotencyKeyConflict
+			}
+
+			span.SetStatus(codes.Ok, "order create replayed")
+			return stored.Order(), nil
+		}
+	}
+
+	if order.ClientOrderID != nil {
+		if _, found := r.findByClientOrderID(order.UserUUID, *order.ClientOrderID); found {
+			span.SetStatus(codes.Error, errs.ErrClientOrderIDAlreadyExists.Message)
+			return nil, errs.ErrClientOrderIDAlreadyExists
+		}
+	}
+
 	order.ID = uuid.New()
 
 	span.SetAttributes(
 		attribute.String("order.id", order.ID.String()),
 	)
 
+	if order.CreatedAt == nil {
+		order.CreatedAt = new(time.Now())
+	}
+
 	r.Orders[order.ID] = order
+	r.History[order.ID] = append(r.History[order.ID], model.NewOrderStatusChange(order.ID, nil, order.Status, model.UserActor(order.UserUUID), *order.CreatedAt))
+
+	if key != nil {
+		stored := *key
+		stored.OrderID = order.ID
+		stored.OrderStatus = order.Status
+		stored.OrderCreatedAt = *order.CreatedAt
+		stored.CreatedAt = time.Now()
+		r.IdempotencyKeys[idempotencyKeyID{key.UserID, key.Key}] = stored
+	}
 
 	span.SetStatus(codes.Ok, "order success created")
 
@@ -95,7 +140,11 @@ func (r *Repo) GetOrder(ctx context.Context, id uuid.UUID) (*model.Order, *error
 	return nil, errs.ErrOrderNotFound
 }
 
-func (r *Repo) UpdateOrderStatus(ctx context.Context, id uuid.UUID, status model.OrderStatus) *errors.CustomError {
+func (r *Repo) GetOrderByID(ctx context.Context, id uuid.UUID) (*model.Order, *errors.CustomError) {
+	return r.GetOrder(ctx, id)
+}
+
+func (r *Repo) UpdateOrderStatus(ctx context.Context, id uuid.UUID, update model.OrderStatusUpdate) *errors.CustomError {
 	const method = "UpdateOrder"
 
 	ctx, span := r.tracer.Start(ctx, "OrderRepo.UpdateOrder")
@@ -104,6 +153,20 @@ func (r *Repo) UpdateOrderStatus(ctx context.Context, id uuid.UUID, status model
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	if o, found := r.Orders[id]; found {
+		now := time.Now()
+		if err := model.OrderWorkflow.CanTransition(o, update.Status, now); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+
+			r.log.Error(
+				layerInMemory,
+				method,
+				err.Error(), err,
+				"order_id", id,
+				"order_status", o.Status,
+			)
+			return errs.IllegalStatusTransition(err)
+		}
 
 		span.SetStatus(codes.Ok, "order success updated")
 
@@ -115,8 +178,10 @@ func (r *Repo) UpdateOrderStatus(ctx context.Context, id uuid.UUID, status model
 			"user_id", o.UserUUID,
 			"order_status", o.Status,
 		)
-		o.Status = status
-		o.UpdatedAt = new(time.Now())
+		r.History[id] = append(r.History[id], model.NewOrderStatusChange(id, &o.Status, update.Status, update.Actor, now))
+		o.Status = update.Status
+		o.UpdatedAt = &now
+		o.NextTransitionAt = update.NextTransitionAt
 		return nil
 	}
 
@@ -133,3 +198,235 @@ func (r *Repo) UpdateOrderStatus(ctx context.Context, id uuid.UUID, status model
 
 	return errs.ErrOrderNotFound
 }
+
+func (r *Repo) CancelOrder(ctx context.Context, id uuid.UUID, cancellation model.OrderCancellation) *errors.CustomError {
+	const method = "CancelOrder"
+
+	ctx, span := r.tracer.Start(ctx, "OrderRepo.CancelOrder")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	o, found := r.Orders[id]
+	if !found {
+		span.RecordError(errs.ErrOrderNotFound)
+		span.SetStatus(codes.Error, errs.ErrOrderNotFound.Message)
+
+		r.log.Error(
+			layerInMemory,
+			method,
+			"not found order",
+			errs.ErrOrderNotFound,
+			"order_id", id,
+		)
+		return errs.ErrOrderNotFound
+	}
+
+	if err := model.OrderWorkflow.CanTransition(o, model.StatusCancelled, cancellation.CancelledAt); err != nil {
+		span.RecordError(errs.ErrOrderCannotBeCancelled)
+		span.SetStatus(codes.Error, errs.ErrOrderCannotBeCancelled.Message)
+
+		r.log.Error(
+			layerInMemory,
+			method,
+			errs.ErrOrderCannotBeCancelled.Message,
+			errs.ErrOrderCannotBeCancelled,
+			"order_id", id,
+			"order_status", o.Status,
+		)
+		return errs.ErrOrderCannotBeCancelled
+	}
+
+	r.History[id] = append(r.History[id], model.NewOrderStatusChange(id, &o.Status, model.StatusCancelled, cancellation.Actor, cancellation.CancelledAt))
+	o.Status = model.StatusCancelled
+	o.CancelReason = new(cancellation.Reason)
+	o.CancelledBy = cancellation.Actor.ID
+	o.CancelledAt = new(cancellation.CancelledAt)
+	o.UpdatedAt = new(cancellation.CancelledAt)
+	o.NextTransitionAt = nil
+
+	span.SetStatus(codes.Ok, "order success cancelled")
+
+	r.log.Debug(
+		layerInMemory,
+		method,
+		"order success cancelled",
+		"order_id", id,
+		"reason", cancellation.Reason,
+	)
+
+	return nil
+}
+
+func (r *Repo) ListOrders(ctx context.Context, filter model.OrderFilter) ([]model.Order, *errors.CustomError) {
+	const method = "ListOrders"
+
+	ctx, span := r.tracer.Start(ctx, "OrderRepo.ListOrders")
+	defer span.End()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	orders := make([]model.Order, 0, filter.Limit)
+	for _, o := range r.Orders {
+		if o.DeletedAt != nil || !filter.Matches(o) {
+			continue
+		}
+		orders = append(orders, *o)
+	}
+
+	slices.SortFunc(orders, func(a, b model.Order) int {
+		if model.OrderBefore(&a, &model.OrderCursor{CreatedAt: *b.CreatedAt, ID: b.ID}) {
+			return 1
+		}
+		if model.OrderBefore(&b, &model.OrderCursor{CreatedAt: *a.CreatedAt, ID: a.ID}) {
+			return -1
+		}
+		return 0
+	})
+
+	if filter.Limit > 0 && len(orders) > filter.Limit {
+		orders = orders[:filter.Limit]
+	}
+
+	span.SetStatus(codes.Ok, "list orders success")
+
+	r.log.Debug(
+		layerInMemory,
+		method,
+		"list orders",
+		"count", len(orders),
+	)
+
+	return orders, nil
+}
+
+func (r *Repo) GetOrderHistory(ctx context.Context, orderID uuid.UUID) ([]model.OrderStatusChange, *errors.CustomError) {
+	ctx, span := r.tracer.Start(ctx, "OrderRepo.GetOrderHistory")
+	defer span.End()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	history := append([]model.OrderStatusChange(nil), r.History[orderID]...)
+
+	span.SetStatus(codes.Ok, "get order history success")
+
+	r.log.Debug(
+		layerInMemory,
+		"GetOrderHistory",
+		"get order history",
+		"order_id", orderID,
+		"count", len(history),
+	)
+
+	return history, nil
+}
+
+func (r *Repo) ClaimDueOrders(ctx context.Context, limit int, lease time.Duration) ([]model.Order, *errors.CustomError) {
+	ctx, span := r.tracer.Start(ctx, "OrderRepo.ClaimDueOrders")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	leasedUntil := now.Add(lease)
+
+	var orders []model.Order
+	for _, o := range r.Orders {
+		if len(orders) == limit {
+			break
+		}
+		if o.NextTransitionAt == nil || o.NextTransitionAt.After(now) {
+			continue
+		}
+
+		o.NextTransitionAt = new(leasedUntil)
+		orders = append(orders, *o)
+	}
+
+	span.SetStatus(codes.Ok, "due orders claimed")
+
+	r.log.Debug(
+		layerInMemory,
+		"ClaimDueOrders",
+		"due orders claimed",
+		"count", len(orders),
+	)
+
+	return orders, nil
+}
+
+func (r *Repo) DeleteExpiredIdempotencyKeys(ctx context.Context, before time.Time) (int64, *errors.CustomError) {
+	_, span := r.tracer.Start(ctx, "OrderRepo.DeleteExpiredIdempotencyKeys")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted int64
+	for id, key := range r.IdempotencyKeys {
+		if !key.ExpiresAt.After(before) {
+			delete(r.IdempotencyKeys, id)
+			deleted++
+		}
+	}
+
+	span.SetStatus(codes.Ok, "expired idempotency keys deleted")
+
+	return deleted, nil
+}
+
+func (r *Repo) GetIdempotentOrder(ctx context.Context, key *model.IdempotencyKey) (*model.Order, *errors.CustomError) {
+	_, span := r.tracer.Start(ctx, "OrderRepo.GetIdempotentOrder")
+	defer span.End()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored, found := r.IdempotencyKeys[idempotencyKeyID{key.UserID, key.Key}]
+	if !found || !stored.ExpiresAt.After(time.Now()) {
+		span.SetStatus(codes.Ok, "idempotency key not used yet")
+		return nil, errs.ErrOrderNotFound
+	}
+
+	if stored.RequestHash != key.RequestHash {
+		span.SetStatus(codes.Error, errs.ErrIdempotencyKeyConflict.Message)
+		return nil, errs.ErrIdempotencyKeyConflict
+	}
+
+	span.SetStatus(codes.Ok, "idempotent order found")
+
+	return stored.Order(), nil
+}
+
+func (r *Repo) GetOrderByClientOrderID(ctx context.Context, userID uuid.UUID, clientOrderID string) (*model.Order, *errors.CustomError) {
+	_, span := r.tracer.Start(ctx, "OrderRepo.GetOrderByClientOrderID")
+	defer span.End()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	order, found := r.findByClientOrderID(userID, clientOrderID)
+	if !found {
+		span.RecordError(errs.ErrOrderNotFound)
+		span.SetStatus(codes.Error, errs.ErrOrderNotFound.Message)
+		return nil, errs.ErrOrderNotFound
+	}
+
+	span.SetStatus(codes.Ok, "get order success")
+
+	return order, nil
+}
+
+func (r *Repo) findByClientOrderID(userID uuid.UUID, clientOrderID string) (*model.Order, bool) {
+	for _, o := range r.Orders {
+		if o.UserUUID == userID && o.ClientOrderID != nil && *o.ClientOrderID == clientOrderID {
+			return o, true
+		}
+	}
+
+	return nil, false
+}
@@ -0,0 +1,96 @@
+package order
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	errs "OrderService/internal/errors"
+	"OrderService/internal/metrics"
+	"OrderService/internal/model"
+
+	errorz "github.com/erdedan1/shared/errs"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+func (r *Repository) CancelOrder(ctx context.Context, id uuid.UUID, cancellation model.OrderCancellation) *errorz.CustomError {
+	const method = "CancelOrder"
+	defer metrics.ObservePostgres(method, time.Now())
+
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.CancelOrder")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("order.id", id.String()),
+		attribute.String("cancel.reason", string(cancellation.Reason)),
+	)
+
+	query := `
+			UPDATE orders
+			SET order_status = $1, cancel_reason = $2, cancelled_by = $3, cancelled_at = $4, updated_at = $4, next_transition_at = NULL
+			WHERE id = $5
+		`
+
+	var from model.OrderStatus
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		order, err := getOrderForUpdate(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		from = order.Status
+
+		if err := model.OrderWorkflow.CanTransition(order, model.StatusCancelled, cancellation.CancelledAt); err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(
+			ctx, query,
+			model.StatusCancelled,
+			cancellation.Reason,
+			cancellation.Actor.ID,
+			cancellation.CancelledAt,
+			id,
+		)
+		if err != nil {
+			return err
+		}
+
+		change := model.NewOrderStatusChange(id, &order.Status, model.StatusCancelled, cancellation.Actor, cancellation.CancelledAt)
+		if err := insertStatusChange(ctx, tx, change); err != nil {
+			return err
+		}
+
+		return insertOrderStatusEvent(ctx, tx, id, model.StatusCancelled, cancellation.CancelledAt)
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		r.log.Error(
+			layerPostgres,
+			method,
+			err.Error(), err,
+			"order_id", id,
+		)
+
+		var transitionErr *model.TransitionError
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return errs.ErrOrderNotFound
+		case errors.As(err, &transitionErr):
+			return errs.ErrOrderCannotBeCancelled
+		default:
+			return errs.ErrFailedToCancelOrder
+		}
+	}
+
+	metrics.OrderStatusTransitions.WithLabelValues(string(from), string(model.StatusCancelled), string(cancellation.Actor.Type)).Inc()
+
+	span.SetStatus(codes.Ok, "order success cancelled")
+
+	return nil
+}
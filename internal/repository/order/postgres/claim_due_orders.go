@@ -0,0 +1,57 @@
+package order
+
+import (
+	"context"
+	"time"
+
+	"OrderService/internal/metrics"
+	"OrderService/internal/model"
+
+	errorz "github.com/erdedan1/shared/errs"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// ClaimDueOrders leases up to limit orders whose next automatic transition is due.
+// The lease pushes next_transition_at forward, so other workers skip the orders
+// and an order is picked up again if its worker dies before moving it.
+func (r *Repository) ClaimDueOrders(ctx context.Context, limit int, lease time.Duration) ([]model.Order, *errorz.CustomError) {
+	const method = "ClaimDueOrders"
+	defer metrics.ObservePostgres(method, time.Now())
+
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.ClaimDueOrders")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int("limit", limit),
+	)
+
+	query := `
+		UPDATE orders
+		SET next_transition_at = NOW() + $2 * INTERVAL '1 millisecond'
+		WHERE id IN (
+			SELECT id
+			FROM orders
+			WHERE next_transition_at <= NOW() AND deleted_at IS NULL
+			ORDER BY next_transition_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + orderColumns
+
+	var orders []model.Order
+	if err := r.db.SelectContext(ctx, &orders, query, limit, lease.Milliseconds()); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		r.log.Error(layerPostgres, method, err.Error(), err)
+		return nil, errorz.New(errorz.INTERNAL, "failed to claim due orders")
+	}
+
+	span.SetAttributes(
+		attribute.Int("orders.claimed", len(orders)),
+	)
+	span.SetStatus(codes.Ok, "due orders claimed")
+
+	return orders, nil
+}
@@ -2,27 +2,78 @@ package order
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"time"
 
+	errs "OrderService/internal/errors"
+	"OrderService/internal/metrics"
 	"OrderService/internal/model"
 
 	errorz "github.com/erdedan1/shared/errs"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"go.opentelemetry.io/otel/codes"
 )
 
-func (r *Repository) CreateOrder(ctx context.Context, order *model.Order) (*model.Order, *errorz.CustomError) {
+// CreateOrder inserts the order. With a non-nil idempotency key a repeated request
+// returns the order created by the first one instead of inserting a new order, or
+// ErrIdempotencyKeyConflict if the key was used for a different payload.
+func (r *Repository) CreateOrder(ctx context.Context, order *model.Order, key *model.IdempotencyKey) (*model.Order, *errorz.CustomError) {
 	const method = "CreateOrder"
+	defer metrics.ObservePostgres(method, time.Now())
 
 	ctx, span := r.tracer.Start(ctx, "OrderRepository.CreateOrder")
 	defer span.End()
 
 	query := `
-		INSERT INTO orders (user_id, market_id, quantity, order_type, order_status, price)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO orders (user_id, market_id, quantity, order_type, order_status, price, next_transition_at, client_order_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING id, created_at
 	`
-	row := r.db.QueryRowxContext(ctx, query, order.UserUUID, order.MarketUUID, order.Quantity, order.Type, order.Status, order.Price)
 
-	err := row.Scan(&order.ID, &order.CreatedAt)
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		row := tx.QueryRowxContext(
+			ctx, query,
+			order.UserUUID,
+			order.MarketUUID,
+			order.Quantity,
+			order.Type,
+			order.Status,
+			order.Price,
+			order.NextTransitionAt,
+			order.ClientOrderID,
+		)
+		if err := row.Scan(&order.ID, &order.CreatedAt); err != nil {
+			return err
+		}
+
+		if key != nil {
+			if err := claimIdempotencyKey(ctx, tx, key, order); err != nil {
+				return err
+			}
+		}
+
+		change := model.NewOrderStatusChange(order.ID, nil, order.Status, model.UserActor(order.UserUUID), *order.CreatedAt)
+		if err := insertStatusChange(ctx, tx, change); err != nil {
+			return err
+		}
+
+		return insertOrderStatusEvent(ctx, tx, order.ID, order.Status, *order.CreatedAt)
+	})
+	if errors.Is(err, errIdempotencyKeyTaken) {
+		return r.replayIdempotentCreate(ctx, key)
+	}
+	if isUniqueViolation(err, clientOrderIDIndex) {
+		// a retry that reuses the client order id is replayed like any other idempotent retry
+		if key != nil {
+			return r.replayIdempotentCreate(ctx, key)
+		}
+
+		span.RecordError(err)
+		span.SetStatus(codes.Error, errs.ErrClientOrderIDAlreadyExists.Message)
+		return nil, errs.ErrClientOrderIDAlreadyExists
+	}
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -40,3 +91,47 @@ func (r *Repository) CreateOrder(ctx context.Context, order *model.Order) (*mode
 
 	return order, nil
 }
+
+func (r *Repository) replayIdempotentCreate(ctx context.Context, key *model.IdempotencyKey) (*model.Order, *errorz.CustomError) {
+	const method = "replayIdempotentCreate"
+
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.replayIdempotentCreate")
+	defer span.End()
+
+	stored, err := r.getIdempotencyKey(ctx, key)
+	if errors.Is(err, sql.ErrNoRows) {
+		// the client order id is taken by an order created without this key
+		span.SetStatus(codes.Error, errs.ErrClientOrderIDAlreadyExists.Message)
+		return nil, errs.ErrClientOrderIDAlreadyExists
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		r.log.Error(layerPostgres, method, err.Error(), err, "user_id", key.UserID, "idempotency_key", key.Key)
+		return nil, errorz.New(errorz.INTERNAL, "failed to create order")
+	}
+
+	if stored.RequestHash != key.RequestHash {
+		span.SetStatus(codes.Error, errs.ErrIdempotencyKeyConflict.Message)
+
+		r.log.Error(
+			layerPostgres, method,
+			errs.ErrIdempotencyKeyConflict.Message, errs.ErrIdempotencyKeyConflict,
+			"user_id", key.UserID,
+			"idempotency_key", key.Key,
+		)
+		return nil, errs.ErrIdempotencyKeyConflict
+	}
+
+	span.SetStatus(codes.Ok, "order create replayed")
+
+	return stored.Order(), nil
+}
+
+const clientOrderIDIndex = "orders_user_client_order_id_idx"
+
+func isUniqueViolation(err error, constraint string) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == constraint
+}
@@ -0,0 +1,250 @@
+package order_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	errs "OrderService/internal/errors"
+	"OrderService/internal/model"
+	postgres "OrderService/internal/repository/order/postgres"
+
+	log "github.com/erdedan1/shared/logger"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// fakeDB answers the statements of CreateOrder from memory: the orders it inserted and
+// the idempotency_keys table, which keeps a key for the first request that claimed it.
+type fakeDB struct {
+	mu        sync.Mutex
+	orders    int
+	keys      map[string][]driver.Value
+	commits   int
+	rollbacks int
+}
+
+func newFakeDB() *fakeDB {
+	return &fakeDB{keys: map[string][]driver.Value{}}
+}
+
+var idempotencyKeyColumns = []string{"user_id", "idempotency_key", "request_hash", "order_id", "order_status", "order_created_at", "created_at", "expires_at"}
+
+func (db *fakeDB) query(query string, args []driver.NamedValue) (driver.Rows, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	switch {
+	case strings.Contains(query, "INSERT INTO orders"):
+		db.orders++
+		return &fakeRows{
+			columns: []string{"id", "created_at"},
+			values:  [][]driver.Value{{uuid.NewString(), time.Now()}},
+		}, nil
+
+	case strings.Contains(query, "FROM idempotency_keys"):
+		row, ok := db.keys[keyID(args)]
+		if !ok {
+			return &fakeRows{columns: idempotencyKeyColumns}, nil
+		}
+		return &fakeRows{columns: idempotencyKeyColumns, values: [][]driver.Value{row}}, nil
+	}
+
+	return nil, errors.New("unexpected query: " + query)
+}
+
+func (db *fakeDB) exec(query string, args []driver.NamedValue) (driver.Result, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	switch {
+	case strings.Contains(query, "INSERT INTO idempotency_keys"):
+		if _, ok := db.keys[keyID(args)]; ok {
+			return driver.RowsAffected(0), nil
+		}
+		// user_id, idempotency_key, request_hash, order_id, order_status, order_created_at, expires_at
+		db.keys[keyID(args)] = []driver.Value{
+			args[0].Value, args[1].Value, args[2].Value, args[3].Value, args[4].Value, args[5].Value, time.Now(), args[6].Value,
+		}
+		return driver.RowsAffected(1), nil
+
+	case strings.Contains(query, "DELETE FROM idempotency_keys"),
+		strings.Contains(query, "INSERT INTO order_status_history"),
+		strings.Contains(query, "INSERT INTO outbox"):
+		return driver.RowsAffected(1), nil
+	}
+
+	return nil, errors.New("unexpected statement: " + query)
+}
+
+func keyID(args []driver.NamedValue) string {
+	return args[0].Value.(string) + "/" + args[1].Value.(string)
+}
+
+func (db *fakeDB) Connect(context.Context) (driver.Conn, error) { return &fakeConn{db: db}, nil }
+func (db *fakeDB) Driver() driver.Driver                        { return nil }
+
+type fakeConn struct {
+	db *fakeDB
+}
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *fakeConn) Close() error                        { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)           { return &fakeTx{db: c.db}, nil }
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return c.db.query(query, args)
+}
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return c.db.exec(query, args)
+}
+
+type fakeTx struct {
+	db *fakeDB
+}
+
+func (tx *fakeTx) Commit() error {
+	tx.db.mu.Lock()
+	defer tx.db.mu.Unlock()
+
+	tx.db.commits++
+	return nil
+}
+
+func (tx *fakeTx) Rollback() error {
+	tx.db.mu.Lock()
+	defer tx.db.mu.Unlock()
+
+	tx.db.rollbacks++
+	return nil
+}
+
+type fakeRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+func newTestRepository(db *fakeDB) *postgres.Repository {
+	logger, _ := log.NewLogger("debug")
+
+	return postgres.New(sqlx.NewDb(sql.OpenDB(db), "postgres"), logger, noop.NewTracerProvider())
+}
+
+func newOrder(userID uuid.UUID) *model.Order {
+	return &model.Order{
+		UserUUID:   userID,
+		MarketUUID: uuid.New(),
+		Quantity:   1,
+		Type:       "limit",
+		Status:     model.StatusCreated,
+		Price:      decimal.NewFromInt(120),
+	}
+}
+
+func newKey(userID uuid.UUID, hash string) *model.IdempotencyKey {
+	return &model.IdempotencyKey{
+		UserID:      userID,
+		Key:         "retry-1",
+		RequestHash: hash,
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+}
+
+func TestCreateOrder_ReplaysIdempotentRetry(t *testing.T) {
+	db := newFakeDB()
+	repo := newTestRepository(db)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	created, err := repo.CreateOrder(ctx, newOrder(userID), newKey(userID, "hash-1"))
+	assert.Nil(t, err)
+
+	replayed, err := repo.CreateOrder(ctx, newOrder(userID), newKey(userID, "hash-1"))
+	assert.Nil(t, err)
+
+	assert.Equal(t, created.ID, replayed.ID)
+	assert.Equal(t, created.Status, replayed.Status)
+	assert.True(t, created.CreatedAt.Equal(*replayed.CreatedAt))
+	// the retry inserted an order too, its transaction is rolled back
+	assert.Equal(t, 1, db.commits)
+	assert.Equal(t, 1, db.rollbacks)
+}
+
+func TestCreateOrder_IdempotencyKeyConflict(t *testing.T) {
+	db := newFakeDB()
+	repo := newTestRepository(db)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	_, err := repo.CreateOrder(ctx, newOrder(userID), newKey(userID, "hash-1"))
+	assert.Nil(t, err)
+
+	order, err := repo.CreateOrder(ctx, newOrder(userID), newKey(userID, "hash-2"))
+
+	assert.Nil(t, order)
+	assert.Equal(t, errs.ErrIdempotencyKeyConflict, err)
+	assert.Equal(t, 1, db.commits)
+	assert.Equal(t, 1, db.rollbacks)
+}
+
+func TestCreateOrder_IdempotencyKeyOfOtherUser(t *testing.T) {
+	db := newFakeDB()
+	repo := newTestRepository(db)
+	ctx := context.Background()
+
+	first, err := repo.CreateOrder(ctx, newOrder(uuid.New()), newKey(uuid.New(), "hash-1"))
+	assert.Nil(t, err)
+
+	otherUser := uuid.New()
+	second, err := repo.CreateOrder(ctx, newOrder(otherUser), newKey(otherUser, "hash-1"))
+	assert.Nil(t, err)
+
+	// keys are scoped to the user, the same key creates a second order
+	assert.NotEqual(t, first.ID, second.ID)
+	assert.Equal(t, 2, db.commits)
+}
+
+func TestGetIdempotentOrder(t *testing.T) {
+	db := newFakeDB()
+	repo := newTestRepository(db)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	_, err := repo.GetIdempotentOrder(ctx, newKey(userID, "hash-1"))
+	assert.Equal(t, errs.ErrOrderNotFound, err)
+
+	created, err := repo.CreateOrder(ctx, newOrder(userID), newKey(userID, "hash-1"))
+	assert.Nil(t, err)
+
+	found, err := repo.GetIdempotentOrder(ctx, newKey(userID, "hash-1"))
+	assert.Nil(t, err)
+	assert.Equal(t, created.ID, found.ID)
+	assert.Equal(t, created.Status, found.Status)
+
+	found, err = repo.GetIdempotentOrder(ctx, newKey(userID, "hash-2"))
+	assert.Nil(t, found)
+	assert.Equal(t, errs.ErrIdempotencyKeyConflict, err)
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
 
 	errs "OrderService/internal/errors"
+	"OrderService/internal/metrics"
 	"OrderService/internal/model"
 
 	errorz "github.com/erdedan1/shared/errs"
@@ -16,6 +18,7 @@ import (
 
 func (r *Repository) GetOrder(ctx context.Context, orderID, userID uuid.UUID) (*model.Order, *errorz.CustomError) {
 	const method = "GetOrder"
+	defer metrics.ObservePostgres(method, time.Now())
 
 	ctx, span := r.tracer.Start(ctx, "OrderRepository.GetOrder")
 	defer span.End()
@@ -25,7 +28,7 @@ func (r *Repository) GetOrder(ctx context.Context, orderID, userID uuid.UUID) (*
 		attribute.String("user.id", userID.String()),
 	)
 
-	query := `SELECT id, user_id, market_id, quantity, order_type, order_status, price, created_at, updated_at, deleted_at FROM orders WHERE id = $1 AND user_id = $2`
+	query := `SELECT ` + orderColumns + ` FROM orders WHERE id = $1 AND user_id = $2`
 
 	var notification model.Order
 
@@ -62,3 +65,82 @@ func (r *Repository) GetOrder(ctx context.Context, orderID, userID uuid.UUID) (*
 
 	return &notification, nil
 }
+
+func (r *Repository) GetOrderByClientOrderID(ctx context.Context, userID uuid.UUID, clientOrderID string) (*model.Order, *errorz.CustomError) {
+	const method = "GetOrderByClientOrderID"
+	defer metrics.ObservePostgres(method, time.Now())
+
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.GetOrderByClientOrderID")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("order.client_id", clientOrderID),
+		attribute.String("user.id", userID.String()),
+	)
+
+	query := `SELECT ` + orderColumns + ` FROM orders WHERE user_id = $1 AND client_order_id = $2`
+
+	var order model.Order
+
+	err := r.db.GetContext(ctx, &order, query, userID, clientOrderID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		r.log.Error(
+			layerPostgres,
+			method,
+			err.Error(), err,
+			"client_order_id", clientOrderID,
+			"user_id", userID,
+		)
+
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.ErrOrderNotFound
+		}
+		return nil, errorz.New(errorz.INTERNAL, "failed to get order")
+	}
+
+	span.SetStatus(codes.Ok, "get order success")
+
+	return &order, nil
+}
+
+// GetOrderByID loads an order regardless of its owner, callers are expected to have authorized the access.
+func (r *Repository) GetOrderByID(ctx context.Context, orderID uuid.UUID) (*model.Order, *errorz.CustomError) {
+	const method = "GetOrderByID"
+	defer metrics.ObservePostgres(method, time.Now())
+
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.GetOrderByID")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("order.id", orderID.String()),
+	)
+
+	query := `SELECT ` + orderColumns + ` FROM orders WHERE id = $1`
+
+	var order model.Order
+
+	err := r.db.GetContext(ctx, &order, query, orderID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		r.log.Error(
+			layerPostgres,
+			method,
+			err.Error(), err,
+			"order_id", orderID,
+		)
+
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.ErrOrderNotFound
+		}
+		return nil, errorz.New(errorz.INTERNAL, "failed to get order")
+	}
+
+	span.SetStatus(codes.Ok, "get order success")
+
+	return &order, nil
+}
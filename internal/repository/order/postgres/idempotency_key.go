@@ -0,0 +1,126 @@
+package order
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	errs "OrderService/internal/errors"
+	"OrderService/internal/metrics"
+	"OrderService/internal/model"
+
+	errorz "github.com/erdedan1/shared/errs"
+	"github.com/jmoiron/sqlx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// errIdempotencyKeyTaken rolls back the create transaction when another request already used the key.
+var errIdempotencyKeyTaken = errors.New("idempotency key already used")
+
+const idempotencyKeyColumns = `user_id, idempotency_key, request_hash, order_id, order_status, order_created_at, created_at, expires_at`
+
+// claimIdempotencyKey stores the key for the freshly inserted order. If the key is
+// already taken the insert waits for the other transaction and then does nothing.
+func claimIdempotencyKey(ctx context.Context, tx *sqlx.Tx, key *model.IdempotencyKey, order *model.Order) error {
+	deleteExpired := `DELETE FROM idempotency_keys WHERE user_id = $1 AND idempotency_key = $2 AND expires_at <= NOW()`
+	if _, err := tx.ExecContext(ctx, deleteExpired, key.UserID, key.Key); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO idempotency_keys (user_id, idempotency_key, request_hash, order_id, order_status, order_created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (user_id, idempotency_key) DO NOTHING
+	`
+
+	res, err := tx.ExecContext(ctx, query, key.UserID, key.Key, key.RequestHash, order.ID, order.Status, order.CreatedAt, key.ExpiresAt)
+	if err != nil {
+		return err
+	}
+
+	inserted, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if inserted == 0 {
+		return errIdempotencyKeyTaken
+	}
+
+	return nil
+}
+
+func (r *Repository) getIdempotencyKey(ctx context.Context, key *model.IdempotencyKey) (*model.IdempotencyKey, error) {
+	query := `SELECT ` + idempotencyKeyColumns + ` FROM idempotency_keys WHERE user_id = $1 AND idempotency_key = $2`
+
+	var stored model.IdempotencyKey
+	if err := r.db.GetContext(ctx, &stored, query, key.UserID, key.Key); err != nil {
+		return nil, err
+	}
+
+	return &stored, nil
+}
+
+// GetIdempotentOrder returns the order created by the first request of an unexpired key,
+// ErrIdempotencyKeyConflict if the key was used for a different payload and
+// ErrOrderNotFound if the key was not used yet.
+func (r *Repository) GetIdempotentOrder(ctx context.Context, key *model.IdempotencyKey) (*model.Order, *errorz.CustomError) {
+	const method = "GetIdempotentOrder"
+	defer metrics.ObservePostgres(method, time.Now())
+
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.GetIdempotentOrder")
+	defer span.End()
+
+	query := `SELECT ` + idempotencyKeyColumns + ` FROM idempotency_keys WHERE user_id = $1 AND idempotency_key = $2 AND expires_at > NOW()`
+
+	var stored model.IdempotencyKey
+	err := r.db.GetContext(ctx, &stored, query, key.UserID, key.Key)
+	if errors.Is(err, sql.ErrNoRows) {
+		span.SetStatus(codes.Ok, "idempotency key not used yet")
+		return nil, errs.ErrOrderNotFound
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		r.log.Error(layerPostgres, method, err.Error(), err, "user_id", key.UserID, "idempotency_key", key.Key)
+		return nil, errorz.New(errorz.INTERNAL, "failed to get idempotency key")
+	}
+
+	if stored.RequestHash != key.RequestHash {
+		span.SetStatus(codes.Error, errs.ErrIdempotencyKeyConflict.Message)
+		return nil, errs.ErrIdempotencyKeyConflict
+	}
+
+	span.SetStatus(codes.Ok, "idempotent order found")
+
+	return stored.Order(), nil
+}
+
+// DeleteExpiredIdempotencyKeys removes keys whose retention window ended before the given time.
+func (r *Repository) DeleteExpiredIdempotencyKeys(ctx context.Context, before time.Time) (int64, *errorz.CustomError) {
+	const method = "DeleteExpiredIdempotencyKeys"
+	defer metrics.ObservePostgres(method, time.Now())
+
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.DeleteExpiredIdempotencyKeys")
+	defer span.End()
+
+	res, err := r.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= $1`, before)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		r.log.Error(layerPostgres, method, err.Error(), err)
+		return 0, errorz.New(errorz.INTERNAL, "failed to delete expired idempotency keys")
+	}
+
+	deleted, _ := res.RowsAffected()
+
+	span.SetAttributes(
+		attribute.Int64("idempotency_keys.deleted", deleted),
+	)
+	span.SetStatus(codes.Ok, "expired idempotency keys deleted")
+
+	return deleted, nil
+}
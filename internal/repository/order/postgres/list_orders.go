@@ -0,0 +1,93 @@
+package order
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"OrderService/internal/metrics"
+	"OrderService/internal/model"
+
+	errorz "github.com/erdedan1/shared/errs"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+func (r *Repository) ListOrders(ctx context.Context, filter model.OrderFilter) ([]model.Order, *errorz.CustomError) {
+	const method = "ListOrders"
+	defer metrics.ObservePostgres(method, time.Now())
+
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.ListOrders")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("user.id", filter.UserUUID.String()),
+		attribute.String("market.id", filter.MarketUUID.String()),
+		attribute.Int("limit", filter.Limit),
+	)
+
+	query, args := buildListOrdersQuery(filter)
+
+	orders := make([]model.Order, 0, filter.Limit)
+	if err := r.db.SelectContext(ctx, &orders, query, args...); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		r.log.Error(
+			layerPostgres,
+			method,
+			err.Error(), err,
+			"user_id", filter.UserUUID,
+		)
+		return nil, errorz.New(errorz.INTERNAL, "failed to list orders")
+	}
+
+	span.SetStatus(codes.Ok, "list orders success")
+
+	return orders, nil
+}
+
+func buildListOrdersQuery(filter model.OrderFilter) (string, []any) {
+	var (
+		conditions = []string{"deleted_at IS NULL"}
+		args       []any
+	)
+	arg := func(value any) string {
+		args = append(args, value)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	if filter.UserUUID != uuid.Nil {
+		conditions = append(conditions, "user_id = "+arg(filter.UserUUID))
+	}
+	if filter.MarketUUID != uuid.Nil {
+		conditions = append(conditions, "market_id = "+arg(filter.MarketUUID))
+	}
+	if filter.Type != "" {
+		conditions = append(conditions, "order_type = "+arg(filter.Type))
+	}
+	if len(filter.Statuses) != 0 {
+		statuses := make([]string, 0, len(filter.Statuses))
+		for _, status := range filter.Statuses {
+			statuses = append(statuses, string(status))
+		}
+		conditions = append(conditions, "order_status = ANY("+arg(pq.Array(statuses))+")")
+	}
+	if filter.CreatedFrom != nil {
+		conditions = append(conditions, "created_at >= "+arg(*filter.CreatedFrom))
+	}
+	if filter.CreatedTo != nil {
+		conditions = append(conditions, "created_at < "+arg(*filter.CreatedTo))
+	}
+	if filter.After != nil {
+		conditions = append(conditions, "(created_at, id) < ("+arg(filter.After.CreatedAt)+", "+arg(filter.After.ID)+")")
+	}
+
+	query := `SELECT ` + orderColumns + ` FROM orders WHERE ` + strings.Join(conditions, " AND ") +
+		` ORDER BY created_at DESC, id DESC LIMIT ` + arg(filter.Limit)
+
+	return query, args
+}
@@ -0,0 +1,62 @@
+package order
+
+import (
+	"context"
+	"time"
+
+	"OrderService/internal/metrics"
+	"OrderService/internal/model"
+
+	errorz "github.com/erdedan1/shared/errs"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+func (r *Repository) GetOrderHistory(ctx context.Context, orderID uuid.UUID) ([]model.OrderStatusChange, *errorz.CustomError) {
+	const method = "GetOrderHistory"
+	defer metrics.ObservePostgres(method, time.Now())
+
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.GetOrderHistory")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("order.id", orderID.String()),
+	)
+
+	query := `
+		SELECT id, order_id, from_status, to_status, actor_type, actor_id, changed_at
+		FROM order_status_history
+		WHERE order_id = $1
+		ORDER BY changed_at, id
+	`
+
+	var history []model.OrderStatusChange
+	if err := r.db.SelectContext(ctx, &history, query, orderID); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		r.log.Error(
+			layerPostgres,
+			method,
+			err.Error(), err,
+			"order_id", orderID,
+		)
+		return nil, errorz.New(errorz.INTERNAL, "failed to get order history")
+	}
+
+	span.SetStatus(codes.Ok, "get order history success")
+
+	return history, nil
+}
+
+func insertStatusChange(ctx context.Context, tx *sqlx.Tx, change model.OrderStatusChange) error {
+	query := `
+		INSERT INTO order_status_history (order_id, from_status, to_status, actor_type, actor_id, changed_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := tx.ExecContext(ctx, query, change.OrderID, change.FromStatus, change.ToStatus, change.ActorType, change.ActorID, change.ChangedAt)
+	return err
+}
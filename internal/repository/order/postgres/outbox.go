@@ -0,0 +1,26 @@
+package order
+
+import (
+	"context"
+	"time"
+
+	"OrderService/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+func insertOrderStatusEvent(ctx context.Context, tx *sqlx.Tx, orderID uuid.UUID, status model.OrderStatus, occurredAt time.Time) error {
+	event, err := model.NewOrderStatusOutboxEvent(orderID, status, occurredAt)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO outbox (aggregate_id, event_type, payload)
+		VALUES ($1, $2, $3)
+	`
+
+	_, err = tx.ExecContext(ctx, query, event.AggregateID, event.EventType, event.Payload)
+	return err
+}
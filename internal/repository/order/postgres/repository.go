@@ -3,11 +3,10 @@ package order
 import (
 	"context"
 
-	"OrderService/config"
-	"OrderService/internal/connection"
+	"OrderService/internal/model"
 
-	errorz "github.com/erdedan1/shared/errs"
 	log "github.com/erdedan1/shared/logger"
+	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 	"go.opentelemetry.io/otel/trace"
 )
@@ -18,17 +17,39 @@ type Repository struct {
 	tracer trace.Tracer
 }
 
-func New(ctx context.Context, log log.Logger, config config.PostgresDB, tp trace.TracerProvider) (*Repository, *errorz.CustomError) {
-	db, err := connection.New(ctx, config)
-	if err != nil {
-		return nil, err
-	}
-
+func New(db *sqlx.DB, log log.Logger, tp trace.TracerProvider) *Repository {
 	return &Repository{
 		db:     db,
 		log:    log,
 		tracer: tp.Tracer("order-service/Repository"),
-	}, nil
+	}
 }
 
 const layerPostgres = "PostgresOrderRepo"
+
+const orderColumns = `id, user_id, market_id, quantity, order_type, order_status, price, created_at, updated_at, deleted_at, cancel_reason, cancelled_by, cancelled_at, next_transition_at, client_order_id`
+
+func (r *Repository) withTx(ctx context.Context, fn func(tx *sqlx.Tx) error) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func getOrderForUpdate(ctx context.Context, tx *sqlx.Tx, id uuid.UUID) (*model.Order, error) {
+	query := `SELECT ` + orderColumns + ` FROM orders WHERE id = $1 FOR UPDATE`
+
+	var order model.Order
+	if err := tx.GetContext(ctx, &order, query, id); err != nil {
+		return nil, err
+	}
+
+	return &order, nil
+}
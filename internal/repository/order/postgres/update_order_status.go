@@ -2,48 +2,63 @@ package order
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"time"
 
 	errs "OrderService/internal/errors"
+	"OrderService/internal/metrics"
 	"OrderService/internal/model"
 
 	errorz "github.com/erdedan1/shared/errs"
 	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 )
 
-func (r *Repository) UpdateOrderStatus(ctx context.Context, id uuid.UUID, status model.OrderStatus) *errorz.CustomError {
+func (r *Repository) UpdateOrderStatus(ctx context.Context, id uuid.UUID, update model.OrderStatusUpdate) *errorz.CustomError {
 	const method = "UpdateOrder"
+	defer metrics.ObservePostgres(method, time.Now())
 
 	ctx, span := r.tracer.Start(ctx, "OrderRepository.UpdateOrder")
 	defer span.End()
 
 	span.SetAttributes(
 		attribute.String("order.id", id.String()),
+		attribute.String("order.status", string(update.Status)),
+		attribute.String("actor.type", string(update.Actor.Type)),
 	)
 
 	query := `
 			UPDATE orders
-			SET order_status = $1, updated_at = $2
-			WHERE id = $3
+			SET order_status = $1, updated_at = $2, next_transition_at = $3
+			WHERE id = $4
 		`
 
-	res, err := r.db.ExecContext(ctx, query, status, time.Now(), id)
-	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, err.Error())
+	var from model.OrderStatus
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		order, err := getOrderForUpdate(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		from = order.Status
 
-		r.log.Error(
-			layerPostgres,
-			method,
-			err.Error(), err,
-			"order_id", id,
-		)
-		return errs.ErrFailedToUpdateOrderStatus
-	}
+		now := time.Now()
+		if err := model.OrderWorkflow.CanTransition(order, update.Status, now); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, query, update.Status, now, update.NextTransitionAt, id); err != nil {
+			return err
+		}
+
+		if err := insertStatusChange(ctx, tx, model.NewOrderStatusChange(id, &order.Status, update.Status, update.Actor, now)); err != nil {
+			return err
+		}
 
-	rowsAffected, err := res.RowsAffected()
+		return insertOrderStatusEvent(ctx, tx, id, update.Status, now)
+	})
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -53,22 +68,22 @@ func (r *Repository) UpdateOrderStatus(ctx context.Context, id uuid.UUID, status
 			method,
 			err.Error(), err,
 			"order_id", id,
+			"status", update.Status,
 		)
-		return errs.ErrFailedToUpdateOrderStatus
-	}
-	if rowsAffected == 0 {
-		span.RecordError(errs.ErrOrderNotFound)
-		span.SetStatus(codes.Error, errs.ErrOrderNotFound.Message)
 
-		r.log.Error(
-			layerPostgres,
-			method,
-			errs.ErrOrderNotFound.Message,
-			errs.ErrOrderNotFound,
-		)
-		return errs.ErrOrderNotFound
+		var transitionErr *model.TransitionError
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return errs.ErrOrderNotFound
+		case errors.As(err, &transitionErr):
+			return errs.IllegalStatusTransition(transitionErr)
+		default:
+			return errs.ErrFailedToUpdateOrderStatus
+		}
 	}
 
+	metrics.OrderStatusTransitions.WithLabelValues(string(from), string(update.Status), string(update.Actor.Type)).Inc()
+
 	span.SetStatus(codes.Ok, "order success updated")
 
 	return nil
@@ -2,6 +2,7 @@ package order_status
 
 import (
 	"context"
+	"time"
 
 	errs "OrderService/internal/errors"
 	"OrderService/internal/model"
@@ -31,7 +32,9 @@ func NewRedisPublisher(client cache.RedisClient, logger log.Logger, tp trace.Tra
 
 const publisherLayer = "RedisOrderStatusPublisher"
 
-func (p *RedisPublisher) PublishOrderStatus(ctx context.Context, orderID uuid.UUID, status model.OrderStatus) *errors.CustomError {
+// PublishOrderStatus sends the status only, pub/sub subscribers cannot resume so the time
+// the change occurred at is not needed.
+func (p *RedisPublisher) PublishOrderStatus(ctx context.Context, orderID uuid.UUID, status model.OrderStatus, _ time.Time) *errors.CustomError {
 	const method = "PublishOrderStatus"
 
 	ctx, span := p.tracer.Start(ctx, "OrderStatusPublisher.PublishOrderStatus")
@@ -0,0 +1,87 @@
+package order_status
+
+import (
+	"context"
+	"time"
+
+	"OrderService/config"
+	errs "OrderService/internal/errors"
+	"OrderService/internal/model"
+	"OrderService/pkg/cache"
+
+	errors "github.com/erdedan1/shared/errs"
+	log "github.com/erdedan1/shared/logger"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RedisStreamPublisher appends order status changes to a per-order Redis stream,
+// so subscribers can replay what they missed while disconnected.
+type RedisStreamPublisher struct {
+	client cache.RedisClient
+	log    log.Logger
+	tracer trace.Tracer
+	cfg    config.OrderStatusStreamConfig
+}
+
+func NewRedisStreamPublisher(client cache.RedisClient, logger log.Logger, tp trace.TracerProvider, cfg config.OrderStatusStreamConfig) *RedisStreamPublisher {
+	return &RedisStreamPublisher{
+		client: client,
+		log:    logger,
+		tracer: tp.Tracer("order-service/RedisOrderStatusStreamPublisher"),
+		cfg:    cfg,
+	}
+}
+
+const streamPublisherLayer = "RedisOrderStatusStreamPublisher"
+
+// PublishOrderStatus appends the change with the time it was committed at, not the time it
+// is published at, so resuming by time agrees with the updated_at clients have seen.
+func (p *RedisStreamPublisher) PublishOrderStatus(
+	ctx context.Context,
+	orderID uuid.UUID,
+	status model.OrderStatus,
+	occurredAt time.Time,
+) *errors.CustomError {
+	const method = "PublishOrderStatus"
+
+	ctx, span := p.tracer.Start(ctx, "OrderStatusStreamPublisher.PublishOrderStatus")
+	defer span.End()
+
+	streamName := orderStatusStream(orderID)
+
+	span.SetAttributes(
+		attribute.String("order.id", orderID.String()),
+		attribute.String("stream", streamName),
+		attribute.String("status", string(status)),
+	)
+
+	id, err := p.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: streamName,
+		MaxLen: p.cfg.MaxLen,
+		Approx: true,
+		Values: map[string]interface{}{
+			streamFieldStatus:     string(status),
+			streamFieldOccurredAt: occurredAt.UTC().Format(time.RFC3339Nano),
+		},
+	}).Result()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, errs.ErrUnavailableRedis.Message)
+
+		p.log.Error(streamPublisherLayer, method, err.Error(), err, "order_id", orderID, "status", status)
+		return errs.ErrUnavailableRedis
+	}
+
+	// every publish pushes the expiry forward, so streams of finished orders go away on their own
+	if err := p.client.Expire(ctx, streamName, p.cfg.TTL).Err(); err != nil {
+		p.log.Error(streamPublisherLayer, method, "failed to set order status stream ttl", err, "order_id", orderID)
+	}
+
+	span.SetAttributes(attribute.String("event.id", id))
+	span.SetStatus(codes.Ok, "order status appended to stream")
+	return nil
+}
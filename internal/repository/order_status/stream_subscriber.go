@@ -0,0 +1,197 @@
+package order_status
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"OrderService/config"
+	errs "OrderService/internal/errors"
+	"OrderService/internal/model"
+	"OrderService/pkg/cache"
+
+	errorz "github.com/erdedan1/shared/errs"
+	log "github.com/erdedan1/shared/logger"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	streamFieldStatus     = "status"
+	streamFieldOccurredAt = "occurred_at"
+
+	streamReadCount = 100
+)
+
+// RedisStreamSubscriber reads order status changes from the per-order Redis stream.
+// Every event carries its stream ID, which can be passed back to resume after it. The
+// time an event occurred at resumes after it as well, for clients that only see that.
+type RedisStreamSubscriber struct {
+	client cache.RedisClient
+	log    log.Logger
+	tracer trace.Tracer
+	cfg    config.OrderStatusStreamConfig
+}
+
+func NewRedisStreamSubscriber(client cache.RedisClient, logger log.Logger, tp trace.TracerProvider, cfg config.OrderStatusStreamConfig) *RedisStreamSubscriber {
+	return &RedisStreamSubscriber{
+		client: client,
+		log:    logger,
+		tracer: tp.Tracer("order-service/RedisOrderStatusStreamSubscriber"),
+		cfg:    cfg,
+	}
+}
+
+const streamSubscriberLayer = "RedisOrderStatusStreamSubscriber"
+
+func (s *RedisStreamSubscriber) SubscribeOrderStatus(ctx context.Context, orderID uuid.UUID, afterID string) (<-chan model.OrderStatusEvent, *errorz.CustomError) {
+	const method = "SubscribeOrderStatus"
+
+	ctx, span := s.tracer.Start(ctx, "OrderStatusStreamSubscriber.SubscribeOrderStatus")
+	defer span.End()
+
+	streamName := orderStatusStream(orderID)
+	span.SetAttributes(
+		attribute.String("order.id", orderID.String()),
+		attribute.String("stream", streamName),
+		attribute.String("resume.after", afterID),
+	)
+
+	lastID := afterID
+	// events that occurred by then are skipped when resuming from a time
+	var occurredAfter time.Time
+	switch {
+	case lastID == "":
+		// a fresh subscription starts after the newest entry that is already in the stream
+		latestID, err := s.latestID(ctx, streamName)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, errs.ErrUnavailableRedis.Message)
+
+			s.log.Error(streamSubscriberLayer, method, err.Error(), err, "order_id", orderID)
+			return nil, errs.ErrUnavailableRedis
+		}
+		lastID = latestID
+	case isStreamID(lastID):
+	default:
+		resumeAt, err := time.Parse(time.RFC3339Nano, afterID)
+		if err != nil {
+			span.SetStatus(codes.Error, errs.ErrInvalidResumeToken.Message)
+			return nil, errs.ErrInvalidResumeToken
+		}
+
+		// the stream IDs come from the Redis clock, so the whole stream is read, it is
+		// capped and only holds the few changes of one order
+		lastID = "0-0"
+		occurredAfter = resumeAt
+	}
+
+	out := make(chan model.OrderStatusEvent)
+
+	go func() {
+		defer close(out)
+
+		for {
+			streams, err := s.client.XRead(ctx, &redis.XReadArgs{
+				Streams: []string{streamName, lastID},
+				Count:   streamReadCount,
+				Block:   s.cfg.BlockTimeout,
+			}).Result()
+			if ctx.Err() != nil {
+				return
+			}
+			if errors.Is(err, redis.Nil) {
+				continue
+			}
+			if err != nil {
+				s.log.Error(streamSubscriberLayer, method, err.Error(), err, "order_id", orderID, "last_id", lastID)
+
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(s.cfg.BlockTimeout):
+				}
+				continue
+			}
+
+			for _, stream := range streams {
+				for _, message := range stream.Messages {
+					lastID = message.ID
+
+					event, ok := orderStatusEventFromMessage(orderID, message)
+					if !ok {
+						s.log.Error(streamSubscriberLayer, method, "invalid order status stream entry", errs.ErrInvalidArgument, "order_id", orderID, "event_id", message.ID)
+						continue
+					}
+					if !event.OccurredAt.After(occurredAfter) {
+						continue
+					}
+
+					select {
+					case <-ctx.Done():
+						return
+					case out <- event:
+					}
+				}
+			}
+		}
+	}()
+
+	span.SetStatus(codes.Ok, "order status stream subscription started")
+	return out, nil
+}
+
+func (s *RedisStreamSubscriber) latestID(ctx context.Context, streamName string) (string, error) {
+	messages, err := s.client.XRevRangeN(ctx, streamName, "+", "-", 1).Result()
+	if err != nil {
+		return "", err
+	}
+	if len(messages) == 0 {
+		return "0-0", nil
+	}
+
+	return messages[0].ID, nil
+}
+
+func orderStatusEventFromMessage(orderID uuid.UUID, message redis.XMessage) (model.OrderStatusEvent, bool) {
+	rawStatus, _ := message.Values[streamFieldStatus].(string)
+	status := model.OrderStatus(rawStatus)
+	if !status.IsValid() {
+		return model.OrderStatusEvent{}, false
+	}
+
+	rawOccurredAt, _ := message.Values[streamFieldOccurredAt].(string)
+	occurredAt, err := time.Parse(time.RFC3339Nano, rawOccurredAt)
+	if err != nil {
+		return model.OrderStatusEvent{}, false
+	}
+
+	return model.OrderStatusEvent{
+		ID:         message.ID,
+		OrderID:    orderID,
+		Status:     status,
+		OccurredAt: occurredAt,
+	}, true
+}
+
+// isStreamID reports whether id looks like a Redis stream entry ID ("<ms>-<seq>").
+func isStreamID(id string) bool {
+	ms, seq, ok := strings.Cut(id, "-")
+	if !ok {
+		return false
+	}
+	if _, err := strconv.ParseUint(ms, 10, 64); err != nil {
+		return false
+	}
+	_, err := strconv.ParseUint(seq, 10, 64)
+	return err == nil
+}
+
+func orderStatusStream(orderID uuid.UUID) string {
+	return "order:status:stream:" + orderID.String()
+}
@@ -0,0 +1,229 @@
+package order_status_test
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"OrderService/config"
+	errs "OrderService/internal/errors"
+	"OrderService/internal/model"
+	"OrderService/internal/repository/order_status"
+	"OrderService/pkg/cache"
+
+	log "github.com/erdedan1/shared/logger"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// fakeStream keeps the entries of one stream in memory, XREAD returns those after the
+// requested ID and otherwise waits for the block timeout like Redis does.
+type fakeStream struct {
+	cache.RedisClient
+
+	mu      sync.Mutex
+	entries []redis.XMessage
+	added   chan struct{}
+}
+
+func newFakeStream() *fakeStream {
+	return &fakeStream{added: make(chan struct{}, 16)}
+}
+
+func (s *fakeStream) add(status model.OrderStatus, occurredAt time.Time) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := strconv.Itoa(len(s.entries)+1) + "-0"
+	s.entries = append(s.entries, redis.XMessage{
+		ID: id,
+		Values: map[string]interface{}{
+			"status":      string(status),
+			"occurred_at": occurredAt.UTC().Format(time.RFC3339Nano),
+		},
+	})
+	s.added <- struct{}{}
+
+	return id
+}
+
+func (s *fakeStream) XAdd(_ context.Context, a *redis.XAddArgs) *redis.StringCmd {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := strconv.Itoa(len(s.entries)+1) + "-0"
+	s.entries = append(s.entries, redis.XMessage{ID: id, Values: a.Values.(map[string]interface{})})
+	s.added <- struct{}{}
+
+	return redis.NewStringResult(id, nil)
+}
+
+func (s *fakeStream) Expire(context.Context, string, time.Duration) *redis.BoolCmd {
+	return redis.NewBoolResult(true, nil)
+}
+
+func (s *fakeStream) after(id string) []redis.XMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var messages []redis.XMessage
+	for _, entry := range s.entries {
+		if streamSeq(entry.ID) > streamSeq(id) {
+			messages = append(messages, entry)
+		}
+	}
+	return messages
+}
+
+func (s *fakeStream) XRead(ctx context.Context, a *redis.XReadArgs) *redis.XStreamSliceCmd {
+	for {
+		if messages := s.after(a.Streams[1]); len(messages) > 0 {
+			return redis.NewXStreamSliceCmdResult([]redis.XStream{{Stream: a.Streams[0], Messages: messages}}, nil)
+		}
+
+		select {
+		case <-ctx.Done():
+			return redis.NewXStreamSliceCmdResult(nil, ctx.Err())
+		case <-s.added:
+		case <-time.After(a.Block):
+			return redis.NewXStreamSliceCmdResult(nil, redis.Nil)
+		}
+	}
+}
+
+func (s *fakeStream) XRevRangeN(_ context.Context, _, _, _ string, _ int64) *redis.XMessageSliceCmd {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.entries) == 0 {
+		return redis.NewXMessageSliceCmdResult(nil, nil)
+	}
+	return redis.NewXMessageSliceCmdResult([]redis.XMessage{s.entries[len(s.entries)-1]}, nil)
+}
+
+// streamSeq is the time part of the IDs of the fake, they count the entries up from 1.
+func streamSeq(id string) int {
+	ms, _, _ := strings.Cut(id, "-")
+	seq, _ := strconv.Atoi(ms)
+	return seq
+}
+
+func newStreamSubscriber(client cache.RedisClient) *order_status.RedisStreamSubscriber {
+	logger, _ := log.NewLogger("debug")
+
+	return order_status.NewRedisStreamSubscriber(client, logger, noop.NewTracerProvider(), config.OrderStatusStreamConfig{
+		BlockTimeout: 50 * time.Millisecond,
+	})
+}
+
+func receive(t *testing.T, events <-chan model.OrderStatusEvent) model.OrderStatusEvent {
+	t.Helper()
+
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("no order status event")
+		return model.OrderStatusEvent{}
+	}
+}
+
+func TestRedisStreamSubscriber_FreshSubscriptionSkipsOlderEntries(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := newFakeStream()
+	client.add(model.StatusCreated, time.Now())
+
+	events, err := newStreamSubscriber(client).SubscribeOrderStatus(ctx, uuid.New(), "")
+	assert.Nil(t, err)
+
+	id := client.add(model.StatusPaid, time.Now())
+
+	event := receive(t, events)
+	assert.Equal(t, model.StatusPaid, event.Status)
+	assert.Equal(t, id, event.ID)
+}
+
+func TestRedisStreamSubscriber_ResumesAfterEventID(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := newFakeStream()
+	first := client.add(model.StatusCreated, time.Now())
+	client.add(model.StatusPaid, time.Now())
+
+	events, err := newStreamSubscriber(client).SubscribeOrderStatus(ctx, uuid.New(), first)
+	assert.Nil(t, err)
+
+	assert.Equal(t, model.StatusPaid, receive(t, events).Status)
+}
+
+func TestRedisStreamSubscriber_ResumesAfterOccurredAt(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	createdAt := time.Now().Add(-time.Minute)
+	client := newFakeStream()
+	client.add(model.StatusCreated, createdAt)
+	client.add(model.StatusPaid, createdAt.Add(time.Second))
+
+	// what a gRPC client has: the updated_at of the last message it received
+	events, err := newStreamSubscriber(client).SubscribeOrderStatus(ctx, uuid.New(), createdAt.UTC().Format(time.RFC3339Nano))
+	assert.Nil(t, err)
+
+	assert.Equal(t, model.StatusPaid, receive(t, events).Status)
+}
+
+func TestRedisStreamPublisher_ResumesAfterOccurredAtOfRetriedEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger, _ := log.NewLogger("debug")
+	client := newFakeStream()
+	publisher := order_status.NewRedisStreamPublisher(client, logger, noop.NewTracerProvider(), config.OrderStatusStreamConfig{
+		MaxLen: 100,
+		TTL:    time.Hour,
+	})
+
+	// the relay delivers both changes late, after outbox retries
+	createdAt := time.Now().Add(-time.Minute)
+	orderID := uuid.New()
+	assert.Nil(t, publisher.PublishOrderStatus(ctx, orderID, model.StatusCreated, createdAt))
+	assert.Nil(t, publisher.PublishOrderStatus(ctx, orderID, model.StatusPaid, createdAt.Add(time.Second)))
+
+	// the client saw the order as created, only the later change is new to it
+	events, err := newStreamSubscriber(client).SubscribeOrderStatus(ctx, orderID, createdAt.UTC().Format(time.RFC3339Nano))
+	assert.Nil(t, err)
+
+	event := receive(t, events)
+	assert.Equal(t, model.StatusPaid, event.Status)
+	assert.True(t, createdAt.Add(time.Second).Equal(event.OccurredAt))
+}
+
+func TestRedisStreamSubscriber_InvalidResumeToken(t *testing.T) {
+	events, err := newStreamSubscriber(newFakeStream()).SubscribeOrderStatus(context.Background(), uuid.New(), "yesterday")
+
+	assert.Nil(t, events)
+	assert.Equal(t, errs.ErrInvalidResumeToken, err)
+}
+
+func TestRedisStreamSubscriber_StopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	events, err := newStreamSubscriber(newFakeStream()).SubscribeOrderStatus(ctx, uuid.New(), "")
+	assert.Nil(t, err)
+
+	cancel()
+	select {
+	case _, ok := <-events:
+		assert.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("subscription not closed")
+	}
+}
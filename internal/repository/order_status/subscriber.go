@@ -2,6 +2,7 @@ package order_status
 
 import (
 	"context"
+	"time"
 
 	errs "OrderService/internal/errors"
 	"OrderService/internal/model"
@@ -31,12 +32,18 @@ func NewRedisSubscriber(client cache.RedisClient, logger log.Logger, tp trace.Tr
 
 const layer = "RedisOrderStatusSubscriber"
 
-func (s *RedisSubscriber) SubscribeOrderStatus(ctx context.Context, orderID uuid.UUID) (<-chan model.OrderStatus, *errorz.CustomError) {
+func (s *RedisSubscriber) SubscribeOrderStatus(ctx context.Context, orderID uuid.UUID, afterID string) (<-chan model.OrderStatusEvent, *errorz.CustomError) {
 	const method = "SubscribeOrderStatus"
 
 	ctx, span := s.tracer.Start(ctx, "OrderStatusSubscriber.SubscribeOrderStatus")
 	defer span.End()
 
+	// pub/sub keeps no history, so there is nothing to resume from
+	if afterID != "" {
+		span.SetStatus(codes.Error, errs.ErrResumeNotSupported.Message)
+		return nil, errs.ErrResumeNotSupported
+	}
+
 	channelName := orderStatusChannel(orderID)
 	span.SetAttributes(
 		attribute.String("order.id", orderID.String()),
@@ -53,7 +60,7 @@ func (s *RedisSubscriber) SubscribeOrderStatus(ctx context.Context, orderID uuid
 	}
 
 	messages := pubsub.Channel()
-	out := make(chan model.OrderStatus)
+	out := make(chan model.OrderStatusEvent)
 
 	go func() {
 		defer close(out)
@@ -79,7 +86,7 @@ func (s *RedisSubscriber) SubscribeOrderStatus(ctx context.Context, orderID uuid
 				select {
 				case <-ctx.Done():
 					return
-				case out <- status:
+				case out <- model.OrderStatusEvent{OrderID: orderID, Status: status, OccurredAt: time.Now()}:
 				}
 			}
 		}
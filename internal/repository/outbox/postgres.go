@@ -0,0 +1,196 @@
+package outbox
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"OrderService/internal/metrics"
+	"OrderService/internal/model"
+
+	errorz "github.com/erdedan1/shared/errs"
+	log "github.com/erdedan1/shared/logger"
+	"github.com/jmoiron/sqlx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type Repository struct {
+	db     *sqlx.DB
+	log    log.Logger
+	tracer trace.Tracer
+}
+
+func New(db *sqlx.DB, log log.Logger, tp trace.TracerProvider) *Repository {
+	return &Repository{
+		db:     db,
+		log:    log,
+		tracer: tp.Tracer("order-service/OutboxRepository"),
+	}
+}
+
+const layer = "PostgresOutboxRepo"
+
+// ClaimPending leases up to limit due events so that other relays skip them until the lease expires.
+// Only the oldest undelivered event of every aggregate is claimed, which keeps per-order ordering.
+// Dead-lettered events are neither claimed nor hold back the events after them.
+func (r *Repository) ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]model.OutboxEvent, *errorz.CustomError) {
+	const method = "ClaimPending"
+	defer metrics.ObservePostgres(method, time.Now())
+
+	ctx, span := r.tracer.Start(ctx, "OutboxRepository.ClaimPending")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int("limit", limit),
+	)
+
+	query := `
+		UPDATE outbox
+		SET next_attempt_at = NOW() + $2 * INTERVAL '1 millisecond'
+		WHERE id IN (
+			SELECT o.id
+			FROM outbox o
+			WHERE o.delivered_at IS NULL
+				AND o.dead_lettered_at IS NULL
+				AND o.next_attempt_at <= NOW()
+				AND NOT EXISTS (
+					SELECT 1 FROM outbox e
+					WHERE e.aggregate_id = o.aggregate_id
+						AND e.delivered_at IS NULL
+						AND e.dead_lettered_at IS NULL
+						AND e.id < o.id
+				)
+			ORDER BY o.id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, aggregate_id, event_type, payload, attempts, created_at
+	`
+
+	var events []model.OutboxEvent
+	if err := r.db.SelectContext(ctx, &events, query, limit, lease.Milliseconds()); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		r.log.Error(layer, method, err.Error(), err)
+		return nil, errorz.New(errorz.INTERNAL, "failed to claim outbox events")
+	}
+
+	slices.SortFunc(events, func(a, b model.OutboxEvent) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	span.SetStatus(codes.Ok, "outbox events claimed")
+
+	return events, nil
+}
+
+func (r *Repository) MarkDelivered(ctx context.Context, id int64) *errorz.CustomError {
+	const method = "MarkDelivered"
+	defer metrics.ObservePostgres(method, time.Now())
+
+	ctx, span := r.tracer.Start(ctx, "OutboxRepository.MarkDelivered")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("outbox.id", id),
+	)
+
+	query := `UPDATE outbox SET delivered_at = NOW(), last_error = NULL WHERE id = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, id); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		r.log.Error(layer, method, err.Error(), err, "outbox_id", id)
+		return errorz.New(errorz.INTERNAL, "failed to mark outbox event delivered")
+	}
+
+	span.SetStatus(codes.Ok, "outbox event delivered")
+
+	return nil
+}
+
+func (r *Repository) MarkFailed(ctx context.Context, id int64, nextAttemptAt time.Time, reason string) *errorz.CustomError {
+	const method = "MarkFailed"
+	defer metrics.ObservePostgres(method, time.Now())
+
+	ctx, span := r.tracer.Start(ctx, "OutboxRepository.MarkFailed")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("outbox.id", id),
+	)
+
+	query := `UPDATE outbox SET attempts = attempts + 1, next_attempt_at = $1, last_error = $2 WHERE id = $3`
+
+	if _, err := r.db.ExecContext(ctx, query, nextAttemptAt, reason, id); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		r.log.Error(layer, method, err.Error(), err, "outbox_id", id)
+		return errorz.New(errorz.INTERNAL, "failed to mark outbox event failed")
+	}
+
+	span.SetStatus(codes.Ok, "outbox event rescheduled")
+
+	return nil
+}
+
+// MarkDeadLettered gives up on the event, it stays in the table with its last error.
+func (r *Repository) MarkDeadLettered(ctx context.Context, id int64, reason string) *errorz.CustomError {
+	const method = "MarkDeadLettered"
+	defer metrics.ObservePostgres(method, time.Now())
+
+	ctx, span := r.tracer.Start(ctx, "OutboxRepository.MarkDeadLettered")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("outbox.id", id),
+	)
+
+	query := `UPDATE outbox SET attempts = attempts + 1, dead_lettered_at = NOW(), last_error = $1 WHERE id = $2`
+
+	if _, err := r.db.ExecContext(ctx, query, reason, id); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		r.log.Error(layer, method, err.Error(), err, "outbox_id", id)
+		return errorz.New(errorz.INTERNAL, "failed to mark outbox event dead-lettered")
+	}
+
+	span.SetStatus(codes.Ok, "outbox event dead-lettered")
+
+	return nil
+}
+
+// DeleteDelivered removes the events delivered before the given time, pending and
+// dead-lettered ones are kept.
+func (r *Repository) DeleteDelivered(ctx context.Context, before time.Time) (int64, *errorz.CustomError) {
+	const method = "DeleteDelivered"
+	defer metrics.ObservePostgres(method, time.Now())
+
+	ctx, span := r.tracer.Start(ctx, "OutboxRepository.DeleteDelivered")
+	defer span.End()
+
+	res, err := r.db.ExecContext(ctx, `DELETE FROM outbox WHERE delivered_at IS NOT NULL AND delivered_at <= $1`, before)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		r.log.Error(layer, method, err.Error(), err)
+		return 0, errorz.New(errorz.INTERNAL, "failed to delete delivered outbox events")
+	}
+
+	deleted, _ := res.RowsAffected()
+
+	span.SetAttributes(
+		attribute.Int64("outbox.deleted", deleted),
+	)
+	span.SetStatus(codes.Ok, "delivered outbox events deleted")
+
+	return deleted, nil
+}
@@ -2,7 +2,9 @@ package user
 
 import (
 	"context"
+	"slices"
 	"sync"
+	"time"
 
 	"OrderService/internal/model"
 
@@ -31,24 +33,24 @@ func NewRepo(logger log.Logger, tp trace.TracerProvider) *Repo {
 	}
 	users := []model.User{
 		{
-			ID:   uuid.MustParse("1179803e-06f0-4369-b94f-14e26ec190a3"),
-			Name: "Gleb",
-			Role: "TRADER",
+			ID:    uuid.MustParse("1179803e-06f0-4369-b94f-14e26ec190a3"),
+			Name:  "Gleb",
+			Roles: []string{model.RoleTrader},
 		},
 		{
-			ID:   uuid.MustParse("2179803e-06f0-4369-b94f-14e26ec190a3"),
-			Name: "Oleg",
-			Role: "ADMIN",
+			ID:    uuid.MustParse("2179803e-06f0-4369-b94f-14e26ec190a3"),
+			Name:  "Oleg",
+			Roles: []string{model.RoleAdmin},
 		},
 		{
-			ID:   uuid.MustParse("3179803e-06f0-4369-b94f-14e26ec190a3"),
-			Name: "Vova",
-			Role: "TRADER",
+			ID:    uuid.MustParse("3179803e-06f0-4369-b94f-14e26ec190a3"),
+			Name:  "Vova",
+			Roles: []string{model.RoleTrader},
 		},
 		{
-			ID:   uuid.MustParse("4179803e-06f0-4369-b94f-14e26ec190a3"),
-			Name: "Arsen",
-			Role: "ADMIN",
+			ID:    uuid.MustParse("4179803e-06f0-4369-b94f-14e26ec190a3"),
+			Name:  "Arsen",
+			Roles: []string{model.RoleAdmin},
 		},
 	}
 	for _, user := range users {
@@ -59,13 +61,21 @@ func NewRepo(logger log.Logger, tp trace.TracerProvider) *Repo {
 
 const layer = "UserInMemoryRepo"
 
-func (r *Repo) CreateUser(ctx context.Context, user model.User) {
+func (r *Repo) CreateUser(ctx context.Context, user model.User) *errors.CustomError {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	if _, found := r.Users[user.ID]; found {
+		r.log.Error(layer, "CreateUser", "user already exists", errs.ErrUserAlreadyExists, "user_id", user.ID)
+		return errs.ErrUserAlreadyExists
+	}
+
 	r.log.Debug(layer, "CreateUser", "user success created")
 
+	user.Roles = slices.Clone(user.Roles)
 	r.Users[user.ID] = user
+
+	return nil
 }
 
 func (r *Repo) GetUserById(ctx context.Context, id uuid.UUID) (*model.User, *errors.CustomError) {
@@ -84,6 +94,7 @@ func (r *Repo) GetUserById(ctx context.Context, id uuid.UUID) (*model.User, *err
 			"found user",
 			"user_id", id,
 		)
+		u.Roles = slices.Clone(u.Roles)
 		return &u, nil
 	}
 
@@ -94,3 +105,45 @@ func (r *Repo) GetUserById(ctx context.Context, id uuid.UUID) (*model.User, *err
 
 	return nil, errs.ErrUserNotFound
 }
+
+func (r *Repo) UpdateUserRoles(ctx context.Context, id uuid.UUID, roles []string) *errors.CustomError {
+	const method = "UpdateUserRoles"
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u, found := r.Users[id]
+	if !found {
+		r.log.Error(layer, method, "user not found", errs.ErrUserNotFound, "user_id", id)
+		return errs.ErrUserNotFound
+	}
+
+	u.Roles = slices.Clone(roles)
+	r.Users[id] = u
+
+	r.log.Debug(layer, method, "user roles updated", "user_id", id)
+
+	return nil
+}
+
+func (r *Repo) DeactivateUser(ctx context.Context, id uuid.UUID, at time.Time) *errors.CustomError {
+	const method = "DeactivateUser"
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u, found := r.Users[id]
+	if !found {
+		r.log.Error(layer, method, "user not found", errs.ErrUserNotFound, "user_id", id)
+		return errs.ErrUserNotFound
+	}
+
+	if u.DeactivatedAt == nil {
+		u.DeactivatedAt = &at
+		r.Users[id] = u
+	}
+
+	r.log.Debug(layer, method, "user deactivated", "user_id", id)
+
+	return nil
+}
@@ -0,0 +1,198 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	errs "OrderService/internal/errors"
+	"OrderService/internal/metrics"
+	"OrderService/internal/model"
+
+	errorz "github.com/erdedan1/shared/errs"
+	log "github.com/erdedan1/shared/logger"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type PostgresRepo struct {
+	db     *sqlx.DB
+	log    log.Logger
+	tracer trace.Tracer
+}
+
+func NewPostgresRepo(db *sqlx.DB, log log.Logger, tp trace.TracerProvider) *PostgresRepo {
+	return &PostgresRepo{
+		db:     db,
+		log:    log,
+		tracer: tp.Tracer("order-service/UserRepo"),
+	}
+}
+
+const layerPostgres = "UserPostgresRepo"
+
+type userRow struct {
+	ID            uuid.UUID      `db:"id"`
+	Name          string         `db:"name"`
+	Roles         pq.StringArray `db:"roles"`
+	DeactivatedAt *time.Time     `db:"deactivated_at"`
+}
+
+func (r userRow) toModel() *model.User {
+	return &model.User{
+		ID:            r.ID,
+		Name:          r.Name,
+		Roles:         []string(r.Roles),
+		DeactivatedAt: r.DeactivatedAt,
+	}
+}
+
+func (r *PostgresRepo) CreateUser(ctx context.Context, user model.User) *errorz.CustomError {
+	const method = "CreateUser"
+	defer metrics.ObservePostgres(method, time.Now())
+
+	ctx, span := r.tracer.Start(ctx, "UserRepo.CreateUser")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("user.id", user.ID.String()),
+	)
+
+	query := `INSERT INTO users (id, name, roles, deactivated_at) VALUES ($1, $2, $3, $4)`
+
+	_, err := r.db.ExecContext(ctx, query, user.ID, user.Name, rolesArray(user.Roles), user.DeactivatedAt)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		r.log.Error(layerPostgres, method, err.Error(), err, "user_id", user.ID)
+
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return errs.ErrUserAlreadyExists
+		}
+		return errs.ErrFailedToCreateUser
+	}
+
+	span.SetStatus(codes.Ok, "user success created")
+
+	return nil
+}
+
+func (r *PostgresRepo) GetUserById(ctx context.Context, id uuid.UUID) (*model.User, *errorz.CustomError) {
+	const method = "GetUserById"
+	defer metrics.ObservePostgres(method, time.Now())
+
+	ctx, span := r.tracer.Start(ctx, "UserRepo.GetUserById")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("user.id", id.String()),
+	)
+
+	query := `SELECT id, name, roles, deactivated_at FROM users WHERE id = $1`
+
+	var row userRow
+	if err := r.db.GetContext(ctx, &row, query, id); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		r.log.Error(layerPostgres, method, err.Error(), err, "user_id", id)
+
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.ErrUserNotFound
+		}
+		return nil, errs.ErrFailedToGetUser
+	}
+
+	span.SetStatus(codes.Ok, "user found")
+
+	return row.toModel(), nil
+}
+
+func (r *PostgresRepo) UpdateUserRoles(ctx context.Context, id uuid.UUID, roles []string) *errorz.CustomError {
+	const method = "UpdateUserRoles"
+	defer metrics.ObservePostgres(method, time.Now())
+
+	ctx, span := r.tracer.Start(ctx, "UserRepo.UpdateUserRoles")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("user.id", id.String()),
+		attribute.StringSlice("user.roles", roles),
+	)
+
+	query := `UPDATE users SET roles = $1 WHERE id = $2`
+
+	return r.updateUser(ctx, span, method, id, query, rolesArray(roles), id)
+}
+
+// DeactivateUser marks the user deactivated, deactivating an already deactivated user keeps the first timestamp.
+func (r *PostgresRepo) DeactivateUser(ctx context.Context, id uuid.UUID, at time.Time) *errorz.CustomError {
+	const method = "DeactivateUser"
+	defer metrics.ObservePostgres(method, time.Now())
+
+	ctx, span := r.tracer.Start(ctx, "UserRepo.DeactivateUser")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("user.id", id.String()),
+	)
+
+	query := `UPDATE users SET deactivated_at = COALESCE(deactivated_at, $1) WHERE id = $2`
+
+	return r.updateUser(ctx, span, method, id, query, at, id)
+}
+
+func (r *PostgresRepo) updateUser(
+	ctx context.Context,
+	span trace.Span,
+	method string,
+	id uuid.UUID,
+	query string,
+	args ...any,
+) *errorz.CustomError {
+	res, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		r.log.Error(layerPostgres, method, err.Error(), err, "user_id", id)
+		return errs.ErrFailedToUpdateUser
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		r.log.Error(layerPostgres, method, err.Error(), err, "user_id", id)
+		return errs.ErrFailedToUpdateUser
+	}
+
+	if affected == 0 {
+		span.RecordError(errs.ErrUserNotFound)
+		span.SetStatus(codes.Error, errs.ErrUserNotFound.Error())
+
+		r.log.Error(layerPostgres, method, "user not found", errs.ErrUserNotFound, "user_id", id)
+		return errs.ErrUserNotFound
+	}
+
+	span.SetStatus(codes.Ok, "user success updated")
+
+	return nil
+}
+
+// rolesArray keeps an empty role list from being written as NULL, the column is NOT NULL.
+func rolesArray(roles []string) pq.StringArray {
+	if roles == nil {
+		return pq.StringArray{}
+	}
+
+	return pq.StringArray(roles)
+}
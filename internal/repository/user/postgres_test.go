@@ -0,0 +1,218 @@
+package user_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	errs "OrderService/internal/errors"
+	"OrderService/internal/model"
+	"OrderService/internal/repository/user"
+
+	log "github.com/erdedan1/shared/logger"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// fakeUsersDB keeps the users table in memory and answers like Postgres does through
+// lib/pq: roles come back in the text[] wire format and a duplicate id is a 23505.
+type fakeUsersDB struct {
+	mu   sync.Mutex
+	rows map[string][]driver.Value
+}
+
+func newFakeUsersDB() *fakeUsersDB {
+	return &fakeUsersDB{rows: map[string][]driver.Value{}}
+}
+
+func (db *fakeUsersDB) query(query string, args []driver.NamedValue) (driver.Rows, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if !strings.Contains(query, "FROM users WHERE id = $1") {
+		return nil, errors.New("unexpected query: " + query)
+	}
+
+	rows := &fakeRows{columns: []string{"id", "name", "roles", "deactivated_at"}}
+	if row, ok := db.rows[args[0].Value.(string)]; ok {
+		rows.values = [][]driver.Value{row}
+	}
+	return rows, nil
+}
+
+func (db *fakeUsersDB) exec(query string, args []driver.NamedValue) (driver.Result, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	switch {
+	case strings.Contains(query, "INSERT INTO users"):
+		id := args[0].Value.(string)
+		if _, ok := db.rows[id]; ok {
+			return nil, &pq.Error{Code: "23505", Constraint: "users_pkey"}
+		}
+		if args[2].Value == nil {
+			return nil, &pq.Error{Code: "23502", Column: "roles"}
+		}
+		db.rows[id] = []driver.Value{id, args[1].Value, []byte(args[2].Value.(string)), args[3].Value}
+		return driver.RowsAffected(1), nil
+
+	case strings.Contains(query, "UPDATE users SET roles"):
+		row, ok := db.rows[args[1].Value.(string)]
+		if !ok {
+			return driver.RowsAffected(0), nil
+		}
+		row[2] = []byte(args[0].Value.(string))
+		return driver.RowsAffected(1), nil
+
+	case strings.Contains(query, "UPDATE users SET deactivated_at = COALESCE(deactivated_at, $1)"):
+		row, ok := db.rows[args[1].Value.(string)]
+		if !ok {
+			return driver.RowsAffected(0), nil
+		}
+		if row[3] == nil {
+			row[3] = args[0].Value
+		}
+		return driver.RowsAffected(1), nil
+	}
+
+	return nil, errors.New("unexpected statement: " + query)
+}
+
+func (db *fakeUsersDB) Connect(context.Context) (driver.Conn, error) { return &fakeConn{db: db}, nil }
+func (db *fakeUsersDB) Driver() driver.Driver                        { return nil }
+
+type fakeConn struct {
+	db *fakeUsersDB
+}
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *fakeConn) Close() error                        { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return c.db.query(query, args)
+}
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return c.db.exec(query, args)
+}
+
+type fakeRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+func newTestRepo() *user.PostgresRepo {
+	logger, _ := log.NewLogger("debug")
+
+	return user.NewPostgresRepo(sqlx.NewDb(sql.OpenDB(newFakeUsersDB()), "postgres"), logger, noop.NewTracerProvider())
+}
+
+func TestPostgresRepo_CreateAndGetUser(t *testing.T) {
+	repo := newTestRepo()
+	ctx := context.Background()
+
+	id := uuid.New()
+	err := repo.CreateUser(ctx, model.User{ID: id, Name: "Gleb", Roles: []string{model.RoleTrader, model.RoleAdmin}})
+	assert.Nil(t, err)
+
+	got, err := repo.GetUserById(ctx, id)
+
+	assert.Nil(t, err)
+	assert.Equal(t, id, got.ID)
+	assert.Equal(t, "Gleb", got.Name)
+	assert.Equal(t, []string{model.RoleTrader, model.RoleAdmin}, got.Roles)
+	assert.Nil(t, got.DeactivatedAt)
+}
+
+func TestPostgresRepo_CreateUserWithoutRoles(t *testing.T) {
+	repo := newTestRepo()
+	ctx := context.Background()
+
+	id := uuid.New()
+	// the column is NOT NULL, no roles are stored as an empty array
+	err := repo.CreateUser(ctx, model.User{ID: id, Name: "Vova"})
+	assert.Nil(t, err)
+
+	got, err := repo.GetUserById(ctx, id)
+
+	assert.Nil(t, err)
+	assert.Empty(t, got.Roles)
+}
+
+func TestPostgresRepo_CreateUserTwice(t *testing.T) {
+	repo := newTestRepo()
+	ctx := context.Background()
+
+	u := model.User{ID: uuid.New(), Name: "Oleg", Roles: []string{model.RoleAdmin}}
+	assert.Nil(t, repo.CreateUser(ctx, u))
+
+	err := repo.CreateUser(ctx, u)
+
+	assert.Equal(t, errs.ErrUserAlreadyExists, err)
+}
+
+func TestPostgresRepo_GetUnknownUser(t *testing.T) {
+	got, err := newTestRepo().GetUserById(context.Background(), uuid.New())
+
+	assert.Nil(t, got)
+	assert.Equal(t, errs.ErrUserNotFound, err)
+}
+
+func TestPostgresRepo_UpdateUserRoles(t *testing.T) {
+	repo := newTestRepo()
+	ctx := context.Background()
+
+	id := uuid.New()
+	assert.Nil(t, repo.CreateUser(ctx, model.User{ID: id, Name: "Arsen", Roles: []string{model.RoleTrader}}))
+
+	assert.Nil(t, repo.UpdateUserRoles(ctx, id, []string{model.RoleAdmin, model.RoleTrader}))
+
+	got, err := repo.GetUserById(ctx, id)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{model.RoleAdmin, model.RoleTrader}, got.Roles)
+
+	assert.Equal(t, errs.ErrUserNotFound, repo.UpdateUserRoles(ctx, uuid.New(), nil))
+}
+
+func TestPostgresRepo_DeactivateUserKeepsFirstTimestamp(t *testing.T) {
+	repo := newTestRepo()
+	ctx := context.Background()
+
+	id := uuid.New()
+	assert.Nil(t, repo.CreateUser(ctx, model.User{ID: id, Name: "Gleb", Roles: []string{model.RoleTrader}}))
+
+	first := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	assert.Nil(t, repo.DeactivateUser(ctx, id, first))
+	assert.Nil(t, repo.DeactivateUser(ctx, id, first.Add(time.Hour)))
+
+	got, err := repo.GetUserById(ctx, id)
+	assert.Nil(t, err)
+	assert.NotNil(t, got.DeactivatedAt)
+	assert.True(t, got.DeactivatedAt.Equal(first))
+	assert.False(t, got.IsActive())
+
+	assert.Equal(t, errs.ErrUserNotFound, repo.DeactivateUser(ctx, uuid.New(), first))
+}
@@ -0,0 +1,82 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+
+	"OrderService/config"
+	"OrderService/internal/usecase"
+
+	errors "github.com/erdedan1/shared/errs"
+	log "github.com/erdedan1/shared/logger"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Sweeper deletes idempotency keys once their retention window is over.
+type Sweeper struct {
+	repo   usecase.IdempotencyKeyRepo
+	log    log.Logger
+	tracer trace.Tracer
+	cfg    config.IdempotencyConfig
+}
+
+func NewSweeper(repo usecase.IdempotencyKeyRepo, log log.Logger, tp trace.TracerProvider, cfg config.IdempotencyConfig) *Sweeper {
+	return &Sweeper{
+		repo:   repo,
+		log:    log,
+		tracer: tp.Tracer("order-service/IdempotencyKeySweeper"),
+		cfg:    cfg,
+	}
+}
+
+const layer = "IdempotencyKeySweeper"
+
+func (s *Sweeper) Run(ctx context.Context) {
+	const method = "Run"
+
+	s.log.Info(layer, method, "idempotency key sweeper started")
+	defer s.log.Info(layer, method, "idempotency key sweeper stopped")
+
+	ticker := time.NewTicker(s.cfg.SweepInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.Sweep(ctx); err != nil {
+			s.log.Error(layer, method, err.Error(), err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sweep deletes every expired key and returns how many were removed.
+func (s *Sweeper) Sweep(ctx context.Context) (int64, *errors.CustomError) {
+	const method = "Sweep"
+
+	ctx, span := s.tracer.Start(ctx, "IdempotencyKeySweeper.Sweep")
+	defer span.End()
+
+	deleted, err := s.repo.DeleteExpiredIdempotencyKeys(ctx, time.Now())
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return 0, err
+	}
+
+	span.SetAttributes(
+		attribute.Int64("idempotency_keys.deleted", deleted),
+	)
+	span.SetStatus(codes.Ok, "expired idempotency keys swept")
+
+	if deleted > 0 {
+		s.log.Debug(layer, method, "expired idempotency keys deleted", "count", deleted)
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,119 @@
+package lifecircuit
+
+import (
+	"context"
+	"time"
+
+	"OrderService/config"
+	errs "OrderService/internal/errors"
+	"OrderService/internal/model"
+	"OrderService/internal/usecase"
+
+	errors "github.com/erdedan1/shared/errs"
+	log "github.com/erdedan1/shared/logger"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Worker moves orders through the automatic part of the workflow. Due orders are
+// claimed from Postgres, so any number of workers can run side by side and an
+// order advances whether or not anybody is subscribed to it.
+type Worker struct {
+	repo   usecase.OrderRepo
+	log    log.Logger
+	tracer trace.Tracer
+	cfg    config.OrderLifecircuitConfig
+}
+
+func NewWorker(repo usecase.OrderRepo, log log.Logger, tp trace.TracerProvider, cfg config.OrderLifecircuitConfig) *Worker {
+	return &Worker{
+		repo:   repo,
+		log:    log,
+		tracer: tp.Tracer("order-service/LifecircuitWorker"),
+		cfg:    cfg,
+	}
+}
+
+const layer = "LifecircuitWorker"
+
+func (w *Worker) Run(ctx context.Context) {
+	const method = "Run"
+
+	w.log.Info(layer, method, "order lifecircuit worker started")
+	defer w.log.Info(layer, method, "order lifecircuit worker stopped")
+
+	ticker := time.NewTicker(w.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		claimed, err := w.AdvanceDue(ctx)
+		if err != nil {
+			w.log.Error(layer, method, err.Error(), err)
+		}
+
+		// a full batch means there is probably more due, so skip the pause
+		if err == nil && claimed == w.cfg.BatchSize {
+			if ctx.Err() != nil {
+				return
+			}
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// AdvanceDue moves one batch of due orders to their next status and returns how many were claimed.
+func (w *Worker) AdvanceDue(ctx context.Context) (int, *errors.CustomError) {
+	const method = "AdvanceDue"
+
+	ctx, span := w.tracer.Start(ctx, "LifecircuitWorker.AdvanceDue")
+	defer span.End()
+
+	orders, err := w.repo.ClaimDueOrders(ctx, w.cfg.BatchSize, w.cfg.ClaimLease)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return 0, err
+	}
+
+	span.SetAttributes(
+		attribute.Int("orders.claimed", len(orders)),
+	)
+
+	for _, order := range orders {
+		next, ok := model.OrderWorkflow.Next(order.Status)
+		if !ok {
+			w.log.Error(layer, method, "order has no automatic transition", errs.ErrFailedToUpdateOrderStatus, "order_id", order.ID, "status", order.Status)
+			continue
+		}
+
+		update := model.OrderStatusUpdate{
+			Status:           next,
+			Actor:            model.SystemActor(),
+			NextTransitionAt: model.OrderWorkflow.NextTransitionAt(next, time.Now(), w.cfg.StepInterval),
+		}
+
+		if updateErr := w.repo.UpdateOrderStatus(ctx, order.ID, update); updateErr != nil {
+			// the order was moved by somebody else after it was claimed, e.g. cancelled
+			if updateErr.Code == errors.FAILED_PRECONDITION {
+				w.log.Debug(layer, method, "order left its lifecircuit", "order_id", order.ID, "status", order.Status)
+				continue
+			}
+
+			w.log.Error(layer, method, updateErr.Error(), updateErr, "order_id", order.ID, "status", next)
+			continue
+		}
+
+		w.log.Debug(layer, method, "order advanced", "order_id", order.ID, "from", order.Status, "to", next)
+	}
+
+	span.SetStatus(codes.Ok, "due orders advanced")
+
+	return len(orders), nil
+}
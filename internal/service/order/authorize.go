@@ -0,0 +1,84 @@
+package order
+
+import (
+	"context"
+
+	"OrderService/internal/auth"
+	errs "OrderService/internal/errors"
+	"OrderService/internal/model"
+	"OrderService/internal/policy"
+
+	errors "github.com/erdedan1/shared/errs"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// authorize loads the requester and checks the policy for acting on the owner's orders:
+// own is checked when the requester is the owner, other when acting on someone else's
+// orders. An empty other means the action is only allowed on the requester's own orders.
+// A zero requester is the owner itself. The requester is read once per request, the
+// stored user already in ctx is used when there is one.
+func (s *Service) authorize(
+	ctx context.Context,
+	requesterID, ownerID uuid.UUID,
+	own, other policy.Action,
+) (*model.User, *errors.CustomError) {
+	const method = "authorize"
+
+	ctx, span := s.tracer.Start(ctx, "OrderService.authorize")
+	defer span.End()
+
+	if requesterID == uuid.Nil {
+		requesterID = ownerID
+	}
+
+	action := own
+	if requesterID != ownerID {
+		action = other
+	}
+
+	span.SetAttributes(
+		attribute.String("requester.id", requesterID.String()),
+		attribute.String("owner.id", ownerID.String()),
+		attribute.String("policy.action", string(action)),
+	)
+
+	// the rate limiter may have read the user for this request already
+	user, ok := auth.UserFromContext(ctx, requesterID)
+	if !ok {
+		var err *errors.CustomError
+		user, err = s.userRepo.GetUserById(ctx, requesterID)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+
+			s.log.Error(layer, method, err.Error(), err, "user_id", requesterID)
+			return nil, err
+		}
+	}
+
+	if !user.IsActive() {
+		span.RecordError(errs.ErrUserDeactivated)
+		span.SetStatus(codes.Error, errs.ErrUserDeactivated.Message)
+
+		s.log.Error(layer, method, errs.ErrUserDeactivated.Message, errs.ErrUserDeactivated, "user_id", requesterID)
+		return nil, errs.ErrUserDeactivated
+	}
+
+	if action == "" || !s.policy.Allows(user, action) {
+		span.RecordError(errs.ErrPermissionDenied)
+		span.SetStatus(codes.Error, errs.ErrPermissionDenied.Message)
+
+		s.log.Error(
+			layer, method,
+			errs.ErrPermissionDenied.Message, errs.ErrPermissionDenied,
+			"user_id", requesterID,
+			"owner_id", ownerID,
+			"action", action,
+		)
+		return nil, errs.ErrPermissionDenied
+	}
+
+	return user, nil
+}
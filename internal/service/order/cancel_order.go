@@ -0,0 +1,94 @@
+package order
+
+import (
+	"context"
+	"time"
+
+	"OrderService/internal/dto"
+	errs "OrderService/internal/errors"
+	"OrderService/internal/model"
+	"OrderService/internal/policy"
+
+	errors "github.com/erdedan1/shared/errs"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+func (s *Service) CancelOrder(ctx context.Context, request *dto.CancelOrderRequest) (*dto.CancelOrderResponse, *errors.CustomError) {
+	const method = "CancelOrder"
+
+	ctx, span := s.tracer.Start(ctx, "OrderService.CancelOrder")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("user.id", request.UserUUID.String()),
+		attribute.String("order.id", request.OrderUUID.String()),
+		attribute.String("cancel.reason", request.Reason),
+	)
+
+	reason := model.CancelReason(request.Reason)
+	if !reason.IsValid() {
+		span.RecordError(errs.ErrInvalidCancelReason)
+		span.SetStatus(codes.Error, errs.ErrInvalidCancelReason.Message)
+
+		s.log.Error(layer, method, errs.ErrInvalidCancelReason.Message, errs.ErrInvalidCancelReason, "order_id", request.OrderUUID, "reason", request.Reason)
+		return nil, errs.ErrInvalidCancelReason
+	}
+
+	requester, err := s.authorize(ctx, request.RequesterUUID, request.UserUUID, policy.ActionCancelOrder, policy.ActionCancelAnyOrder)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := s.orderRepo.GetOrder(ctx, request.OrderUUID, request.UserUUID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		s.log.Error(
+			layer, method,
+			err.Error(), err,
+			"user_id", request.UserUUID,
+			"order_id", request.OrderUUID,
+		)
+		return nil, err
+	}
+
+	if !order.Status.IsCancellable() {
+		span.RecordError(errs.ErrOrderCannotBeCancelled)
+		span.SetStatus(codes.Error, errs.ErrOrderCannotBeCancelled.Message)
+
+		s.log.Error(layer, method, errs.ErrOrderCannotBeCancelled.Message, errs.ErrOrderCannotBeCancelled, "order_id", order.ID, "status", order.Status)
+		return nil, errs.ErrOrderCannotBeCancelled
+	}
+
+	actor := model.UserActor(requester.ID)
+	if requester.ID != request.UserUUID {
+		actor = model.AdminActor(requester.ID)
+	}
+
+	cancellation := model.OrderCancellation{
+		Reason:      reason,
+		Actor:       actor,
+		CancelledAt: time.Now(),
+	}
+
+	if err := s.orderRepo.CancelOrder(ctx, order.ID, cancellation); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		s.log.Error(layer, method, err.Error(), err, "order_id", order.ID, "status", order.Status)
+		return nil, err
+	}
+
+	span.SetStatus(codes.Ok, "order success cancelled")
+	s.log.Debug(layer, method, "order success cancelled", "order_id", order.ID, "reason", reason)
+
+	return &dto.CancelOrderResponse{
+		OrderUUID:   order.ID,
+		Status:      model.StatusCancelled.ToString(),
+		Reason:      string(cancellation.Reason),
+		CancelledBy: requester.ID,
+		CancelledAt: &cancellation.CancelledAt,
+	}, nil
+}
@@ -3,9 +3,15 @@ package order
 import (
 	"OrderService/internal/dto"
 	errs "OrderService/internal/errors"
+	"OrderService/internal/metrics"
 	"OrderService/internal/model"
+	"OrderService/internal/policy"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"regexp"
+	"time"
 
 	errors "github.com/erdedan1/shared/errs"
 	"github.com/google/uuid"
@@ -29,12 +35,49 @@ func (s *Service) CreateOrder(
 		attribute.String("user.id", request.UserUUID.String()),
 	)
 
+	if !isValidClientOrderID(request.ClientOrderID) {
+		span.RecordError(errs.ErrInvalidClientOrderID)
+		span.SetStatus(codes.Error, errs.ErrInvalidClientOrderID.Message)
+		return nil, errs.ErrInvalidClientOrderID
+	}
+
+	key, err := s.idempotencyKey(request)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
 	user, err := s.getAuthorizedUser(ctx, request)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := s.ensureMarketsAccess(ctx, request.UserUUID, &dto.ViewMarketsRequest{UserRole: user.Role}); err != nil {
+	// a retry of an order that was created replays it, even if its market has been
+	// disabled or deleted since
+	if key != nil {
+		order, err := s.orderRepo.GetIdempotentOrder(ctx, key)
+		if err == nil {
+			span.SetStatus(codes.Ok, "order create replayed")
+			return createOrderResponse(order, request), nil
+		}
+		if err != errs.ErrOrderNotFound {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+
+			s.log.Error(layer, method, err.Error(), err, "user_id", request.UserUUID)
+			return nil, err
+		}
+	}
+
+	role, err := s.marketsRole(user, request)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Message)
+		return nil, err
+	}
+
+	if err := s.ensureMarketAllowed(ctx, request.UserUUID, role, request.MarketUUID); err != nil {
 		return nil, err
 	}
 
@@ -45,8 +88,12 @@ func (s *Service) CreateOrder(
 		request.Price,
 		request.OrderType,
 	)
+	if request.ClientOrderID != "" {
+		req.ClientOrderID = new(request.ClientOrderID)
+	}
+	req.NextTransitionAt = model.OrderWorkflow.NextTransitionAt(req.Status, time.Now(), s.cfg.Infrastructure.OrderLifecircuitConfig.StepInterval)
 
-	order, err := s.orderRepo.CreateOrder(ctx, req)
+	order, err := s.orderRepo.CreateOrder(ctx, req, key)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -59,42 +106,97 @@ func (s *Service) CreateOrder(
 		return nil, err
 	}
 
-	if s.orderStatusPublisher != nil {
-		if publishErr := s.orderStatusPublisher.PublishOrderStatus(ctx, order.ID, order.Status); publishErr != nil {
-			s.log.Error(layer, method, publishErr.Error(), publishErr, "order_id", order.ID, "status", order.Status)
-		}
+	// a replayed idempotent request returns the order created by the first one
+	if order.ID == req.ID {
+		metrics.OrdersCreated.WithLabelValues(request.MarketUUID.String(), request.OrderType).Inc()
 	}
 
 	span.SetStatus(codes.Ok, "order success created")
 	s.log.Debug(layer, method, "order success created")
 
+	return createOrderResponse(order, request), nil
+}
+
+func createOrderResponse(order *model.Order, request *dto.CreateOrderRequest) *dto.CreateOrderResponse {
 	return &dto.CreateOrderResponse{
-		OrderUUID: order.ID,
-		Status:    order.Status.ToString(),
-		CreatedAt: order.CreatedAt,
-		UpdatedAt: order.UpdatedAt,
+		OrderUUID:     order.ID,
+		Status:        order.Status.ToString(),
+		CreatedAt:     order.CreatedAt,
+		UpdatedAt:     order.UpdatedAt,
+		ClientOrderID: request.ClientOrderID,
+	}
+}
+
+const (
+	maxIdempotencyKeyLength = 255
+	maxClientOrderIDLength  = 64
+
+	// clientOrderIDKeyPrefix keeps keys derived from client order ids apart from explicit ones.
+	clientOrderIDKeyPrefix = "client-order-id:"
+)
+
+var clientOrderIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]+$`)
+
+func isValidClientOrderID(clientOrderID string) bool {
+	if clientOrderID == "" {
+		return true
+	}
+
+	return len(clientOrderID) <= maxClientOrderIDLength && clientOrderIDPattern.MatchString(clientOrderID)
+}
+
+// idempotencyKey builds the key stored with the order, nil when the client sent none.
+// Without an explicit key the client order id is used, so a retry that reuses it
+// replays the original order instead of failing on the duplicate id.
+func (s *Service) idempotencyKey(request *dto.CreateOrderRequest) (*model.IdempotencyKey, *errors.CustomError) {
+	key := request.IdempotencyKey
+	if key == "" && request.ClientOrderID != "" {
+		key = clientOrderIDKeyPrefix + request.ClientOrderID
+	}
+	if key == "" {
+		return nil, nil
+	}
+
+	if len(key) > maxIdempotencyKeyLength {
+		return nil, errs.ErrInvalidIdempotencyKey
+	}
+
+	return &model.IdempotencyKey{
+		UserID:      request.UserUUID,
+		Key:         key,
+		RequestHash: createOrderRequestHash(request),
+		ExpiresAt:   time.Now().Add(s.cfg.Infrastructure.Idempotency.Retention),
 	}, nil
 }
 
+// createOrderRequestHash fingerprints the payload so a reused key with a different request is detected.
+// The requested role only narrows the markets checked, the order is the same without it.
+func createOrderRequestHash(request *dto.CreateOrderRequest) string {
+	h := sha256.New()
+	fmt.Fprintf(
+		h, "%s|%s|%s|%s|%d|%s",
+		request.UserUUID,
+		request.MarketUUID,
+		request.OrderType,
+		request.Price.String(),
+		request.Quantity,
+		request.ClientOrderID,
+	)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// getAuthorizedUser checks that the requester may create orders for itself and returns it.
+// The role the client asked to trade with only narrows the markets, it never grants access.
 func (s *Service) getAuthorizedUser(ctx context.Context, request *dto.CreateOrderRequest) (*model.User, *errors.CustomError) {
 	const method = "getAuthorizedUser"
 
-	ctx, span := s.tracer.Start(ctx, "OrderService.getAuthorizedUser")
-	defer span.End()
-
-	user, err := s.userRepo.GetUserById(ctx, request.UserUUID)
+	user, err := s.authorize(ctx, request.RequesterUUID, request.UserUUID, policy.ActionCreateOrder, "")
 	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, err.Error())
-
-		s.log.Error(layer, method, err.Error(), err, "user_id", request.UserUUID)
 		return nil, err
 	}
 
-	if user.Role != request.UserRole {
-		span.RecordError(errs.ErrUserHasNoAccessToMarket)
-		span.SetStatus(codes.Error, "no access rights")
-
+	if request.UserRole != "" && !user.HasRole(request.UserRole) {
 		s.log.Error(layer, method, "user has no acces to market", errs.ErrUserHasNoAccessToMarket, "user_id", request.UserUUID)
 		return nil, errs.ErrUserHasNoAccessToMarket
 	}
@@ -102,50 +204,103 @@ func (s *Service) getAuthorizedUser(ctx context.Context, request *dto.CreateOrde
 	return user, nil
 }
 
-func (s *Service) ensureMarketsAccess(ctx context.Context, userID uuid.UUID, roles *dto.ViewMarketsRequest) *errors.CustomError {
-	const method = "ensureMarketsAccess"
-	ctx, span := s.tracer.Start(ctx, "OrderService.ensureMarketsAccess")
-	defer span.End()
+// marketsRole is the role markets are looked up for: the requested one, or the first role
+// of the user the policy lets create orders. Roles that may not create orders have no
+// markets to trade on.
+func (s *Service) marketsRole(user *model.User, request *dto.CreateOrderRequest) (string, *errors.CustomError) {
+	const method = "marketsRole"
 
-	cacheKey := "markets:" + userID.String()
-	marketsCache, err := s.marketCache.Get(ctx, cacheKey)
-	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, err.Error())
+	if request.UserRole != "" {
+		if !s.policy.AllowsRole(request.UserRole, policy.ActionCreateOrder) {
+			s.log.Error(layer, method, "role may not trade", errs.ErrUserHasNoAccessToMarket, "user_id", user.ID, "role", request.UserRole)
+			return "", errs.ErrUserHasNoAccessToMarket
+		}
 
-		s.log.Error(layer, method, err.Error(), err)
-		return err
+		return request.UserRole, nil
 	}
 
-	if len(marketsCache) != 0 || marketsCache != nil {
-		return nil
+	for _, role := range user.Roles {
+		if s.policy.AllowsRole(role, policy.ActionCreateOrder) {
+			return role, nil
+		}
 	}
 
-	markets, err := s.marketSrv.ViewMarketsByRoles(ctx, roles)
+	// not reached while authorize checks the same action
+	s.log.Error(layer, method, "user has no role that may trade", errs.ErrUserHasNoAccessToMarket, "user_id", user.ID)
+	return "", errs.ErrUserHasNoAccessToMarket
+}
+
+// ensureMarketAllowed checks that the market is one the role may trade on and that it is
+// still open for trading.
+func (s *Service) ensureMarketAllowed(ctx context.Context, userID uuid.UUID, role string, marketID uuid.UUID) *errors.CustomError {
+	const method = "ensureMarketAllowed"
+	ctx, span := s.tracer.Start(ctx, "OrderService.ensureMarketAllowed")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("market.id", marketID.String()),
+		attribute.String("user.role", role),
+	)
+
+	markets, err := s.roleMarkets(ctx, &dto.ViewMarketsRequest{UserRole: role})
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		fmt.Println("111", err.Error())
+
 		s.log.Error(layer, method, err.Error(), err, "user_id", userID)
 		return err
 	}
 
-	if len(markets) == 0 {
-		span.RecordError(errs.ErrMarketNotFound)
-		span.SetStatus(codes.Error, "not found markets")
-
-		s.log.Error(layer, method, errs.ErrMarketNotFound.Message, errs.ErrMarketNotFound, "user_id", userID)
-		return errs.ErrMarketNotFound
-	}
-
-	err = s.marketCache.Set(ctx, cacheKey, markets, s.cfg.Infrastructure.RedisConfig.TTL)
+	err = checkMarket(markets, marketID)
 	if err != nil {
 		span.RecordError(err)
-		span.SetStatus(codes.Error, err.Error())
+		span.SetStatus(codes.Error, err.Message)
 
-		s.log.Error(layer, method, err.Message, err, "user_id", userID)
+		s.log.Error(layer, method, err.Message, err, "user_id", userID, "market_id", marketID)
 		return err
 	}
 
 	return nil
 }
+
+// roleMarkets are the markets of the role, from the cache shared by all users of the role
+// or from the spot instrument service on a miss.
+func (s *Service) roleMarkets(ctx context.Context, request *dto.ViewMarketsRequest) ([]dto.ViewMarketsResponse, *errors.CustomError) {
+	return s.marketCache.GetOrLoad(
+		ctx,
+		model.RoleMarketsKey(request.UserRole),
+		s.cfg.Infrastructure.RedisConfig.TTL,
+		func(ctx context.Context) ([]dto.ViewMarketsResponse, *errors.CustomError) {
+			markets, err := s.marketSrv.ViewMarketsByRoles(ctx, request)
+			if err != nil {
+				return nil, err
+			}
+
+			if len(markets) == 0 {
+				return nil, errs.ErrMarketNotFound
+			}
+
+			return markets, nil
+		},
+	)
+}
+
+// checkMarket finds the market among the markets of the role and tells why it can not be
+// traded on, if it can not.
+func checkMarket(markets []dto.ViewMarketsResponse, marketID uuid.UUID) *errors.CustomError {
+	for _, market := range markets {
+		if market.UUID != marketID {
+			continue
+		}
+
+		switch {
+		case market.DeletedAt != nil:
+			return errs.ErrMarketDeleted
+		case !market.Enabled:
+			return errs.ErrMarketDisabled
+		}
+		return nil
+	}
+
+	return errs.ErrMarketNotFound
+}
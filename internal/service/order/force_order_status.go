@@ -0,0 +1,86 @@
+package order
+
+import (
+	"context"
+	"time"
+
+	"OrderService/internal/dto"
+	errs "OrderService/internal/errors"
+	"OrderService/internal/model"
+	"OrderService/internal/policy"
+
+	errors "github.com/erdedan1/shared/errs"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// ForceOrderStatus lets an admin move any order along the workflow right away. The transition
+// still has to be allowed by the workflow, only the owner check and the lifecycle timing are skipped.
+func (s *Service) ForceOrderStatus(ctx context.Context, request *dto.ForceOrderStatusRequest) (*dto.GetOrderStatusResponse, *errors.CustomError) {
+	const method = "ForceOrderStatus"
+
+	ctx, span := s.tracer.Start(ctx, "OrderService.ForceOrderStatus")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("requester.id", request.RequesterUUID.String()),
+		attribute.String("order.id", request.OrderUUID.String()),
+		attribute.String("order.status", request.Status),
+	)
+
+	status := model.OrderStatus(request.Status)
+	if !status.IsValid() {
+		span.RecordError(errs.ErrInvalidArgument)
+		span.SetStatus(codes.Error, errs.ErrInvalidArgument.Message)
+
+		s.log.Error(layer, method, errs.ErrInvalidArgument.Message, errs.ErrInvalidArgument, "order_id", request.OrderUUID, "status", request.Status)
+		return nil, errs.ErrInvalidArgument
+	}
+
+	// the owner is unknown until the order is loaded, and loading it must not come before the policy check
+	requester, err := s.authorize(ctx, request.RequesterUUID, request.RequesterUUID, policy.ActionForceOrderStatus, "")
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := s.orderRepo.GetOrderByID(ctx, request.OrderUUID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		s.log.Error(layer, method, err.Error(), err, "order_id", request.OrderUUID)
+		return nil, err
+	}
+
+	now := time.Now()
+	if transitionErr := model.OrderWorkflow.CanTransition(order, status, now); transitionErr != nil {
+		err := errs.IllegalStatusTransition(transitionErr)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Message)
+
+		s.log.Error(layer, method, transitionErr.Error(), transitionErr, "order_id", order.ID, "status", status)
+		return nil, err
+	}
+
+	update := model.OrderStatusUpdate{
+		Status:           status,
+		Actor:            model.AdminActor(requester.ID),
+		NextTransitionAt: model.OrderWorkflow.NextTransitionAt(status, now, s.cfg.Infrastructure.OrderLifecircuitConfig.StepInterval),
+	}
+
+	if err := s.orderRepo.UpdateOrderStatus(ctx, order.ID, update); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		s.log.Error(layer, method, err.Error(), err, "order_id", order.ID, "status", status)
+		return nil, err
+	}
+
+	span.SetStatus(codes.Ok, "order status forced")
+	s.log.Info(layer, method, "order status forced", "order_id", order.ID, "from", order.Status, "to", status, "admin_id", requester.ID)
+
+	return &dto.GetOrderStatusResponse{
+		Status:    string(status),
+		UpdatedAt: &now,
+	}, nil
+}
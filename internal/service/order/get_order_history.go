@@ -0,0 +1,59 @@
+package order
+
+import (
+	"context"
+
+	"OrderService/internal/dto"
+
+	errors "github.com/erdedan1/shared/errs"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+func (s *Service) GetOrderHistory(ctx context.Context, request *dto.GetOrderStatusRequest) (*dto.GetOrderHistoryResponse, *errors.CustomError) {
+	const method = "GetOrderHistory"
+
+	ctx, span := s.tracer.Start(ctx, "OrderService.GetOrderHistory")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("user.id", request.UserUUID.String()),
+		attribute.String("order.id", request.OrderUUID.String()),
+		attribute.String("order.client_id", request.ClientOrderID),
+	)
+
+	order, err := s.getRequestedOrder(ctx, request)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		s.log.Error(
+			layer, method,
+			err.Error(), err,
+			"user_id", request.UserUUID,
+			"order_id", request.OrderUUID,
+		)
+		return nil, err
+	}
+
+	history, err := s.orderRepo.GetOrderHistory(ctx, order.ID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		s.log.Error(layer, method, err.Error(), err, "order_id", order.ID)
+		return nil, err
+	}
+
+	response := &dto.GetOrderHistoryResponse{
+		OrderUUID: order.ID,
+		History:   make([]dto.OrderStatusChangeResponse, 0, len(history)),
+	}
+	for i := range history {
+		response.History = append(response.History, *new(dto.OrderStatusChangeResponse).FromModel(&history[i]))
+	}
+
+	span.SetStatus(codes.Ok, "get order history success")
+
+	return response, nil
+}
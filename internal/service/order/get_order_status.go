@@ -18,9 +18,10 @@ func (s *Service) GetOrderStatus(ctx context.Context, request *dto.GetOrderStatu
 	span.SetAttributes(
 		attribute.String("user.id", request.UserUUID.String()),
 		attribute.String("order.id", request.OrderUUID.String()),
+		attribute.String("order.client_id", request.ClientOrderID),
 	)
 
-	order, err := s.orderRepo.GetOrder(ctx, request.OrderUUID, request.UserUUID)
+	order, err := s.getRequestedOrder(ctx, request)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
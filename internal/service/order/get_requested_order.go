@@ -0,0 +1,25 @@
+package order
+
+import (
+	"context"
+
+	"OrderService/internal/dto"
+	"OrderService/internal/model"
+	"OrderService/internal/policy"
+
+	errors "github.com/erdedan1/shared/errs"
+)
+
+// getRequestedOrder authorizes the requester to view the owner's orders and loads the order
+// a request points at, by the client order id when one is given.
+func (s *Service) getRequestedOrder(ctx context.Context, request *dto.GetOrderStatusRequest) (*model.Order, *errors.CustomError) {
+	if _, err := s.authorize(ctx, request.RequesterUUID, request.UserUUID, policy.ActionViewOrder, policy.ActionViewAnyOrder); err != nil {
+		return nil, err
+	}
+
+	if request.ClientOrderID != "" {
+		return s.orderRepo.GetOrderByClientOrderID(ctx, request.UserUUID, request.ClientOrderID)
+	}
+
+	return s.orderRepo.GetOrder(ctx, request.OrderUUID, request.UserUUID)
+}
@@ -0,0 +1,117 @@
+package order
+
+import (
+	"context"
+
+	"OrderService/internal/dto"
+	errs "OrderService/internal/errors"
+	"OrderService/internal/model"
+	"OrderService/internal/policy"
+
+	errors "github.com/erdedan1/shared/errs"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const (
+	defaultListOrdersLimit = 50
+	maxListOrdersLimit     = 200
+)
+
+func (s *Service) ListOrders(ctx context.Context, request *dto.ListOrdersRequest) (*dto.ListOrdersResponse, *errors.CustomError) {
+	const method = "ListOrders"
+
+	ctx, span := s.tracer.Start(ctx, "OrderService.ListOrders")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("user.id", request.UserUUID.String()),
+		attribute.String("market.id", request.MarketUUID.String()),
+	)
+
+	filter, err := listOrdersFilter(request)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Message)
+
+		s.log.Error(layer, method, err.Message, err, "user_id", request.UserUUID)
+		return nil, err
+	}
+
+	if _, err := s.authorize(ctx, request.RequesterUUID, request.UserUUID, policy.ActionViewOrder, policy.ActionViewAnyOrder); err != nil {
+		return nil, err
+	}
+
+	limit := filter.Limit
+	// one extra row tells whether there is a next page
+	filter.Limit++
+
+	orders, err := s.orderRepo.ListOrders(ctx, filter)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		s.log.Error(layer, method, err.Error(), err, "user_id", request.UserUUID)
+		return nil, err
+	}
+
+	response := &dto.ListOrdersResponse{
+		Orders: make([]dto.OrderResponse, 0, min(len(orders), limit)),
+	}
+	for i := range orders {
+		if i == limit {
+			last := orders[i-1]
+			response.NextCursor = model.OrderCursor{CreatedAt: *last.CreatedAt, ID: last.ID}.Encode()
+			break
+		}
+		response.Orders = append(response.Orders, *new(dto.OrderResponse).FromModel(&orders[i]))
+	}
+
+	span.SetStatus(codes.Ok, "list orders success")
+
+	s.log.Debug(layer, method, "list orders", "count", len(response.Orders))
+
+	return response, nil
+}
+
+func listOrdersFilter(request *dto.ListOrdersRequest) (model.OrderFilter, *errors.CustomError) {
+	filter := model.OrderFilter{
+		UserUUID:    request.UserUUID,
+		MarketUUID:  request.MarketUUID,
+		Type:        request.OrderType,
+		CreatedFrom: request.CreatedFrom,
+		CreatedTo:   request.CreatedTo,
+		Limit:       request.Limit,
+	}
+
+	switch {
+	case filter.Limit < 0:
+		return filter, errs.ErrInvalidArgument
+	case filter.Limit == 0:
+		filter.Limit = defaultListOrdersLimit
+	case filter.Limit > maxListOrdersLimit:
+		filter.Limit = maxListOrdersLimit
+	}
+
+	if filter.CreatedFrom != nil && filter.CreatedTo != nil && !filter.CreatedFrom.Before(*filter.CreatedTo) {
+		return filter, errs.ErrInvalidArgument
+	}
+
+	for _, raw := range request.Statuses {
+		status := model.OrderStatus(raw)
+		if !status.IsValid() {
+			return filter, errs.ErrInvalidArgument
+		}
+		filter.Statuses = append(filter.Statuses, status)
+	}
+
+	if request.Cursor != "" {
+		cursor, err := model.DecodeOrderCursor(request.Cursor)
+		if err != nil {
+			return filter, errs.ErrInvalidOrderCursor
+		}
+		filter.After = cursor
+	}
+
+	return filter, nil
+}
@@ -2,6 +2,7 @@ package order
 
 import (
 	"OrderService/config"
+	"OrderService/internal/policy"
 	"OrderService/internal/usecase"
 
 	log "github.com/erdedan1/shared/logger"
@@ -14,7 +15,7 @@ type Service struct {
 	marketCache           usecase.MarketCacheRepo
 	marketSrv             usecase.MarketService
 	orderStatusSubscriber usecase.OrderStatusSubscriber
-	orderStatusPublisher  usecase.OrderStatusPublisher
+	policy                *policy.Policy
 	log                   log.Logger
 	tracer                trace.Tracer
 	cfg                   config.Config
@@ -26,7 +27,6 @@ func New(
 	marketCache usecase.MarketCacheRepo,
 	marketSrv usecase.MarketService,
 	orderStatusSubscriber usecase.OrderStatusSubscriber,
-	orderStatusPublisher usecase.OrderStatusPublisher,
 	log log.Logger,
 	tp trace.TracerProvider,
 	cfg *config.Config,
@@ -37,7 +37,7 @@ func New(
 		marketCache:           marketCache,
 		marketSrv:             marketSrv,
 		orderStatusSubscriber: orderStatusSubscriber,
-		orderStatusPublisher:  orderStatusPublisher,
+		policy:                policy.Default(),
 		log:                   log,
 		tracer:                tp.Tracer("order-service/Service"),
 		cfg:                   *cfg,
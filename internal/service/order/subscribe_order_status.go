@@ -6,10 +6,10 @@ import (
 
 	"OrderService/internal/dto"
 	errs "OrderService/internal/errors"
+	"OrderService/internal/metrics"
 	"OrderService/internal/model"
 
 	errors "github.com/erdedan1/shared/errs"
-	"github.com/google/uuid"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 )
@@ -23,11 +23,13 @@ func (s *Service) SubscribeOrderStatus(ctx context.Context, request *dto.GetOrde
 	span.SetAttributes(
 		attribute.String("user.id", request.UserUUID.String()),
 		attribute.String("order.id", request.OrderUUID.String()),
+		attribute.String("order.client_id", request.ClientOrderID),
+		attribute.Bool("resume", request.ResumeToken != ""),
 	)
 
 	ch := make(chan *dto.GetOrderStatusResponse, 1)
 
-	order, err := s.orderRepo.GetOrder(ctx, request.OrderUUID, request.UserUUID)
+	order, err := s.getRequestedOrder(ctx, request)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -40,18 +42,19 @@ func (s *Service) SubscribeOrderStatus(ctx context.Context, request *dto.GetOrde
 		return nil, err
 	}
 
-	if order.Status == model.StatusClosed {
+	// a finished order has nothing left to replay, so it always gets the snapshot
+	if order.Status.IsTerminal() {
 		defer close(ch)
 
 		ch <- &dto.GetOrderStatusResponse{Status: order.Status.ToString(), UpdatedAt: order.UpdatedAt}
 
-		span.SetStatus(codes.Ok, "order already completed and closed")
+		span.SetStatus(codes.Ok, "order already finalized")
 
 		s.log.Debug(
 			layer, method,
-			"order already completed and closed",
+			"order already finalized",
 			"user_id", request.UserUUID,
-			"order_id", request.OrderUUID,
+			"order_id", order.ID,
 		)
 
 		return ch, nil
@@ -62,57 +65,79 @@ func (s *Service) SubscribeOrderStatus(ctx context.Context, request *dto.GetOrde
 		return ch, errs.ErrUnavailableRedis
 	}
 
-	statusCh, err := s.orderStatusSubscriber.SubscribeOrderStatus(ctx, request.OrderUUID)
+	eventCh, err := s.orderStatusSubscriber.SubscribeOrderStatus(ctx, order.ID, request.ResumeToken)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Message)
 
-		s.log.Error(layer, method, err.Error(), err, "order_id", request.OrderUUID, "user_id", request.UserUUID)
+		s.log.Error(layer, method, err.Error(), err, "order_id", order.ID, "user_id", request.UserUUID)
 		return nil, err
 	}
 
-	go s.publishOrderLifecircuit(ctx, request.UserUUID, order.ID, order.Status)
+	resuming := request.ResumeToken != ""
+
+	// the subscription is positioned before the snapshot is read, a change committed in
+	// between then arrives as an event instead of falling between the two
+	if !resuming {
+		order, err = s.orderRepo.GetOrder(ctx, order.ID, order.UserUUID)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+
+			s.log.Error(layer, method, err.Error(), err, "order_id", request.OrderUUID)
+			return nil, err
+		}
+	}
 
 	go func(initialStatus model.OrderStatus, initialUpdatedAt *time.Time) {
 		defer close(ch)
 
+		metrics.ActiveSubscriptions.Inc()
+		defer metrics.ActiveSubscriptions.Dec()
+
 		ctx, cancel := context.WithTimeout(context.Background(), s.cfg.Infrastructure.OrderLifecircuitConfig.TimeOut)
 		defer cancel()
 
-		select {
-		case <-ctx.Done():
-			s.log.Debug(layer, method, "SubscribeOrderStatus response ctx.Done()", "error", ctx.Err())
-			//мб надо отправлять ошибку или еще что то
-			return
-		case ch <- &dto.GetOrderStatusResponse{Status: initialStatus.ToString(), UpdatedAt: initialUpdatedAt}:
+		// a resumed subscription continues from the stream, the client already has the snapshot
+		var lastStatus model.OrderStatus
+		if !resuming {
+			select {
+			case <-ctx.Done():
+				s.log.Debug(layer, method, "SubscribeOrderStatus response ctx.Done()", "error", ctx.Err())
+				//мб надо отправлять ошибку или еще что то
+				return
+			case ch <- &dto.GetOrderStatusResponse{Status: initialStatus.ToString(), UpdatedAt: initialUpdatedAt}:
+			}
+			lastStatus = initialStatus
+
+			if initialStatus.IsTerminal() {
+				return
+			}
 		}
-		lastStatus := initialStatus
 
 		for {
 			select {
 			case <-ctx.Done():
 				s.log.Debug(layer, method, "SubscribeOrderStatus status ctx.Done()", "error", ctx.Err())
 				return
-			case status, ok := <-statusCh:
+			case event, ok := <-eventCh:
 				if !ok {
 					return
 				}
 
-				if status == lastStatus {
+				if event.Status == lastStatus {
 					continue
 				}
-				lastStatus = status
-
-				now := time.Now()
+				lastStatus = event.Status
 
 				select {
 				case <-ctx.Done():
 					s.log.Debug(layer, method, "SubscribeOrderStatus send ctx.Done()", "error", ctx.Err())
 					return
-				case ch <- &dto.GetOrderStatusResponse{Status: status.ToString(), UpdatedAt: &now}:
+				case ch <- &dto.GetOrderStatusResponse{Status: event.Status.ToString(), UpdatedAt: &event.OccurredAt, EventID: event.ID}:
 				}
 
-				if status == model.StatusClosed {
+				if event.Status.IsTerminal() {
 					return
 				}
 			}
@@ -123,29 +148,3 @@ func (s *Service) SubscribeOrderStatus(ctx context.Context, request *dto.GetOrde
 
 	return ch, nil
 }
-
-func (s *Service) publishOrderLifecircuit(ctx context.Context, userID, orderID uuid.UUID, initialStatus model.OrderStatus) {
-	const method = "publishOrderLifecircuit"
-	defer s.log.Debug(layer, method, "end sobitie")
-	s.log.Debug(layer, method, "start new sobitie")
-
-	status := initialStatus
-	for {
-		nextStatus, hasNext := model.NextOrderStatus(status)
-		if !hasNext {
-			return
-		}
-
-		select {
-		case <-ctx.Done():
-			s.log.Debug(layer, method, "SubscribeOrderStatus send ctx.Done()", "error", ctx.Err())
-			return
-		case <-time.After(s.cfg.Infrastructure.OrderLifecircuitConfig.StepInterval):
-		}
-		if updateErr := s.UpdateOrderStatus(ctx, userID, orderID, nextStatus); updateErr != nil {
-			s.log.Error(layer, method, "publishOrderLifecircuit UpdateOrderStatus error", updateErr)
-			return
-		}
-		status = nextStatus
-	}
-}
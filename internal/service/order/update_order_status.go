@@ -3,6 +3,7 @@ package order
 import (
 	"OrderService/internal/model"
 	"context"
+	"time"
 
 	errs "OrderService/internal/errors"
 
@@ -10,7 +11,7 @@ import (
 	"github.com/google/uuid"
 )
 
-func (s *Service) UpdateOrderStatus(ctx context.Context, userID, orderID uuid.UUID, status model.OrderStatus) *errors.CustomError {
+func (s *Service) UpdateOrderStatus(ctx context.Context, userID, orderID uuid.UUID, status model.OrderStatus, actor model.OrderStatusActor) *errors.CustomError {
 	const method = "UpdateOrderStatus"
 
 	order, err := s.orderRepo.GetOrder(ctx, orderID, userID)
@@ -19,20 +20,25 @@ func (s *Service) UpdateOrderStatus(ctx context.Context, userID, orderID uuid.UU
 		return err
 	}
 
-	if order.Status == status || order.UserUUID != userID {
+	if order.UserUUID != userID {
 		return errs.ErrInvalidArgument
 	}
 
-	if updateErr := s.orderRepo.UpdateOrderStatus(ctx, orderID, status); updateErr != nil {
-		s.log.Error(layer, method, updateErr.Error(), updateErr, "order_id", orderID, "status", status)
-		return updateErr
+	now := time.Now()
+	if transitionErr := model.OrderWorkflow.CanTransition(order, status, now); transitionErr != nil {
+		s.log.Error(layer, method, transitionErr.Error(), transitionErr, "order_id", orderID, "status", status)
+		return errs.IllegalStatusTransition(transitionErr)
+	}
+
+	update := model.OrderStatusUpdate{
+		Status:           status,
+		Actor:            actor,
+		NextTransitionAt: model.OrderWorkflow.NextTransitionAt(status, now, s.cfg.Infrastructure.OrderLifecircuitConfig.StepInterval),
 	}
 
-	if s.orderStatusPublisher != nil {
-		if publishErr := s.orderStatusPublisher.PublishOrderStatus(ctx, orderID, status); publishErr != nil {
-			s.log.Error(layer, method, publishErr.Error(), publishErr, "order_id", orderID, "status", status)
-			return publishErr
-		}
+	if updateErr := s.orderRepo.UpdateOrderStatus(ctx, orderID, update); updateErr != nil {
+		s.log.Error(layer, method, updateErr.Error(), updateErr, "order_id", orderID, "status", status)
+		return updateErr
 	}
 
 	return nil
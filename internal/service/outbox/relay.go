@@ -0,0 +1,165 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"time"
+
+	"OrderService/config"
+	errs "OrderService/internal/errors"
+	"OrderService/internal/model"
+	"OrderService/internal/usecase"
+
+	errors "github.com/erdedan1/shared/errs"
+	log "github.com/erdedan1/shared/logger"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Relay delivers events written to the outbox table to order status subscribers.
+type Relay struct {
+	repo      usecase.OutboxRepo
+	publisher usecase.OrderStatusPublisher
+	log       log.Logger
+	tracer    trace.Tracer
+	cfg       config.OutboxConfig
+}
+
+func NewRelay(
+	repo usecase.OutboxRepo,
+	publisher usecase.OrderStatusPublisher,
+	log log.Logger,
+	tp trace.TracerProvider,
+	cfg config.OutboxConfig,
+) *Relay {
+	return &Relay{
+		repo:      repo,
+		publisher: publisher,
+		log:       log,
+		tracer:    tp.Tracer("order-service/OutboxRelay"),
+		cfg:       cfg,
+	}
+}
+
+const layer = "OutboxRelay"
+
+func (r *Relay) Run(ctx context.Context) {
+	const method = "Run"
+
+	r.log.Info(layer, method, "outbox relay started")
+	defer r.log.Info(layer, method, "outbox relay stopped")
+
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		claimed, err := r.RelayPending(ctx)
+		if err != nil {
+			r.log.Error(layer, method, err.Error(), err)
+		}
+
+		// a full batch means there is probably more waiting, so skip the pause
+		if err == nil && claimed == r.cfg.BatchSize {
+			if ctx.Err() != nil {
+				return
+			}
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayPending publishes one batch of due events and returns how many were claimed.
+func (r *Relay) RelayPending(ctx context.Context) (int, *errors.CustomError) {
+	const method = "RelayPending"
+
+	ctx, span := r.tracer.Start(ctx, "OutboxRelay.RelayPending")
+	defer span.End()
+
+	events, err := r.repo.ClaimPending(ctx, r.cfg.BatchSize, r.cfg.ClaimLease)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return 0, err
+	}
+
+	span.SetAttributes(
+		attribute.Int("outbox.claimed", len(events)),
+	)
+
+	for _, event := range events {
+		if publishErr := r.publish(ctx, event); publishErr != nil {
+			// a malformed event fails the same way every time and would hold back every
+			// later event of its order, so it is not retried
+			if publishErr == errs.ErrInvalidOutboxEvent || event.Attempts+1 >= r.cfg.MaxAttempts {
+				r.log.Error(
+					layer, method,
+					"outbox event dead-lettered", publishErr,
+					"outbox_id", event.ID,
+					"order_id", event.AggregateID,
+					"event_type", event.EventType,
+					"attempts", event.Attempts+1,
+				)
+
+				if markErr := r.repo.MarkDeadLettered(ctx, event.ID, publishErr.Error()); markErr != nil {
+					r.log.Error(layer, method, markErr.Error(), markErr, "outbox_id", event.ID)
+				}
+				continue
+			}
+
+			nextAttemptAt := time.Now().Add(r.backoff(event.Attempts))
+
+			r.log.Error(
+				layer, method,
+				publishErr.Error(), publishErr,
+				"outbox_id", event.ID,
+				"order_id", event.AggregateID,
+				"attempts", event.Attempts+1,
+				"next_attempt_at", nextAttemptAt,
+			)
+
+			if markErr := r.repo.MarkFailed(ctx, event.ID, nextAttemptAt, publishErr.Error()); markErr != nil {
+				r.log.Error(layer, method, markErr.Error(), markErr, "outbox_id", event.ID)
+			}
+			continue
+		}
+
+		if markErr := r.repo.MarkDelivered(ctx, event.ID); markErr != nil {
+			r.log.Error(layer, method, markErr.Error(), markErr, "outbox_id", event.ID)
+		}
+	}
+
+	span.SetStatus(codes.Ok, "outbox batch relayed")
+
+	return len(events), nil
+}
+
+func (r *Relay) publish(ctx context.Context, event model.OutboxEvent) *errors.CustomError {
+	switch event.EventType {
+	case model.EventOrderStatusChanged:
+		var payload model.OrderStatusEvent
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return errs.ErrInvalidOutboxEvent
+		}
+
+		return r.publisher.PublishOrderStatus(ctx, payload.OrderID, payload.Status, payload.OccurredAt)
+	default:
+		return errs.ErrInvalidOutboxEvent
+	}
+}
+
+func (r *Relay) backoff(attempts int) time.Duration {
+	delay := float64(r.cfg.BaseBackoff) * math.Pow(2, float64(attempts))
+	if delay > float64(r.cfg.MaxBackoff) {
+		return r.cfg.MaxBackoff
+	}
+
+	return time.Duration(delay)
+}
@@ -0,0 +1,82 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"OrderService/config"
+	"OrderService/internal/usecase"
+
+	errors "github.com/erdedan1/shared/errs"
+	log "github.com/erdedan1/shared/logger"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Sweeper deletes delivered outbox events once their retention window is over.
+type Sweeper struct {
+	repo   usecase.OutboxRepo
+	log    log.Logger
+	tracer trace.Tracer
+	cfg    config.OutboxConfig
+}
+
+func NewSweeper(repo usecase.OutboxRepo, log log.Logger, tp trace.TracerProvider, cfg config.OutboxConfig) *Sweeper {
+	return &Sweeper{
+		repo:   repo,
+		log:    log,
+		tracer: tp.Tracer("order-service/OutboxSweeper"),
+		cfg:    cfg,
+	}
+}
+
+const sweeperLayer = "OutboxSweeper"
+
+func (s *Sweeper) Run(ctx context.Context) {
+	const method = "Run"
+
+	s.log.Info(sweeperLayer, method, "outbox sweeper started")
+	defer s.log.Info(sweeperLayer, method, "outbox sweeper stopped")
+
+	ticker := time.NewTicker(s.cfg.SweepInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.Sweep(ctx); err != nil {
+			s.log.Error(sweeperLayer, method, err.Error(), err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sweep deletes the events delivered longer than the retention ago and returns how many were removed.
+func (s *Sweeper) Sweep(ctx context.Context) (int64, *errors.CustomError) {
+	const method = "Sweep"
+
+	ctx, span := s.tracer.Start(ctx, "OutboxSweeper.Sweep")
+	defer span.End()
+
+	deleted, err := s.repo.DeleteDelivered(ctx, time.Now().Add(-s.cfg.Retention))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return 0, err
+	}
+
+	span.SetAttributes(
+		attribute.Int64("outbox.deleted", deleted),
+	)
+	span.SetStatus(codes.Ok, "delivered outbox events swept")
+
+	if deleted > 0 {
+		s.log.Debug(sweeperLayer, method, "delivered outbox events deleted", "count", deleted)
+	}
+
+	return deleted, nil
+}
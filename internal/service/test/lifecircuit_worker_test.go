@@ -0,0 +1,118 @@
+package order
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"OrderService/config"
+	"OrderService/internal/errors"
+	"OrderService/internal/model"
+	"OrderService/internal/service/lifecircuit"
+	"OrderService/mocks"
+
+	log "github.com/erdedan1/shared/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func preparingWorkerTests(t *testing.T) (*lifecircuit.Worker, *mocks.OrderRepo) {
+	orderRepo := mocks.NewOrderRepo(t)
+
+	logger, _ := log.NewLogger("debug")
+	defer logger.Sync()
+
+	worker := lifecircuit.NewWorker(
+		orderRepo,
+		logger,
+		noop.NewTracerProvider(),
+		config.OrderLifecircuitConfig{
+			StepInterval: time.Second,
+			BatchSize:    10,
+			ClaimLease:   time.Minute,
+		},
+	)
+	return worker, orderRepo
+}
+
+func TestAdvanceDue_Success(t *testing.T) {
+	worker, orderRepo := preparingWorkerTests(t)
+	ctx := context.Background()
+
+	pending := model.Order{ID: uuid.New(), Status: model.StatusPending}
+	delivered := model.Order{ID: uuid.New(), Status: model.StatusDelivered}
+
+	orderRepo.On("ClaimDueOrders", mock.Anything, 10, time.Minute).
+		Return([]model.Order{pending, delivered}, nil)
+	orderRepo.On("UpdateOrderStatus", mock.Anything, pending.ID, mock.MatchedBy(func(update model.OrderStatusUpdate) bool {
+		return update.Status == model.StatusWaitSeller && update.Actor == model.SystemActor() && update.NextTransitionAt != nil
+	})).
+		Return(nil)
+	orderRepo.On("UpdateOrderStatus", mock.Anything, delivered.ID, mock.MatchedBy(func(update model.OrderStatusUpdate) bool {
+		return update.Status == model.StatusClosed && update.NextTransitionAt == nil
+	})).
+		Return(nil)
+
+	claimed, err := worker.AdvanceDue(ctx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, claimed)
+
+	orderRepo.AssertExpectations(t)
+}
+
+func TestAdvanceDue_OrderMovedConcurrently(t *testing.T) {
+	worker, orderRepo := preparingWorkerTests(t)
+	ctx := context.Background()
+
+	order := model.Order{ID: uuid.New(), Status: model.StatusPaid}
+
+	orderRepo.On("ClaimDueOrders", mock.Anything, 10, time.Minute).
+		Return([]model.Order{order}, nil)
+	orderRepo.On("UpdateOrderStatus", mock.Anything, order.ID, mock.Anything).
+		Return(errors.IllegalStatusTransition(&model.TransitionError{
+			From:   model.StatusCancelled,
+			To:     model.StatusOnHold,
+			Reason: "order is in a terminal status",
+		}))
+
+	claimed, err := worker.AdvanceDue(ctx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, claimed)
+
+	orderRepo.AssertExpectations(t)
+}
+
+func TestAdvanceDue_DeliveredOrderWaitsForReturnWindow(t *testing.T) {
+	worker, orderRepo := preparingWorkerTests(t)
+	ctx := context.Background()
+
+	order := model.Order{ID: uuid.New(), Status: model.StatusOnTheWay}
+
+	var delivered model.OrderStatusUpdate
+	orderRepo.On("ClaimDueOrders", mock.Anything, 10, time.Minute).
+		Return([]model.Order{order}, nil)
+	orderRepo.On("UpdateOrderStatus", mock.Anything, order.ID, mock.Anything).
+		Run(func(args mock.Arguments) {
+			delivered = args.Get(2).(model.OrderStatusUpdate)
+		}).
+		Return(nil)
+
+	claimed, err := worker.AdvanceDue(ctx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, claimed)
+	assert.Equal(t, model.StatusDelivered, delivered.Status)
+
+	// the order is not due again, and so not closed, before the return window is over
+	if assert.NotNil(t, delivered.NextTransitionAt) {
+		assert.WithinDuration(t, time.Now().Add(model.ReturnWindow), *delivered.NextTransitionAt, time.Minute)
+	}
+
+	now := time.Now()
+	deliveredOrder := &model.Order{ID: order.ID, Status: model.StatusDelivered, UpdatedAt: &now}
+	assert.NoError(t, model.OrderWorkflow.CanTransition(deliveredOrder, model.StatusReturnRequested, now.Add(time.Hour)))
+}
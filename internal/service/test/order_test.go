@@ -2,16 +2,20 @@ package order
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
 	"OrderService/config"
+	"OrderService/internal/auth"
 	"OrderService/internal/dto"
 	"OrderService/internal/errors"
 	"OrderService/internal/model"
 	"OrderService/internal/service/order"
+	"OrderService/internal/usecase"
 	"OrderService/mocks"
 
+	"github.com/erdedan1/shared/errs"
 	log "github.com/erdedan1/shared/logger"
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
@@ -27,15 +31,12 @@ func preparingTests(t *testing.T) (
 	*mocks.MarketCacheRepo,
 	*mocks.MarketService,
 	*mocks.OrderStatusSubscriber,
-	*mocks.OrderStatusPublisher,
-
 ) {
 	orderRepo := mocks.NewOrderRepo(t)
 	userRepo := mocks.NewUserRepo(t)
 	cache := mocks.NewMarketCacheRepo(t)
 	marketSrv := mocks.NewMarketService(t)
 	subscriber := mocks.NewOrderStatusSubscriber(t)
-	publisher := mocks.NewOrderStatusPublisher(t)
 
 	logger, _ := log.NewLogger("debug")
 	defer logger.Sync()
@@ -46,42 +47,44 @@ func preparingTests(t *testing.T) (
 		cache,
 		marketSrv,
 		subscriber,
-		publisher,
 		logger,
 		noop.NewTracerProvider(),
-		&config.Config{},
+		&config.Config{Infrastructure: config.InfrastructureConfig{
+			OrderLifecircuitConfig: config.OrderLifecircuitConfig{TimeOut: time.Minute},
+		}},
 	)
-	return service, orderRepo, userRepo, cache, marketSrv, subscriber, publisher
+	return service, orderRepo, userRepo, cache, marketSrv, subscriber
+}
+
+// loadMarkets stands in for a cache miss, it returns what the loader of the service loads.
+func loadMarkets(ctx context.Context, _ string, _ time.Duration, load usecase.MarketsLoader) ([]dto.ViewMarketsResponse, *errs.CustomError) {
+	return load(ctx)
 }
+
 func TestCreateOrder_Success(t *testing.T) {
-	service, orderRepo, userRepo, cache, marketSrv, _, publisher := preparingTests(t)
+	service, orderRepo, userRepo, cache, marketSrv, _ := preparingTests(t)
 	ctx := context.Background()
 
 	userID := uuid.MustParse("1179803e-06f0-4369-b94f-14e26ec190a3")
-	user := &model.User{ID: userID, Role: "USER_ROLE_TRADER"}
+	marketID := uuid.New()
+	user := &model.User{ID: userID, Roles: []string{"USER_ROLE_TRADER"}}
 	order := &model.Order{ID: uuid.New(), Status: model.StatusCreated, UserUUID: userID}
 
 	userRepo.On("GetUserById", mock.Anything, userID).
 		Return(user, nil)
-	cache.On("Get", mock.Anything, mock.Anything).
-		Return(nil, nil)
-	marketSrv.On("ViewMarketsByRole", mock.Anything, mock.Anything).
-		Return([]dto.ViewMarketsResponse{{UUID: uuid.New()}}, nil)
-	cache.On("Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
-		Return(nil)
-	cache.On("Del", mock.Anything, mock.Anything).
-		Return(nil)
-	publisher.On("PublishOrderStatus", mock.Anything, mock.Anything, mock.Anything).
-		Return(nil)
-	orderRepo.On("CreateOrder", mock.Anything, mock.Anything).
+	cache.On("GetOrLoad", mock.Anything, "markets:role:USER_ROLE_TRADER", mock.Anything, mock.Anything).
+		Return(loadMarkets, nil)
+	marketSrv.On("ViewMarketsByRoles", mock.Anything, &dto.ViewMarketsRequest{UserRole: "USER_ROLE_TRADER"}).
+		Return([]dto.ViewMarketsResponse{{UUID: marketID, Enabled: true}}, nil)
+	orderRepo.On("CreateOrder", mock.Anything, mock.Anything, mock.Anything).
 		Return(order, nil)
 
 	res, err := service.CreateOrder(ctx, &dto.CreateOrderRequest{
-		MarketUUID: uuid.New(),
+		MarketUUID: marketID,
 		UserUUID:   userID,
 		OrderType:  "Test_type",
 		Price:      decimal.NewFromInt(120),
-		UserRole:   user.Role,
+		UserRole:   user.Roles[0],
 		Quantity:   1,
 	})
 
@@ -92,16 +95,71 @@ func TestCreateOrder_Success(t *testing.T) {
 	userRepo.AssertExpectations(t)
 	cache.AssertExpectations(t)
 	marketSrv.AssertExpectations(t)
-	publisher.AssertExpectations(t)
 	orderRepo.AssertExpectations(t)
 }
 
+func TestCreateOrder_UsesFirstRoleAllowedToTrade(t *testing.T) {
+	service, orderRepo, userRepo, cache, marketSrv, _ := preparingTests(t)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	marketID := uuid.New()
+	user := &model.User{ID: userID, Roles: []string{"USER_ROLE_VIEWER", model.RoleTrader}}
+
+	userRepo.On("GetUserById", mock.Anything, userID).
+		Return(user, nil)
+	cache.On("GetOrLoad", mock.Anything, model.RoleMarketsKey(model.RoleTrader), mock.Anything, mock.Anything).
+		Return(loadMarkets, nil)
+	marketSrv.On("ViewMarketsByRoles", mock.Anything, &dto.ViewMarketsRequest{UserRole: model.RoleTrader}).
+		Return([]dto.ViewMarketsResponse{{UUID: marketID, Enabled: true}}, nil)
+	orderRepo.On("CreateOrder", mock.Anything, mock.Anything, mock.Anything).
+		Return(&model.Order{ID: uuid.New(), Status: model.StatusCreated, UserUUID: userID}, nil)
+
+	_, err := service.CreateOrder(ctx, &dto.CreateOrderRequest{
+		MarketUUID: marketID,
+		UserUUID:   userID,
+		OrderType:  "Test_type",
+		Price:      decimal.NewFromInt(120),
+		Quantity:   1,
+	})
+
+	assert.Nil(t, err)
+
+	cache.AssertExpectations(t)
+	marketSrv.AssertExpectations(t)
+}
+
+func TestCreateOrder_RequestedRoleMayNotTrade(t *testing.T) {
+	service, _, userRepo, cache, _, _ := preparingTests(t)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	user := &model.User{ID: userID, Roles: []string{"USER_ROLE_VIEWER", model.RoleTrader}}
+
+	userRepo.On("GetUserById", mock.Anything, userID).
+		Return(user, nil)
+
+	res, err := service.CreateOrder(ctx, &dto.CreateOrderRequest{
+		MarketUUID: uuid.New(),
+		UserUUID:   userID,
+		OrderType:  "Test_type",
+		Price:      decimal.NewFromInt(120),
+		UserRole:   "USER_ROLE_VIEWER",
+		Quantity:   1,
+	})
+
+	assert.Nil(t, res)
+	assert.Equal(t, errors.ErrUserHasNoAccessToMarket, err)
+
+	cache.AssertNotCalled(t, "GetOrLoad", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
 func TestCreateOrder_User_No_Acess(t *testing.T) {
-	service, _, userRepo, _, _, _, _ := preparingTests(t)
+	service, _, userRepo, _, _, _ := preparingTests(t)
 	ctx := context.Background()
 
 	userID := uuid.MustParse("1179803e-06f0-4369-b94f-14e26ec190a3")
-	user := &model.User{ID: userID, Role: "USER_ROLE_TRADER"}
+	user := &model.User{ID: userID, Roles: []string{"USER_ROLE_TRADER"}}
 
 	userRepo.On("GetUserById", mock.Anything, userID).
 		Return(user, nil)
@@ -122,8 +180,35 @@ func TestCreateOrder_User_No_Acess(t *testing.T) {
 	userRepo.AssertExpectations(t)
 }
 
+func TestCreateOrder_DeactivatedUser(t *testing.T) {
+	service, _, userRepo, _, _, _ := preparingTests(t)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	deactivatedAt := time.Now().Add(-time.Hour)
+	user := &model.User{ID: userID, Roles: []string{"USER_ROLE_TRADER"}, DeactivatedAt: &deactivatedAt}
+
+	userRepo.On("GetUserById", mock.Anything, userID).
+		Return(user, nil)
+
+	res, err := service.CreateOrder(ctx, &dto.CreateOrderRequest{
+		MarketUUID: uuid.New(),
+		UserUUID:   userID,
+		OrderType:  "Test_type",
+		Price:      decimal.NewFromInt(120),
+		UserRole:   "USER_ROLE_TRADER",
+		Quantity:   1,
+	})
+
+	assert.Nil(t, res)
+	assert.Error(t, err)
+	assert.Equal(t, errors.ErrUserDeactivated.Message, err.Message)
+
+	userRepo.AssertExpectations(t)
+}
+
 func TestCreateOrder_UserRepo_Error(t *testing.T) {
-	service, _, userRepo, _, _, _, _ := preparingTests(t)
+	service, _, userRepo, _, _, _ := preparingTests(t)
 	ctx := context.Background()
 
 	userID := uuid.New()
@@ -147,18 +232,18 @@ func TestCreateOrder_UserRepo_Error(t *testing.T) {
 }
 
 func TestCreateOrder_Market_Not_Found(t *testing.T) {
-	service, _, userRepo, cache, marketSrv, _, _ := preparingTests(t)
+	service, _, userRepo, cache, marketSrv, _ := preparingTests(t)
 	ctx := context.Background()
 
 	userID := uuid.MustParse("1179803e-06f0-4369-b94f-14e26ec190a3")
 	userRole := "USER_ROLE_TRADER"
-	user := &model.User{ID: userID, Role: "USER_ROLE_TRADER"}
+	user := &model.User{ID: userID, Roles: []string{"USER_ROLE_TRADER"}}
 
 	userRepo.On("GetUserById", mock.Anything, userID).
 		Return(user, nil)
-	cache.On("Get", mock.Anything, mock.Anything).
-		Return(nil, nil)
-	marketSrv.On("ViewMarketsByRole", mock.Anything, mock.Anything).
+	cache.On("GetOrLoad", mock.Anything, "markets:role:USER_ROLE_TRADER", mock.Anything, mock.Anything).
+		Return(loadMarkets, nil)
+	marketSrv.On("ViewMarketsByRoles", mock.Anything, mock.Anything).
 		Return(nil, errors.ErrMarketNotFound)
 
 	res, err := service.CreateOrder(ctx, &dto.CreateOrderRequest{
@@ -171,26 +256,30 @@ func TestCreateOrder_Market_Not_Found(t *testing.T) {
 	})
 
 	assert.Nil(t, res)
-	assert.Error(t, err)
+	assert.Equal(t, errors.ErrMarketNotFound.Message, err.Message)
 
 	userRepo.AssertExpectations(t)
 	cache.AssertExpectations(t)
 	marketSrv.AssertExpectations(t)
 }
 func TestGetOrderStatus_Success(t *testing.T) {
-	service, orderRepo, _, _, _, _, _ := preparingTests(t)
+	service, orderRepo, userRepo, _, _, _ := preparingTests(t)
 	ctx := context.Background()
 
 	userID := uuid.New()
 	orderID := uuid.New()
+	now := time.Now()
 
 	order := &model.Order{
-		ID:       orderID,
-		UserUUID: userID,
-		Status:   model.StatusCreated,
+		ID:        orderID,
+		UserUUID:  userID,
+		Status:    model.StatusCreated,
+		UpdatedAt: &now,
 	}
 
-	orderRepo.On("GetOrder", mock.Anything, orderID).
+	userRepo.On("GetUserById", mock.Anything, userID).
+		Return(&model.User{ID: userID, Roles: []string{model.RoleTrader}}, nil)
+	orderRepo.On("GetOrder", mock.Anything, orderID, userID).
 		Return(order, nil)
 
 	res, err := service.GetOrderStatus(ctx, &dto.GetOrderStatusRequest{
@@ -206,14 +295,36 @@ func TestGetOrderStatus_Success(t *testing.T) {
 	orderRepo.AssertExpectations(t)
 }
 
+func TestGetOrderStatus_UsesUserOfRequest(t *testing.T) {
+	// the user repo has no expectations, the user the rate limiter read is used
+	service, orderRepo, _, _, _, _ := preparingTests(t)
+
+	userID := uuid.New()
+	orderID := uuid.New()
+	ctx := auth.NewUserContext(context.Background(), &model.User{ID: userID, Roles: []string{model.RoleTrader}})
+
+	orderRepo.On("GetOrder", mock.Anything, orderID, userID).
+		Return(&model.Order{ID: orderID, UserUUID: userID, Status: model.StatusCreated}, nil)
+
+	res, err := service.GetOrderStatus(ctx, &dto.GetOrderStatusRequest{
+		UserUUID:  userID,
+		OrderUUID: orderID,
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, model.StatusCreated.ToString(), res.Status)
+}
+
 func TestGetOrderStatus_OrderRepo_Error(t *testing.T) {
-	service, orderRepo, _, _, _, _, _ := preparingTests(t)
+	service, orderRepo, userRepo, _, _, _ := preparingTests(t)
 	ctx := context.Background()
 
 	userID := uuid.New()
 	orderID := uuid.New()
 
-	orderRepo.On("GetOrder", mock.Anything, orderID).
+	userRepo.On("GetUserById", mock.Anything, userID).
+		Return(&model.User{ID: userID, Roles: []string{model.RoleTrader}}, nil)
+	orderRepo.On("GetOrder", mock.Anything, orderID, userID).
 		Return(nil, errors.ErrOrderNotFound)
 
 	res, err := service.GetOrderStatus(ctx, &dto.GetOrderStatusRequest{
@@ -222,48 +333,42 @@ func TestGetOrderStatus_OrderRepo_Error(t *testing.T) {
 	})
 
 	assert.Nil(t, res)
-	assert.Error(t, err)
+	assert.Equal(t, errors.ErrOrderNotFound, err)
 
 	orderRepo.AssertExpectations(t)
 }
 
-func TestGetOrderStatus_InvalidUserUUID(t *testing.T) {
-	service, orderRepo, _, _, _, _, _ := preparingTests(t)
+func TestGetOrderStatus_DeactivatedUser(t *testing.T) {
+	service, orderRepo, userRepo, _, _, _ := preparingTests(t)
 	ctx := context.Background()
 
 	userID := uuid.New()
-	anotherUserUUID := uuid.New()
-	orderID := uuid.New()
+	deactivatedAt := time.Now()
 
-	order := &model.Order{
-		ID:       orderID,
-		UserUUID: anotherUserUUID,
-		Status:   model.StatusCreated,
-	}
-
-	orderRepo.On("GetOrder", mock.Anything, orderID).
-		Return(order, nil)
+	userRepo.On("GetUserById", mock.Anything, userID).
+		Return(&model.User{ID: userID, Roles: []string{model.RoleTrader}, DeactivatedAt: &deactivatedAt}, nil)
 
 	res, err := service.GetOrderStatus(ctx, &dto.GetOrderStatusRequest{
 		UserUUID:  userID,
-		OrderUUID: orderID,
+		OrderUUID: uuid.New(),
 	})
 
 	assert.Nil(t, res)
-	assert.Error(t, err)
-	assert.Equal(t, errors.ErrInvalidUserID.Message, err.Message)
+	assert.Equal(t, errors.ErrUserDeactivated, err)
 
-	orderRepo.AssertExpectations(t)
+	orderRepo.AssertNotCalled(t, "GetOrder", mock.Anything, mock.Anything, mock.Anything)
 }
 
 func TestSubscribeOrderStatus_GetOrder_Error(t *testing.T) {
-	service, orderRepo, _, _, _, _, _ := preparingTests(t)
+	service, orderRepo, userRepo, _, _, _ := preparingTests(t)
 	ctx := context.Background()
 
 	orderID := uuid.New()
 	userID := uuid.New()
 
-	orderRepo.On("GetOrder", mock.Anything, orderID).
+	userRepo.On("GetUserById", mock.Anything, userID).
+		Return(&model.User{ID: userID, Roles: []string{model.RoleTrader}}, nil)
+	orderRepo.On("GetOrder", mock.Anything, orderID, userID).
 		Return(nil, errors.ErrOrderNotFound)
 
 	ch, err := service.SubscribeOrderStatus(ctx, &dto.GetOrderStatusRequest{
@@ -272,41 +377,32 @@ func TestSubscribeOrderStatus_GetOrder_Error(t *testing.T) {
 	})
 
 	assert.Nil(t, ch)
-	assert.NotNil(t, err)
+	assert.Equal(t, errors.ErrOrderNotFound, err)
 
 	orderRepo.AssertExpectations(t)
 }
 
-func TestSubscribeOrderStatus_InvalidUserUUID(t *testing.T) {
-	service, orderRepo, _, _, _, _, _ := preparingTests(t)
+func TestSubscribeOrderStatus_TraderCannotSubscribeToOtherUsersOrder(t *testing.T) {
+	service, _, userRepo, _, _, _ := preparingTests(t)
 	ctx := context.Background()
 
-	orderID := uuid.New()
-	userID := uuid.New()
-
-	order := &model.Order{
-		ID:       orderID,
-		UserUUID: uuid.New(),
-		Status:   model.StatusCreated,
-	}
+	traderID := uuid.New()
 
-	orderRepo.On("GetOrder", mock.Anything, orderID).
-		Return(order, nil)
+	userRepo.On("GetUserById", mock.Anything, traderID).
+		Return(&model.User{ID: traderID, Roles: []string{model.RoleTrader}}, nil)
 
 	ch, err := service.SubscribeOrderStatus(ctx, &dto.GetOrderStatusRequest{
-		UserUUID:  userID,
-		OrderUUID: orderID,
+		RequesterUUID: traderID,
+		UserUUID:      uuid.New(),
+		OrderUUID:     uuid.New(),
 	})
 
-	assert.NotNil(t, ch)
-	assert.Error(t, err)
-	assert.Equal(t, errors.ErrInvalidUserID.Message, err.Message)
-
-	orderRepo.AssertExpectations(t)
+	assert.Nil(t, ch)
+	assert.Equal(t, errors.ErrPermissionDenied, err)
 }
 
 func TestSubscribeOrderStatus_OrderClosed(t *testing.T) {
-	service, orderRepo, _, _, _, _, _ := preparingTests(t)
+	service, orderRepo, userRepo, _, _, _ := preparingTests(t)
 	ctx := context.Background()
 
 	orderID := uuid.New()
@@ -321,8 +417,10 @@ func TestSubscribeOrderStatus_OrderClosed(t *testing.T) {
 		UpdatedAt: &now,
 	}
 
-	orderRepo.On("GetOrder", mock.Anything, orderID).
-		Return(order, nil)
+	userRepo.On("GetUserById", mock.Anything, userID).
+		Return(&model.User{ID: userID, Roles: []string{model.RoleTrader}}, nil)
+	orderRepo.On("GetOrder", mock.Anything, orderID, userID).
+		Return(order, nil).Once()
 
 	ch, err := service.SubscribeOrderStatus(ctx, &dto.GetOrderStatusRequest{
 		UserUUID:  userID,
@@ -332,14 +430,16 @@ func TestSubscribeOrderStatus_OrderClosed(t *testing.T) {
 	assert.Nil(t, err)
 
 	res := <-ch
-
 	assert.Equal(t, model.StatusClosed.ToString(), res.Status)
 
+	_, open := <-ch
+	assert.False(t, open)
+
 	orderRepo.AssertExpectations(t)
 }
 
 func TestSubscribeOrderStatus_Subscribe_Error(t *testing.T) {
-	service, orderRepo, _, _, _, subscriber, _ := preparingTests(t)
+	service, orderRepo, userRepo, _, _, subscriber := preparingTests(t)
 
 	ctx := context.Background()
 	orderID := uuid.New()
@@ -351,10 +451,11 @@ func TestSubscribeOrderStatus_Subscribe_Error(t *testing.T) {
 		Status:   model.StatusCreated,
 	}
 
-	orderRepo.On("GetOrder", mock.Anything, orderID).
-		Return(order, nil)
-
-	subscriber.On("SubscribeOrderStatus", mock.Anything, orderID).
+	userRepo.On("GetUserById", mock.Anything, userID).
+		Return(&model.User{ID: userID, Roles: []string{model.RoleTrader}}, nil)
+	orderRepo.On("GetOrder", mock.Anything, orderID, userID).
+		Return(order, nil).Once()
+	subscriber.On("SubscribeOrderStatus", mock.Anything, orderID, "").
 		Return(nil, errors.ErrUnavailableRedis)
 
 	ch, err := service.SubscribeOrderStatus(ctx, &dto.GetOrderStatusRequest{
@@ -363,50 +464,680 @@ func TestSubscribeOrderStatus_Subscribe_Error(t *testing.T) {
 	})
 
 	assert.Nil(t, ch)
-	assert.NotNil(t, err)
+	assert.Equal(t, errors.ErrUnavailableRedis, err)
 
 	orderRepo.AssertExpectations(t)
 	subscriber.AssertExpectations(t)
 }
 
 func TestSubscribeOrderStatus_Success(t *testing.T) {
-	service, orderRepo, _, _, _, subscriber, _ := preparingTests(t)
+	service, orderRepo, userRepo, _, _, subscriber := preparingTests(t)
+
+	ctx := context.Background()
+	orderID := uuid.New()
+	userID := uuid.New()
+
+	statusCh := make(chan model.OrderStatusEvent, 2)
+
+	var calls []string
+	userRepo.On("GetUserById", mock.Anything, userID).
+		Return(&model.User{ID: userID, Roles: []string{model.RoleTrader}}, nil)
+	orderRepo.On("GetOrder", mock.Anything, orderID, userID).
+		Run(func(mock.Arguments) { calls = append(calls, "GetOrder") }).
+		Return(&model.Order{ID: orderID, UserUUID: userID, Status: model.StatusCreated}, nil).Once()
+	subscriber.On("SubscribeOrderStatus", mock.Anything, orderID, "").
+		Run(func(mock.Arguments) { calls = append(calls, "SubscribeOrderStatus") }).
+		Return((<-chan model.OrderStatusEvent)(statusCh), nil)
+	// paid between the lookup and the subscription, the snapshot is read again after it
+	orderRepo.On("GetOrder", mock.Anything, orderID, userID).
+		Run(func(mock.Arguments) { calls = append(calls, "GetOrder") }).
+		Return(&model.Order{ID: orderID, UserUUID: userID, Status: model.StatusPaid}, nil).Once()
+
+	ch, err := service.SubscribeOrderStatus(ctx, &dto.GetOrderStatusRequest{
+		UserUUID:  userID,
+		OrderUUID: orderID,
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"GetOrder", "SubscribeOrderStatus", "GetOrder"}, calls)
+
+	first := <-ch
+	assert.Equal(t, model.StatusPaid.ToString(), first.Status)
+
+	// already in the snapshot
+	statusCh <- model.OrderStatusEvent{ID: "1-0", OrderID: orderID, Status: model.StatusPaid, OccurredAt: time.Now()}
+	statusCh <- model.OrderStatusEvent{ID: "2-0", OrderID: orderID, Status: model.StatusClosed, OccurredAt: time.Now()}
+
+	second := <-ch
+	assert.Equal(t, model.StatusClosed.ToString(), second.Status)
+	assert.Equal(t, "2-0", second.EventID)
+
+	orderRepo.AssertExpectations(t)
+	subscriber.AssertExpectations(t)
+}
+
+func TestSubscribeOrderStatus_Resume(t *testing.T) {
+	service, orderRepo, userRepo, _, _, subscriber := preparingTests(t)
 
 	ctx := context.Background()
 	orderID := uuid.New()
 	userID := uuid.New()
 
-	statusCh := make(chan model.OrderStatus, 2)
+	statusCh := make(chan model.OrderStatusEvent, 1)
+
+	userRepo.On("GetUserById", mock.Anything, userID).
+		Return(&model.User{ID: userID, Roles: []string{model.RoleTrader}}, nil)
+	orderRepo.On("GetOrder", mock.Anything, orderID, userID).
+		Return(&model.Order{ID: orderID, UserUUID: userID, Status: model.StatusCreated}, nil).Once()
+	subscriber.On("SubscribeOrderStatus", mock.Anything, orderID, "1-0").
+		Return((<-chan model.OrderStatusEvent)(statusCh), nil)
+
+	ch, err := service.SubscribeOrderStatus(ctx, &dto.GetOrderStatusRequest{
+		UserUUID:    userID,
+		OrderUUID:   orderID,
+		ResumeToken: "1-0",
+	})
+	assert.Nil(t, err)
+
+	// no snapshot, the client already has it
+	statusCh <- model.OrderStatusEvent{ID: "2-0", OrderID: orderID, Status: model.StatusPaid, OccurredAt: time.Now()}
+
+	first := <-ch
+	assert.Equal(t, model.StatusPaid.ToString(), first.Status)
+	assert.Equal(t, "2-0", first.EventID)
+
+	orderRepo.AssertExpectations(t)
+	subscriber.AssertExpectations(t)
+}
+
+func TestCancelOrder_Success(t *testing.T) {
+	service, orderRepo, userRepo, _, _, _ := preparingTests(t)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	orderID := uuid.New()
 
 	order := &model.Order{
 		ID:       orderID,
 		UserUUID: userID,
-		Status:   model.StatusCreated,
+		Status:   model.StatusPaid,
 	}
 
-	orderRepo.On("GetOrder", mock.Anything, orderID).
+	userRepo.On("GetUserById", mock.Anything, userID).
+		Return(&model.User{ID: userID, Roles: []string{model.RoleTrader}}, nil)
+	orderRepo.On("GetOrder", mock.Anything, orderID, userID).
 		Return(order, nil)
+	orderRepo.On("CancelOrder", mock.Anything, orderID, mock.MatchedBy(func(c model.OrderCancellation) bool {
+		return c.Reason == model.CancelReasonUserRequested && c.Actor.Type == model.ActorUser && *c.Actor.ID == userID
+	})).
+		Return(nil)
 
-	subscriber.On("SubscribeOrderStatus", mock.Anything, orderID).
-		Return((<-chan model.OrderStatus)(statusCh), nil)
+	res, err := service.CancelOrder(ctx, &dto.CancelOrderRequest{
+		UserUUID:  userID,
+		OrderUUID: orderID,
+		Reason:    string(model.CancelReasonUserRequested),
+	})
 
-	ch, err := service.SubscribeOrderStatus(ctx, &dto.GetOrderStatusRequest{
+	assert.Nil(t, err)
+	assert.Equal(t, orderID, res.OrderUUID)
+	assert.Equal(t, model.StatusCancelled.ToString(), res.Status)
+	assert.Equal(t, userID, res.CancelledBy)
+
+	orderRepo.AssertExpectations(t)
+}
+
+func TestCancelOrder_InvalidReason(t *testing.T) {
+	service, orderRepo, _, _, _, _ := preparingTests(t)
+	ctx := context.Background()
+
+	res, err := service.CancelOrder(ctx, &dto.CancelOrderRequest{
+		UserUUID:  uuid.New(),
+		OrderUUID: uuid.New(),
+		Reason:    "BORED",
+	})
+
+	assert.Nil(t, res)
+	assert.Error(t, err)
+	assert.Equal(t, errors.ErrInvalidCancelReason.Message, err.Message)
+
+	orderRepo.AssertNotCalled(t, "CancelOrder", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCancelOrder_NotCancellable(t *testing.T) {
+	service, orderRepo, userRepo, _, _, _ := preparingTests(t)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	orderID := uuid.New()
+
+	order := &model.Order{
+		ID:       orderID,
+		UserUUID: userID,
+		Status:   model.StatusProcessing,
+	}
+
+	userRepo.On("GetUserById", mock.Anything, userID).
+		Return(&model.User{ID: userID, Roles: []string{model.RoleTrader}}, nil)
+	orderRepo.On("GetOrder", mock.Anything, orderID, userID).
+		Return(order, nil)
+
+	res, err := service.CancelOrder(ctx, &dto.CancelOrderRequest{
 		UserUUID:  userID,
 		OrderUUID: orderID,
+		Reason:    string(model.CancelReasonUserRequested),
 	})
 
+	assert.Nil(t, res)
+	assert.Error(t, err)
+	assert.Equal(t, errors.ErrOrderCannotBeCancelled.Message, err.Message)
+
+	orderRepo.AssertNotCalled(t, "CancelOrder", mock.Anything, mock.Anything, mock.Anything)
+	orderRepo.AssertExpectations(t)
+}
+
+func TestUpdateOrderStatus_Success(t *testing.T) {
+	service, orderRepo, _, _, _, _ := preparingTests(t)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	orderID := uuid.New()
+
+	order := &model.Order{
+		ID:       orderID,
+		UserUUID: userID,
+		Status:   model.StatusCreated,
+	}
+
+	orderRepo.On("GetOrder", mock.Anything, orderID, userID).
+		Return(order, nil)
+	orderRepo.On("UpdateOrderStatus", mock.Anything, orderID, mock.MatchedBy(func(update model.OrderStatusUpdate) bool {
+		return update.Status == model.StatusPending && update.Actor == model.SystemActor() && update.NextTransitionAt != nil
+	})).
+		Return(nil)
+
+	err := service.UpdateOrderStatus(ctx, userID, orderID, model.StatusPending, model.SystemActor())
+
 	assert.Nil(t, err)
 
-	first := <-ch
-	assert.Equal(t, model.StatusCreated.ToString(), first.Status)
+	orderRepo.AssertExpectations(t)
+}
 
-	statusCh <- model.StatusClosed
+func TestUpdateOrderStatus_IllegalTransition(t *testing.T) {
+	service, orderRepo, _, _, _, _ := preparingTests(t)
+	ctx := context.Background()
 
-	second := <-ch
+	userID := uuid.New()
+	orderID := uuid.New()
+
+	order := &model.Order{
+		ID:       orderID,
+		UserUUID: userID,
+		Status:   model.StatusCreated,
+	}
+
+	orderRepo.On("GetOrder", mock.Anything, orderID, userID).
+		Return(order, nil)
+
+	err := service.UpdateOrderStatus(ctx, userID, orderID, model.StatusDelivered, model.SystemActor())
+
+	assert.Error(t, err)
+	var transitionErr *model.TransitionError
+	assert.ErrorAs(t, err, &transitionErr)
+	assert.Equal(t, model.StatusCreated, transitionErr.From)
 
-	assert.Equal(t, model.StatusClosed.ToString(), second.Status)
 	orderRepo.AssertNotCalled(t, "UpdateOrderStatus", mock.Anything, mock.Anything, mock.Anything)
+	orderRepo.AssertExpectations(t)
+}
+
+func TestUpdateOrderStatus_ReturnWindowExpired(t *testing.T) {
+	service, orderRepo, _, _, _, _ := preparingTests(t)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	orderID := uuid.New()
+	deliveredAt := time.Now().Add(-model.ReturnWindow - time.Hour)
+
+	order := &model.Order{
+		ID:        orderID,
+		UserUUID:  userID,
+		Status:    model.StatusDelivered,
+		UpdatedAt: &deliveredAt,
+	}
+
+	orderRepo.On("GetOrder", mock.Anything, orderID, userID).
+		Return(order, nil)
+
+	err := service.UpdateOrderStatus(ctx, userID, orderID, model.StatusReturnRequested, model.UserActor(userID))
+
+	assert.Error(t, err)
+
+	orderRepo.AssertNotCalled(t, "UpdateOrderStatus", mock.Anything, mock.Anything, mock.Anything)
+	orderRepo.AssertExpectations(t)
+}
+
+func TestListOrders_NextCursor(t *testing.T) {
+	service, orderRepo, userRepo, _, _, _ := preparingTests(t)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	now := time.Now()
+
+	orders := []model.Order{
+		{ID: uuid.New(), UserUUID: userID, Status: model.StatusCreated, CreatedAt: new(now)},
+		{ID: uuid.New(), UserUUID: userID, Status: model.StatusPaid, CreatedAt: new(now.Add(-time.Minute))},
+		{ID: uuid.New(), UserUUID: userID, Status: model.StatusClosed, CreatedAt: new(now.Add(-time.Hour))},
+	}
+
+	userRepo.On("GetUserById", mock.Anything, userID).
+		Return(&model.User{ID: userID, Roles: []string{model.RoleTrader}}, nil)
+	orderRepo.On("ListOrders", mock.Anything, mock.MatchedBy(func(f model.OrderFilter) bool {
+		return f.UserUUID == userID && f.Limit == 3 && f.After == nil
+	})).
+		Return(orders, nil)
+
+	res, err := service.ListOrders(ctx, &dto.ListOrdersRequest{
+		UserUUID: userID,
+		Limit:    2,
+	})
+
+	assert.Nil(t, err)
+	assert.Len(t, res.Orders, 2)
+	assert.Equal(t, orders[0].ID, res.Orders[0].OrderUUID)
+
+	cursor, cursorErr := model.DecodeOrderCursor(res.NextCursor)
+	assert.NoError(t, cursorErr)
+	assert.Equal(t, orders[1].ID, cursor.ID)
+	assert.True(t, orders[1].CreatedAt.Equal(cursor.CreatedAt))
+
+	orderRepo.AssertExpectations(t)
+}
+
+func TestListOrders_LastPage(t *testing.T) {
+	service, orderRepo, userRepo, _, _, _ := preparingTests(t)
+	ctx := context.Background()
+
+	orders := []model.Order{
+		{ID: uuid.New(), Status: model.StatusCreated, CreatedAt: new(time.Now())},
+	}
+
+	userRepo.On("GetUserById", mock.Anything, uuid.Nil).
+		Return(&model.User{Roles: []string{model.RoleTrader}}, nil)
+	orderRepo.On("ListOrders", mock.Anything, mock.Anything).
+		Return(orders, nil)
+
+	res, err := service.ListOrders(ctx, &dto.ListOrdersRequest{
+		Statuses: []string{string(model.StatusCreated)},
+	})
+
+	assert.Nil(t, err)
+	assert.Len(t, res.Orders, 1)
+	assert.Empty(t, res.NextCursor)
+
+	orderRepo.AssertExpectations(t)
+}
+
+func TestListOrders_InvalidFilter(t *testing.T) {
+	service, orderRepo, _, _, _, _ := preparingTests(t)
+	ctx := context.Background()
+
+	res, err := service.ListOrders(ctx, &dto.ListOrdersRequest{
+		Statuses: []string{"TELEPORTED"},
+	})
+
+	assert.Nil(t, res)
+	assert.Error(t, err)
+
+	res, err = service.ListOrders(ctx, &dto.ListOrdersRequest{
+		Cursor: "not-a-cursor",
+	})
+
+	assert.Nil(t, res)
+	assert.Equal(t, errors.ErrInvalidOrderCursor.Message, err.Message)
+
+	orderRepo.AssertNotCalled(t, "ListOrders", mock.Anything, mock.Anything)
+}
+
+func TestGetOrderHistory_Success(t *testing.T) {
+	service, orderRepo, userRepo, _, _, _ := preparingTests(t)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	orderID := uuid.New()
+	now := time.Now()
+	created := model.StatusCreated
+
+	order := &model.Order{
+		ID:       orderID,
+		UserUUID: userID,
+		Status:   model.StatusPending,
+	}
+	history := []model.OrderStatusChange{
+		model.NewOrderStatusChange(orderID, nil, model.StatusCreated, model.UserActor(userID), now.Add(-time.Minute)),
+		model.NewOrderStatusChange(orderID, &created, model.StatusPending, model.SystemActor(), now),
+	}
+
+	userRepo.On("GetUserById", mock.Anything, userID).
+		Return(&model.User{ID: userID, Roles: []string{model.RoleTrader}}, nil)
+	orderRepo.On("GetOrder", mock.Anything, orderID, userID).
+		Return(order, nil)
+	orderRepo.On("GetOrderHistory", mock.Anything, orderID).
+		Return(history, nil)
+
+	res, err := service.GetOrderHistory(ctx, &dto.GetOrderStatusRequest{
+		UserUUID:  userID,
+		OrderUUID: orderID,
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, orderID, res.OrderUUID)
+	assert.Len(t, res.History, 2)
+	assert.Empty(t, res.History[0].FromStatus)
+	assert.Equal(t, string(model.ActorUser), res.History[0].ActorType)
+	assert.Equal(t, model.StatusCreated.ToString(), res.History[1].FromStatus)
+	assert.Equal(t, model.StatusPending.ToString(), res.History[1].ToStatus)
+	assert.Nil(t, res.History[1].ActorUUID)
+
+	orderRepo.AssertExpectations(t)
+}
+
+func TestGetOrderHistory_OrderNotFound(t *testing.T) {
+	service, orderRepo, userRepo, _, _, _ := preparingTests(t)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	orderID := uuid.New()
+
+	userRepo.On("GetUserById", mock.Anything, userID).
+		Return(&model.User{ID: userID, Roles: []string{model.RoleTrader}}, nil)
+	orderRepo.On("GetOrder", mock.Anything, orderID, userID).
+		Return(nil, errors.ErrOrderNotFound)
+
+	res, err := service.GetOrderHistory(ctx, &dto.GetOrderStatusRequest{
+		UserUUID:  userID,
+		OrderUUID: orderID,
+	})
+
+	assert.Nil(t, res)
+	assert.Equal(t, errors.ErrOrderNotFound.Message, err.Message)
+
+	orderRepo.AssertNotCalled(t, "GetOrderHistory", mock.Anything, mock.Anything)
+}
+
+func TestCreateOrder_IdempotencyKey(t *testing.T) {
+	service, orderRepo, userRepo, cache, _, _ := preparingTests(t)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	marketID := uuid.New()
+	user := &model.User{ID: userID, Roles: []string{model.RoleTrader}}
+	original := &model.Order{ID: uuid.New(), Status: model.StatusCreated, UserUUID: userID}
+
+	// the repository keeps the key of the first request, later ones are replayed or
+	// rejected for a different payload before the market is checked again
+	var stored *model.IdempotencyKey
+
+	userRepo.On("GetUserById", mock.Anything, userID).
+		Return(user, nil)
+	cache.On("GetOrLoad", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return([]dto.ViewMarketsResponse{{UUID: marketID, Enabled: true}}, nil).
+		Once()
+	orderRepo.On("GetIdempotentOrder", mock.Anything, mock.Anything).
+		Return(func(_ context.Context, key *model.IdempotencyKey) (*model.Order, *errs.CustomError) {
+			switch {
+			case stored == nil:
+				return nil, errors.ErrOrderNotFound
+			case key.RequestHash != stored.RequestHash:
+				return nil, errors.ErrIdempotencyKeyConflict
+			}
+			return original, nil
+		})
+	orderRepo.On("CreateOrder", mock.Anything, mock.Anything, mock.Anything).
+		Return(func(_ context.Context, _ *model.Order, key *model.IdempotencyKey) (*model.Order, *errs.CustomError) {
+			stored = key
+			return original, nil
+		}).
+		Once()
+
+	request := dto.CreateOrderRequest{
+		MarketUUID:     marketID,
+		UserUUID:       userID,
+		OrderType:      "Test_type",
+		Price:          decimal.NewFromInt(120),
+		UserRole:       model.RoleTrader,
+		Quantity:       1,
+		IdempotencyKey: "retry-1",
+	}
+
+	created, err := service.CreateOrder(ctx, &request)
+	assert.Nil(t, err)
+	assert.Equal(t, original.ID, created.OrderUUID)
+
+	t.Run("retry replays the original order", func(t *testing.T) {
+		retry := request
+
+		replayed, err := service.CreateOrder(ctx, &retry)
+
+		assert.Nil(t, err)
+		assert.Equal(t, created.OrderUUID, replayed.OrderUUID)
+	})
+
+	t.Run("retry without the role replays the original order", func(t *testing.T) {
+		retry := request
+		retry.UserRole = ""
+
+		replayed, err := service.CreateOrder(ctx, &retry)
+
+		assert.Nil(t, err)
+		assert.Equal(t, created.OrderUUID, replayed.OrderUUID)
+	})
+
+	t.Run("different payload conflicts", func(t *testing.T) {
+		changed := request
+		changed.Quantity = 2
+
+		res, err := service.CreateOrder(ctx, &changed)
+
+		assert.Nil(t, res)
+		assert.Equal(t, errors.ErrIdempotencyKeyConflict, err)
+	})
+
+	assert.Equal(t, "retry-1", stored.Key)
+	assert.Equal(t, userID, stored.UserID)
+}
+
+func TestCreateOrder_RetryReplayedAfterMarketDisabled(t *testing.T) {
+	// the market cache has no expectations, the market is not checked again
+	service, orderRepo, userRepo, _, _, _ := preparingTests(t)
+
+	userID := uuid.New()
+	original := &model.Order{ID: uuid.New(), Status: model.StatusPaid, UserUUID: userID}
+
+	userRepo.On("GetUserById", mock.Anything, userID).
+		Return(&model.User{ID: userID, Roles: []string{model.RoleTrader}}, nil)
+	orderRepo.On("GetIdempotentOrder", mock.Anything, mock.MatchedBy(func(key *model.IdempotencyKey) bool {
+		return key.UserID == userID && key.Key == "retry-1"
+	})).
+		Return(original, nil)
+
+	res, err := service.CreateOrder(context.Background(), &dto.CreateOrderRequest{
+		MarketUUID:     uuid.New(),
+		UserUUID:       userID,
+		OrderType:      "Test_type",
+		Price:          decimal.NewFromInt(120),
+		Quantity:       1,
+		IdempotencyKey: "retry-1",
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, original.ID, res.OrderUUID)
+	assert.Equal(t, model.StatusPaid.ToString(), res.Status)
+	orderRepo.AssertNotCalled(t, "CreateOrder", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCreateOrder_MarketNotTradable(t *testing.T) {
+	deletedAt := time.Now()
+	enabled := uuid.New()
+	disabled := uuid.New()
+	deleted := uuid.New()
+
+	markets := []dto.ViewMarketsResponse{
+		{UUID: enabled, Enabled: true},
+		{UUID: disabled, Enabled: false},
+		{UUID: deleted, Enabled: true, DeletedAt: &deletedAt},
+	}
+
+	tests := []struct {
+		name     string
+		marketID uuid.UUID
+		want     *errs.CustomError
+	}{
+		{name: "unknown", marketID: uuid.New(), want: errors.ErrMarketNotFound},
+		{name: "disabled", marketID: disabled, want: errors.ErrMarketDisabled},
+		{name: "deleted", marketID: deleted, want: errors.ErrMarketDeleted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, orderRepo, userRepo, cache, _, _ := preparingTests(t)
+
+			userID := uuid.New()
+			userRepo.On("GetUserById", mock.Anything, userID).
+				Return(&model.User{ID: userID, Roles: []string{model.RoleTrader}}, nil)
+			cache.On("GetOrLoad", mock.Anything, "markets:role:"+model.RoleTrader, mock.Anything, mock.Anything).
+				Return(markets, nil)
+
+			res, err := service.CreateOrder(context.Background(), &dto.CreateOrderRequest{
+				MarketUUID: tt.marketID,
+				UserUUID:   userID,
+				OrderType:  "Test_type",
+				Price:      decimal.NewFromInt(120),
+				Quantity:   1,
+			})
+
+			assert.Nil(t, res)
+			assert.Equal(t, tt.want.Message, err.Message)
+			orderRepo.AssertNotCalled(t, "CreateOrder", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestCreateOrder_InvalidIdempotencyKey(t *testing.T) {
+	service, _, _, _, _, _ := preparingTests(t)
+	ctx := context.Background()
+
+	res, err := service.CreateOrder(ctx, &dto.CreateOrderRequest{
+		UserUUID:       uuid.New(),
+		IdempotencyKey: strings.Repeat("k", 256),
+	})
+
+	assert.Nil(t, res)
+	assert.Equal(t, errors.ErrInvalidIdempotencyKey.Message, err.Message)
+}
+
+func TestGetOrderStatus_ByClientOrderID(t *testing.T) {
+	service, orderRepo, userRepo, _, _, _ := preparingTests(t)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	now := time.Now()
+
+	order := &model.Order{
+		ID:            uuid.New(),
+		UserUUID:      userID,
+		Status:        model.StatusPaid,
+		UpdatedAt:     &now,
+		ClientOrderID: new("bot-42"),
+	}
+
+	userRepo.On("GetUserById", mock.Anything, userID).
+		Return(&model.User{ID: userID, Roles: []string{model.RoleTrader}}, nil)
+	orderRepo.On("GetOrderByClientOrderID", mock.Anything, userID, "bot-42").
+		Return(order, nil)
+
+	res, err := service.GetOrderStatus(ctx, &dto.GetOrderStatusRequest{
+		UserUUID:      userID,
+		ClientOrderID: "bot-42",
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, model.StatusPaid.ToString(), res.Status)
+
+	orderRepo.AssertNotCalled(t, "GetOrder", mock.Anything, mock.Anything, mock.Anything)
+	orderRepo.AssertExpectations(t)
+}
+
+func TestGetOrderStatus_AdminViewsOtherUsersOrder(t *testing.T) {
+	service, orderRepo, userRepo, _, _, _ := preparingTests(t)
+	ctx := context.Background()
+
+	adminID := uuid.New()
+	ownerID := uuid.New()
+	orderID := uuid.New()
+
+	userRepo.On("GetUserById", mock.Anything, adminID).
+		Return(&model.User{ID: adminID, Roles: []string{model.RoleAdmin}}, nil)
+	orderRepo.On("GetOrder", mock.Anything, orderID, ownerID).
+		Return(&model.Order{ID: orderID, UserUUID: ownerID, Status: model.StatusPaid}, nil)
+
+	res, err := service.GetOrderStatus(ctx, &dto.GetOrderStatusRequest{
+		RequesterUUID: adminID,
+		UserUUID:      ownerID,
+		OrderUUID:     orderID,
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, string(model.StatusPaid), res.Status)
+
+	orderRepo.AssertExpectations(t)
+	userRepo.AssertExpectations(t)
+}
+
+func TestGetOrderStatus_TraderCannotViewOtherUsersOrder(t *testing.T) {
+	service, orderRepo, userRepo, _, _, _ := preparingTests(t)
+	ctx := context.Background()
+
+	traderID := uuid.New()
+	ownerID := uuid.New()
+
+	userRepo.On("GetUserById", mock.Anything, traderID).
+		Return(&model.User{ID: traderID, Roles: []string{model.RoleTrader}}, nil)
+
+	res, err := service.GetOrderStatus(ctx, &dto.GetOrderStatusRequest{
+		RequesterUUID: traderID,
+		UserUUID:      ownerID,
+		OrderUUID:     uuid.New(),
+	})
+
+	assert.Nil(t, res)
+	assert.Equal(t, errors.ErrPermissionDenied.Message, err.Message)
+
+	orderRepo.AssertNotCalled(t, "GetOrder", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestForceOrderStatus_Admin(t *testing.T) {
+	service, orderRepo, userRepo, _, _, _ := preparingTests(t)
+	ctx := context.Background()
+
+	adminID := uuid.New()
+	orderID := uuid.New()
+
+	userRepo.On("GetUserById", mock.Anything, adminID).
+		Return(&model.User{ID: adminID, Roles: []string{model.RoleAdmin}}, nil)
+	orderRepo.On("GetOrderByID", mock.Anything, orderID).
+		Return(&model.Order{ID: orderID, UserUUID: uuid.New(), Status: model.StatusPacked}, nil)
+	orderRepo.On("UpdateOrderStatus", mock.Anything, orderID, mock.MatchedBy(func(u model.OrderStatusUpdate) bool {
+		return u.Status == model.StatusOutOfDelivery && u.Actor.Type == model.ActorAdmin && *u.Actor.ID == adminID
+	})).
+		Return(nil)
+
+	res, err := service.ForceOrderStatus(ctx, &dto.ForceOrderStatusRequest{
+		RequesterUUID: adminID,
+		OrderUUID:     orderID,
+		Status:        string(model.StatusOutOfDelivery),
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, string(model.StatusOutOfDelivery), res.Status)
 
 	orderRepo.AssertExpectations(t)
-	subscriber.AssertExpectations(t)
 }
@@ -0,0 +1,213 @@
+package order
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"OrderService/config"
+	"OrderService/internal/errors"
+	"OrderService/internal/model"
+	"OrderService/internal/service/outbox"
+	"OrderService/mocks"
+
+	errs "github.com/erdedan1/shared/errs"
+	log "github.com/erdedan1/shared/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func preparingRelayTests(t *testing.T) (*outbox.Relay, *mocks.OutboxRepo, *mocks.OrderStatusPublisher) {
+	repo := mocks.NewOutboxRepo(t)
+	publisher := mocks.NewOrderStatusPublisher(t)
+
+	logger, _ := log.NewLogger("debug")
+	defer logger.Sync()
+
+	relay := outbox.NewRelay(
+		repo,
+		publisher,
+		logger,
+		noop.NewTracerProvider(),
+		config.OutboxConfig{
+			BatchSize:   10,
+			ClaimLease:  time.Minute,
+			BaseBackoff: time.Second,
+			MaxBackoff:  time.Minute,
+			MaxAttempts: 5,
+		},
+	)
+	return relay, repo, publisher
+}
+
+func TestRelayPending_Delivered(t *testing.T) {
+	relay, repo, publisher := preparingRelayTests(t)
+	ctx := context.Background()
+
+	orderID := uuid.New()
+	occurredAt := time.Now().Add(-time.Minute).UTC()
+	event, err := model.NewOrderStatusOutboxEvent(orderID, model.StatusPending, occurredAt)
+	assert.NoError(t, err)
+	event.ID = 1
+
+	repo.On("ClaimPending", mock.Anything, 10, time.Minute).
+		Return([]model.OutboxEvent{event}, nil)
+	publisher.On("PublishOrderStatus", mock.Anything, orderID, model.StatusPending, mock.MatchedBy(occurredAt.Equal)).
+		Return(nil)
+	repo.On("MarkDelivered", mock.Anything, int64(1)).
+		Return(nil)
+
+	claimed, relayErr := relay.RelayPending(ctx)
+
+	assert.Nil(t, relayErr)
+	assert.Equal(t, 1, claimed)
+}
+
+func TestRelayPending_PublishFailed(t *testing.T) {
+	relay, repo, publisher := preparingRelayTests(t)
+	ctx := context.Background()
+
+	orderID := uuid.New()
+	occurredAt := time.Now().Add(-time.Minute).UTC()
+	event, err := model.NewOrderStatusOutboxEvent(orderID, model.StatusPending, occurredAt)
+	assert.NoError(t, err)
+	event.ID = 1
+	event.Attempts = 2
+
+	repo.On("ClaimPending", mock.Anything, 10, time.Minute).
+		Return([]model.OutboxEvent{event}, nil)
+	publisher.On("PublishOrderStatus", mock.Anything, orderID, model.StatusPending, mock.MatchedBy(occurredAt.Equal)).
+		Return(errors.ErrUnavailableRedis)
+	repo.On("MarkFailed", mock.Anything, int64(1), mock.MatchedBy(func(at time.Time) bool {
+		delay := time.Until(at)
+		return delay > 3*time.Second && delay <= 4*time.Second
+	}), errors.ErrUnavailableRedis.Error()).
+		Return(nil)
+
+	claimed, relayErr := relay.RelayPending(ctx)
+
+	assert.Nil(t, relayErr)
+	assert.Equal(t, 1, claimed)
+}
+
+func TestRelayPending_InvalidEventDeadLettered(t *testing.T) {
+	tests := []struct {
+		name  string
+		event model.OutboxEvent
+	}{
+		{name: "malformed payload", event: model.OutboxEvent{ID: 1, EventType: model.EventOrderStatusChanged, Payload: []byte("{")}},
+		{name: "unknown event type", event: model.OutboxEvent{ID: 1, EventType: "order.unknown", Payload: []byte("{}")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the publisher has no expectations, the event is never published
+			relay, repo, _ := preparingRelayTests(t)
+
+			repo.On("ClaimPending", mock.Anything, 10, time.Minute).
+				Return([]model.OutboxEvent{tt.event}, nil)
+			repo.On("MarkDeadLettered", mock.Anything, int64(1), errors.ErrInvalidOutboxEvent.Error()).
+				Return(nil)
+
+			claimed, relayErr := relay.RelayPending(context.Background())
+
+			assert.Nil(t, relayErr)
+			assert.Equal(t, 1, claimed)
+		})
+	}
+}
+
+func TestRelayPending_LastAttemptDeadLettered(t *testing.T) {
+	relay, repo, publisher := preparingRelayTests(t)
+	ctx := context.Background()
+
+	orderID := uuid.New()
+	occurredAt := time.Now().Add(-time.Minute).UTC()
+	event, err := model.NewOrderStatusOutboxEvent(orderID, model.StatusPending, occurredAt)
+	assert.NoError(t, err)
+	event.ID = 1
+	event.Attempts = 4
+
+	repo.On("ClaimPending", mock.Anything, 10, time.Minute).
+		Return([]model.OutboxEvent{event}, nil)
+	publisher.On("PublishOrderStatus", mock.Anything, orderID, model.StatusPending, mock.MatchedBy(occurredAt.Equal)).
+		Return(errors.ErrUnavailableRedis)
+	repo.On("MarkDeadLettered", mock.Anything, int64(1), errors.ErrUnavailableRedis.Error()).
+		Return(nil)
+
+	claimed, relayErr := relay.RelayPending(ctx)
+
+	assert.Nil(t, relayErr)
+	assert.Equal(t, 1, claimed)
+}
+
+func TestOutboxSweeper_DeletesDeliveredAfterRetention(t *testing.T) {
+	repo := mocks.NewOutboxRepo(t)
+	logger, _ := log.NewLogger("debug")
+
+	sweeper := outbox.NewSweeper(repo, logger, noop.NewTracerProvider(), config.OutboxConfig{Retention: time.Hour})
+
+	repo.On("DeleteDelivered", mock.Anything, mock.MatchedBy(func(before time.Time) bool {
+		return time.Since(before) >= time.Hour && time.Since(before) < time.Hour+time.Minute
+	})).
+		Return(int64(3), nil)
+
+	deleted, err := sweeper.Sweep(context.Background())
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), deleted)
+}
+
+func TestOutboxSweeper_RepoFailed(t *testing.T) {
+	repo := mocks.NewOutboxRepo(t)
+	logger, _ := log.NewLogger("debug")
+
+	sweeper := outbox.NewSweeper(repo, logger, noop.NewTracerProvider(), config.OutboxConfig{Retention: time.Hour})
+
+	failed := errs.New(errs.INTERNAL, "failed to delete delivered outbox events")
+	repo.On("DeleteDelivered", mock.Anything, mock.Anything).
+		Return(int64(0), failed)
+
+	deleted, err := sweeper.Sweep(context.Background())
+
+	assert.Equal(t, failed, err)
+	assert.Zero(t, deleted)
+}
+
+func TestOutboxSweeper_RunStopsOnCancel(t *testing.T) {
+	repo := mocks.NewOutboxRepo(t)
+	logger, _ := log.NewLogger("debug")
+
+	sweeper := outbox.NewSweeper(repo, logger, noop.NewTracerProvider(), config.OutboxConfig{
+		Retention:     time.Hour,
+		SweepInterval: time.Millisecond,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	swept := make(chan struct{}, 1)
+	repo.On("DeleteDelivered", mock.Anything, mock.Anything).
+		Run(func(mock.Arguments) {
+			select {
+			case swept <- struct{}{}:
+			default:
+			}
+		}).
+		Return(int64(0), nil)
+
+	done := make(chan struct{})
+	go func() {
+		sweeper.Run(ctx)
+		close(done)
+	}()
+
+	<-swept
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("sweeper did not stop")
+	}
+}
@@ -13,15 +13,38 @@ import (
 
 //go:generate mockery --name=OrderRepo --output=../../mocks --outpkg=mocks
 type OrderRepo interface {
-	CreateOrder(ctx context.Context, order *model.Order) (*model.Order, *errors.CustomError)
+	CreateOrder(ctx context.Context, order *model.Order, key *model.IdempotencyKey) (*model.Order, *errors.CustomError)
 	GetOrder(ctx context.Context, orderID, userID uuid.UUID) (*model.Order, *errors.CustomError)
-	UpdateOrderStatus(ctx context.Context, id uuid.UUID, order model.OrderStatus) *errors.CustomError
+	GetOrderByID(ctx context.Context, orderID uuid.UUID) (*model.Order, *errors.CustomError)
+	GetOrderByClientOrderID(ctx context.Context, userID uuid.UUID, clientOrderID string) (*model.Order, *errors.CustomError)
+	GetIdempotentOrder(ctx context.Context, key *model.IdempotencyKey) (*model.Order, *errors.CustomError)
+	UpdateOrderStatus(ctx context.Context, id uuid.UUID, update model.OrderStatusUpdate) *errors.CustomError
+	CancelOrder(ctx context.Context, id uuid.UUID, cancellation model.OrderCancellation) *errors.CustomError
+	ListOrders(ctx context.Context, filter model.OrderFilter) ([]model.Order, *errors.CustomError)
+	GetOrderHistory(ctx context.Context, orderID uuid.UUID) ([]model.OrderStatusChange, *errors.CustomError)
+	ClaimDueOrders(ctx context.Context, limit int, lease time.Duration) ([]model.Order, *errors.CustomError)
+}
+
+//go:generate mockery --name=IdempotencyKeyRepo --output=../../mocks --outpkg=mocks
+type IdempotencyKeyRepo interface {
+	DeleteExpiredIdempotencyKeys(ctx context.Context, before time.Time) (int64, *errors.CustomError)
+}
+
+//go:generate mockery --name=OutboxRepo --output=../../mocks --outpkg=mocks
+type OutboxRepo interface {
+	ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]model.OutboxEvent, *errors.CustomError)
+	MarkDelivered(ctx context.Context, id int64) *errors.CustomError
+	MarkFailed(ctx context.Context, id int64, nextAttemptAt time.Time, reason string) *errors.CustomError
+	MarkDeadLettered(ctx context.Context, id int64, reason string) *errors.CustomError
+	DeleteDelivered(ctx context.Context, before time.Time) (int64, *errors.CustomError)
 }
 
 //go:generate mockery --name=UserRepo --output=../../mocks --outpkg=mocks
 type UserRepo interface {
-	CreateUser(ctx context.Context, user model.User)
+	CreateUser(ctx context.Context, user model.User) *errors.CustomError
 	GetUserById(ctx context.Context, id uuid.UUID) (*model.User, *errors.CustomError)
+	UpdateUserRoles(ctx context.Context, id uuid.UUID, roles []string) *errors.CustomError
+	DeactivateUser(ctx context.Context, id uuid.UUID, at time.Time) *errors.CustomError
 }
 
 //go:generate mockery --name=MarketCacheRepo --output=../../mocks --outpkg=mocks
@@ -29,14 +52,22 @@ type MarketCacheRepo interface {
 	Set(ctx context.Context, key string, value []dto.ViewMarketsResponse, ttl time.Duration) *errors.CustomError
 	Get(ctx context.Context, key string) ([]dto.ViewMarketsResponse, *errors.CustomError)
 	Del(ctx context.Context, key string) *errors.CustomError
+	// GetOrLoad returns the markets of key, on a miss it loads them with load and sets them for ttl.
+	GetOrLoad(ctx context.Context, key string, ttl time.Duration, load MarketsLoader) ([]dto.ViewMarketsResponse, *errors.CustomError)
 }
 
+// MarketsLoader fetches markets missing from the cache.
+type MarketsLoader func(ctx context.Context) ([]dto.ViewMarketsResponse, *errors.CustomError)
+
 //go:generate mockery --name=OrderStatusSubscriber --output=../../mocks --outpkg=mocks
 type OrderStatusSubscriber interface {
-	SubscribeOrderStatus(ctx context.Context, orderID uuid.UUID) (<-chan model.OrderStatus, *errors.CustomError)
+	// SubscribeOrderStatus streams status events of the order. A non-empty afterID
+	// resumes right after the event with that ID, or after the events that occurred by
+	// the time it holds in RFC 3339.
+	SubscribeOrderStatus(ctx context.Context, orderID uuid.UUID, afterID string) (<-chan model.OrderStatusEvent, *errors.CustomError)
 }
 
 //go:generate mockery --name=OrderStatusPublisher --output=../../mocks --outpkg=mocks
 type OrderStatusPublisher interface {
-	PublishOrderStatus(ctx context.Context, orderID uuid.UUID, status model.OrderStatus) *errors.CustomError
+	PublishOrderStatus(ctx context.Context, orderID uuid.UUID, status model.OrderStatus, occurredAt time.Time) *errors.CustomError
 }
@@ -13,4 +13,8 @@ type OrderService interface {
 	CreateOrder(ctx context.Context, request *dto.CreateOrderRequest) (*dto.CreateOrderResponse, *errors.CustomError)
 	GetOrderStatus(ctx context.Context, request *dto.GetOrderStatusRequest) (*dto.GetOrderStatusResponse, *errors.CustomError)
 	SubscribeOrderStatus(ctx context.Context, request *dto.GetOrderStatusRequest) (<-chan *dto.GetOrderStatusResponse, *errors.CustomError)
+	CancelOrder(ctx context.Context, request *dto.CancelOrderRequest) (*dto.CancelOrderResponse, *errors.CustomError)
+	ListOrders(ctx context.Context, request *dto.ListOrdersRequest) (*dto.ListOrdersResponse, *errors.CustomError)
+	GetOrderHistory(ctx context.Context, request *dto.GetOrderStatusRequest) (*dto.GetOrderHistoryResponse, *errors.CustomError)
+	ForceOrderStatus(ctx context.Context, request *dto.ForceOrderStatusRequest) (*dto.GetOrderStatusResponse, *errors.CustomError)
 }
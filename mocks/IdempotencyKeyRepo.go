@@ -0,0 +1,61 @@
+// Code generated by mockery v2.53.6. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	errs "github.com/erdedan1/shared/errs"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// IdempotencyKeyRepo is an autogenerated mock type for the IdempotencyKeyRepo type
+type IdempotencyKeyRepo struct {
+	mock.Mock
+}
+
+// DeleteExpiredIdempotencyKeys provides a mock function with given fields: ctx, before
+func (_m *IdempotencyKeyRepo) DeleteExpiredIdempotencyKeys(ctx context.Context, before time.Time) (int64, *errs.CustomError) {
+	ret := _m.Called(ctx, before)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteExpiredIdempotencyKeys")
+	}
+
+	var r0 int64
+	var r1 *errs.CustomError
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) (int64, *errs.CustomError)); ok {
+		return rf(ctx, before)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int64); ok {
+		r0 = rf(ctx, before)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) *errs.CustomError); ok {
+		r1 = rf(ctx, before)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*errs.CustomError)
+		}
+	}
+
+	return r0, r1
+}
+
+// NewIdempotencyKeyRepo creates a new instance of IdempotencyKeyRepo. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIdempotencyKeyRepo(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IdempotencyKeyRepo {
+	mock := &IdempotencyKeyRepo{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -11,6 +11,8 @@ import (
 	mock "github.com/stretchr/testify/mock"
 
 	time "time"
+
+	usecase "OrderService/internal/usecase"
 )
 
 // MarketCacheRepo is an autogenerated mock type for the MarketCacheRepo type
@@ -70,6 +72,38 @@ func (_m *MarketCacheRepo) Get(ctx context.Context, key string) ([]dto.ViewMarke
 	return r0, r1
 }
 
+// GetOrLoad provides a mock function with given fields: ctx, key, ttl, load
+func (_m *MarketCacheRepo) GetOrLoad(ctx context.Context, key string, ttl time.Duration, load usecase.MarketsLoader) ([]dto.ViewMarketsResponse, *errs.CustomError) {
+	ret := _m.Called(ctx, key, ttl, load)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOrLoad")
+	}
+
+	var r0 []dto.ViewMarketsResponse
+	var r1 *errs.CustomError
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Duration, usecase.MarketsLoader) ([]dto.ViewMarketsResponse, *errs.CustomError)); ok {
+		return rf(ctx, key, ttl, load)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Duration, usecase.MarketsLoader) []dto.ViewMarketsResponse); ok {
+		r0 = rf(ctx, key, ttl, load)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]dto.ViewMarketsResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Duration, usecase.MarketsLoader) *errs.CustomError); ok {
+		r1 = rf(ctx, key, ttl, load)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*errs.CustomError)
+		}
+	}
+
+	return r0, r1
+}
+
 // Set provides a mock function with given fields: ctx, key, value, ttl
 func (_m *MarketCacheRepo) Set(ctx context.Context, key string, value []dto.ViewMarketsResponse, ttl time.Duration) *errs.CustomError {
 	ret := _m.Called(ctx, key, value, ttl)
@@ -10,6 +10,8 @@ import (
 
 	model "OrderService/internal/model"
 
+	time "time"
+
 	uuid "github.com/google/uuid"
 )
 
@@ -18,9 +20,61 @@ type OrderRepo struct {
 	mock.Mock
 }
 
-// CreateOrder provides a mock function with given fields: ctx, order
-func (_m *OrderRepo) CreateOrder(ctx context.Context, order *model.Order) (*model.Order, *errs.CustomError) {
-	ret := _m.Called(ctx, order)
+// CancelOrder provides a mock function with given fields: ctx, id, cancellation
+func (_m *OrderRepo) CancelOrder(ctx context.Context, id uuid.UUID, cancellation model.OrderCancellation) *errs.CustomError {
+	ret := _m.Called(ctx, id, cancellation)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CancelOrder")
+	}
+
+	var r0 *errs.CustomError
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, model.OrderCancellation) *errs.CustomError); ok {
+		r0 = rf(ctx, id, cancellation)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*errs.CustomError)
+		}
+	}
+
+	return r0
+}
+
+// ClaimDueOrders provides a mock function with given fields: ctx, limit, lease
+func (_m *OrderRepo) ClaimDueOrders(ctx context.Context, limit int, lease time.Duration) ([]model.Order, *errs.CustomError) {
+	ret := _m.Called(ctx, limit, lease)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ClaimDueOrders")
+	}
+
+	var r0 []model.Order
+	var r1 *errs.CustomError
+	if rf, ok := ret.Get(0).(func(context.Context, int, time.Duration) ([]model.Order, *errs.CustomError)); ok {
+		return rf(ctx, limit, lease)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, time.Duration) []model.Order); ok {
+		r0 = rf(ctx, limit, lease)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Order)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, time.Duration) *errs.CustomError); ok {
+		r1 = rf(ctx, limit, lease)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*errs.CustomError)
+		}
+	}
+
+	return r0, r1
+}
+
+// CreateOrder provides a mock function with given fields: ctx, order, key
+func (_m *OrderRepo) CreateOrder(ctx context.Context, order *model.Order, key *model.IdempotencyKey) (*model.Order, *errs.CustomError) {
+	ret := _m.Called(ctx, order, key)
 
 	if len(ret) == 0 {
 		panic("no return value specified for CreateOrder")
@@ -28,19 +82,51 @@ func (_m *OrderRepo) CreateOrder(ctx context.Context, order *model.Order) (*mode
 
 	var r0 *model.Order
 	var r1 *errs.CustomError
-	if rf, ok := ret.Get(0).(func(context.Context, *model.Order) (*model.Order, *errs.CustomError)); ok {
-		return rf(ctx, order)
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Order, *model.IdempotencyKey) (*model.Order, *errs.CustomError)); ok {
+		return rf(ctx, order, key)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, *model.Order) *model.Order); ok {
-		r0 = rf(ctx, order)
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Order, *model.IdempotencyKey) *model.Order); ok {
+		r0 = rf(ctx, order, key)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*model.Order)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, *model.Order) *errs.CustomError); ok {
-		r1 = rf(ctx, order)
+	if rf, ok := ret.Get(1).(func(context.Context, *model.Order, *model.IdempotencyKey) *errs.CustomError); ok {
+		r1 = rf(ctx, order, key)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*errs.CustomError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetIdempotentOrder provides a mock function with given fields: ctx, key
+func (_m *OrderRepo) GetIdempotentOrder(ctx context.Context, key *model.IdempotencyKey) (*model.Order, *errs.CustomError) {
+	ret := _m.Called(ctx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetIdempotentOrder")
+	}
+
+	var r0 *model.Order
+	var r1 *errs.CustomError
+	if rf, ok := ret.Get(0).(func(context.Context, *model.IdempotencyKey) (*model.Order, *errs.CustomError)); ok {
+		return rf(ctx, key)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *model.IdempotencyKey) *model.Order); ok {
+		r0 = rf(ctx, key)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Order)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *model.IdempotencyKey) *errs.CustomError); ok {
+		r1 = rf(ctx, key)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(*errs.CustomError)
@@ -82,17 +168,145 @@ func (_m *OrderRepo) GetOrder(ctx context.Context, orderID uuid.UUID, userID uui
 	return r0, r1
 }
 
-// UpdateOrderStatus provides a mock function with given fields: ctx, id, order
-func (_m *OrderRepo) UpdateOrderStatus(ctx context.Context, id uuid.UUID, order model.OrderStatus) *errs.CustomError {
-	ret := _m.Called(ctx, id, order)
+// GetOrderByClientOrderID provides a mock function with given fields: ctx, userID, clientOrderID
+func (_m *OrderRepo) GetOrderByClientOrderID(ctx context.Context, userID uuid.UUID, clientOrderID string) (*model.Order, *errs.CustomError) {
+	ret := _m.Called(ctx, userID, clientOrderID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOrderByClientOrderID")
+	}
+
+	var r0 *model.Order
+	var r1 *errs.CustomError
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string) (*model.Order, *errs.CustomError)); ok {
+		return rf(ctx, userID, clientOrderID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string) *model.Order); ok {
+		r0 = rf(ctx, userID, clientOrderID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Order)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, string) *errs.CustomError); ok {
+		r1 = rf(ctx, userID, clientOrderID)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*errs.CustomError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetOrderByID provides a mock function with given fields: ctx, orderID
+func (_m *OrderRepo) GetOrderByID(ctx context.Context, orderID uuid.UUID) (*model.Order, *errs.CustomError) {
+	ret := _m.Called(ctx, orderID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOrderByID")
+	}
+
+	var r0 *model.Order
+	var r1 *errs.CustomError
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) (*model.Order, *errs.CustomError)); ok {
+		return rf(ctx, orderID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) *model.Order); ok {
+		r0 = rf(ctx, orderID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Order)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) *errs.CustomError); ok {
+		r1 = rf(ctx, orderID)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*errs.CustomError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetOrderHistory provides a mock function with given fields: ctx, orderID
+func (_m *OrderRepo) GetOrderHistory(ctx context.Context, orderID uuid.UUID) ([]model.OrderStatusChange, *errs.CustomError) {
+	ret := _m.Called(ctx, orderID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOrderHistory")
+	}
+
+	var r0 []model.OrderStatusChange
+	var r1 *errs.CustomError
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) ([]model.OrderStatusChange, *errs.CustomError)); ok {
+		return rf(ctx, orderID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) []model.OrderStatusChange); ok {
+		r0 = rf(ctx, orderID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.OrderStatusChange)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) *errs.CustomError); ok {
+		r1 = rf(ctx, orderID)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*errs.CustomError)
+		}
+	}
+
+	return r0, r1
+}
+
+// ListOrders provides a mock function with given fields: ctx, filter
+func (_m *OrderRepo) ListOrders(ctx context.Context, filter model.OrderFilter) ([]model.Order, *errs.CustomError) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListOrders")
+	}
+
+	var r0 []model.Order
+	var r1 *errs.CustomError
+	if rf, ok := ret.Get(0).(func(context.Context, model.OrderFilter) ([]model.Order, *errs.CustomError)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.OrderFilter) []model.Order); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Order)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.OrderFilter) *errs.CustomError); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*errs.CustomError)
+		}
+	}
+
+	return r0, r1
+}
+
+// UpdateOrderStatus provides a mock function with given fields: ctx, id, update
+func (_m *OrderRepo) UpdateOrderStatus(ctx context.Context, id uuid.UUID, update model.OrderStatusUpdate) *errs.CustomError {
+	ret := _m.Called(ctx, id, update)
 
 	if len(ret) == 0 {
 		panic("no return value specified for UpdateOrderStatus")
 	}
 
 	var r0 *errs.CustomError
-	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, model.OrderStatus) *errs.CustomError); ok {
-		r0 = rf(ctx, id, order)
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, model.OrderStatusUpdate) *errs.CustomError); ok {
+		r0 = rf(ctx, id, update)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*errs.CustomError)
@@ -16,6 +16,38 @@ type OrderService struct {
 	mock.Mock
 }
 
+// CancelOrder provides a mock function with given fields: ctx, request
+func (_m *OrderService) CancelOrder(ctx context.Context, request *dto.CancelOrderRequest) (*dto.CancelOrderResponse, *errs.CustomError) {
+	ret := _m.Called(ctx, request)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CancelOrder")
+	}
+
+	var r0 *dto.CancelOrderResponse
+	var r1 *errs.CustomError
+	if rf, ok := ret.Get(0).(func(context.Context, *dto.CancelOrderRequest) (*dto.CancelOrderResponse, *errs.CustomError)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *dto.CancelOrderRequest) *dto.CancelOrderResponse); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.CancelOrderResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *dto.CancelOrderRequest) *errs.CustomError); ok {
+		r1 = rf(ctx, request)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*errs.CustomError)
+		}
+	}
+
+	return r0, r1
+}
+
 // CreateOrder provides a mock function with given fields: ctx, request
 func (_m *OrderService) CreateOrder(ctx context.Context, request *dto.CreateOrderRequest) (*dto.CreateOrderResponse, *errs.CustomError) {
 	ret := _m.Called(ctx, request)
@@ -48,6 +80,70 @@ func (_m *OrderService) CreateOrder(ctx context.Context, request *dto.CreateOrde
 	return r0, r1
 }
 
+// ForceOrderStatus provides a mock function with given fields: ctx, request
+func (_m *OrderService) ForceOrderStatus(ctx context.Context, request *dto.ForceOrderStatusRequest) (*dto.GetOrderStatusResponse, *errs.CustomError) {
+	ret := _m.Called(ctx, request)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ForceOrderStatus")
+	}
+
+	var r0 *dto.GetOrderStatusResponse
+	var r1 *errs.CustomError
+	if rf, ok := ret.Get(0).(func(context.Context, *dto.ForceOrderStatusRequest) (*dto.GetOrderStatusResponse, *errs.CustomError)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *dto.ForceOrderStatusRequest) *dto.GetOrderStatusResponse); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.GetOrderStatusResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *dto.ForceOrderStatusRequest) *errs.CustomError); ok {
+		r1 = rf(ctx, request)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*errs.CustomError)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetOrderHistory provides a mock function with given fields: ctx, request
+func (_m *OrderService) GetOrderHistory(ctx context.Context, request *dto.GetOrderStatusRequest) (*dto.GetOrderHistoryResponse, *errs.CustomError) {
+	ret := _m.Called(ctx, request)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOrderHistory")
+	}
+
+	var r0 *dto.GetOrderHistoryResponse
+	var r1 *errs.CustomError
+	if rf, ok := ret.Get(0).(func(context.Context, *dto.GetOrderStatusRequest) (*dto.GetOrderHistoryResponse, *errs.CustomError)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *dto.GetOrderStatusRequest) *dto.GetOrderHistoryResponse); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.GetOrderHistoryResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *dto.GetOrderStatusRequest) *errs.CustomError); ok {
+		r1 = rf(ctx, request)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*errs.CustomError)
+		}
+	}
+
+	return r0, r1
+}
+
 // GetOrderStatus provides a mock function with given fields: ctx, request
 func (_m *OrderService) GetOrderStatus(ctx context.Context, request *dto.GetOrderStatusRequest) (*dto.GetOrderStatusResponse, *errs.CustomError) {
 	ret := _m.Called(ctx, request)
@@ -80,6 +176,38 @@ func (_m *OrderService) GetOrderStatus(ctx context.Context, request *dto.GetOrde
 	return r0, r1
 }
 
+// ListOrders provides a mock function with given fields: ctx, request
+func (_m *OrderService) ListOrders(ctx context.Context, request *dto.ListOrdersRequest) (*dto.ListOrdersResponse, *errs.CustomError) {
+	ret := _m.Called(ctx, request)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListOrders")
+	}
+
+	var r0 *dto.ListOrdersResponse
+	var r1 *errs.CustomError
+	if rf, ok := ret.Get(0).(func(context.Context, *dto.ListOrdersRequest) (*dto.ListOrdersResponse, *errs.CustomError)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *dto.ListOrdersRequest) *dto.ListOrdersResponse); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.ListOrdersResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *dto.ListOrdersRequest) *errs.CustomError); ok {
+		r1 = rf(ctx, request)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*errs.CustomError)
+		}
+	}
+
+	return r0, r1
+}
+
 // SubscribeOrderStatus provides a mock function with given fields: ctx, request
 func (_m *OrderService) SubscribeOrderStatus(ctx context.Context, request *dto.GetOrderStatusRequest) (<-chan *dto.GetOrderStatusResponse, *errs.CustomError) {
 	ret := _m.Called(ctx, request)
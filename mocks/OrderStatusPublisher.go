@@ -10,6 +10,8 @@ import (
 
 	model "OrderService/internal/model"
 
+	time "time"
+
 	uuid "github.com/google/uuid"
 )
 
@@ -18,17 +20,17 @@ type OrderStatusPublisher struct {
 	mock.Mock
 }
 
-// PublishOrderStatus provides a mock function with given fields: ctx, orderID, status
-func (_m *OrderStatusPublisher) PublishOrderStatus(ctx context.Context, orderID uuid.UUID, status model.OrderStatus) *errs.CustomError {
-	ret := _m.Called(ctx, orderID, status)
+// PublishOrderStatus provides a mock function with given fields: ctx, orderID, status, occurredAt
+func (_m *OrderStatusPublisher) PublishOrderStatus(ctx context.Context, orderID uuid.UUID, status model.OrderStatus, occurredAt time.Time) *errs.CustomError {
+	ret := _m.Called(ctx, orderID, status, occurredAt)
 
 	if len(ret) == 0 {
 		panic("no return value specified for PublishOrderStatus")
 	}
 
 	var r0 *errs.CustomError
-	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, model.OrderStatus) *errs.CustomError); ok {
-		r0 = rf(ctx, orderID, status)
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, model.OrderStatus, time.Time) *errs.CustomError); ok {
+		r0 = rf(ctx, orderID, status, occurredAt)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*errs.CustomError)
@@ -18,29 +18,29 @@ type OrderStatusSubscriber struct {
 	mock.Mock
 }
 
-// SubscribeOrderStatus provides a mock function with given fields: ctx, orderID
-func (_m *OrderStatusSubscriber) SubscribeOrderStatus(ctx context.Context, orderID uuid.UUID) (<-chan model.OrderStatus, *errs.CustomError) {
-	ret := _m.Called(ctx, orderID)
+// SubscribeOrderStatus provides a mock function with given fields: ctx, orderID, afterID
+func (_m *OrderStatusSubscriber) SubscribeOrderStatus(ctx context.Context, orderID uuid.UUID, afterID string) (<-chan model.OrderStatusEvent, *errs.CustomError) {
+	ret := _m.Called(ctx, orderID, afterID)
 
 	if len(ret) == 0 {
 		panic("no return value specified for SubscribeOrderStatus")
 	}
 
-	var r0 <-chan model.OrderStatus
+	var r0 <-chan model.OrderStatusEvent
 	var r1 *errs.CustomError
-	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) (<-chan model.OrderStatus, *errs.CustomError)); ok {
-		return rf(ctx, orderID)
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string) (<-chan model.OrderStatusEvent, *errs.CustomError)); ok {
+		return rf(ctx, orderID, afterID)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) <-chan model.OrderStatus); ok {
-		r0 = rf(ctx, orderID)
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string) <-chan model.OrderStatusEvent); ok {
+		r0 = rf(ctx, orderID, afterID)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(<-chan model.OrderStatus)
+			r0 = ret.Get(0).(<-chan model.OrderStatusEvent)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) *errs.CustomError); ok {
-		r1 = rf(ctx, orderID)
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, string) *errs.CustomError); ok {
+		r1 = rf(ctx, orderID, afterID)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(*errs.CustomError)
@@ -0,0 +1,155 @@
+// Code generated by mockery v2.53.6. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	errs "github.com/erdedan1/shared/errs"
+	mock "github.com/stretchr/testify/mock"
+
+	model "OrderService/internal/model"
+
+	time "time"
+)
+
+// OutboxRepo is an autogenerated mock type for the OutboxRepo type
+type OutboxRepo struct {
+	mock.Mock
+}
+
+// ClaimPending provides a mock function with given fields: ctx, limit, lease
+func (_m *OutboxRepo) ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]model.OutboxEvent, *errs.CustomError) {
+	ret := _m.Called(ctx, limit, lease)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ClaimPending")
+	}
+
+	var r0 []model.OutboxEvent
+	var r1 *errs.CustomError
+	if rf, ok := ret.Get(0).(func(context.Context, int, time.Duration) ([]model.OutboxEvent, *errs.CustomError)); ok {
+		return rf(ctx, limit, lease)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, time.Duration) []model.OutboxEvent); ok {
+		r0 = rf(ctx, limit, lease)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.OutboxEvent)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, time.Duration) *errs.CustomError); ok {
+		r1 = rf(ctx, limit, lease)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*errs.CustomError)
+		}
+	}
+
+	return r0, r1
+}
+
+// DeleteDelivered provides a mock function with given fields: ctx, before
+func (_m *OutboxRepo) DeleteDelivered(ctx context.Context, before time.Time) (int64, *errs.CustomError) {
+	ret := _m.Called(ctx, before)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteDelivered")
+	}
+
+	var r0 int64
+	var r1 *errs.CustomError
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) (int64, *errs.CustomError)); ok {
+		return rf(ctx, before)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int64); ok {
+		r0 = rf(ctx, before)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) *errs.CustomError); ok {
+		r1 = rf(ctx, before)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*errs.CustomError)
+		}
+	}
+
+	return r0, r1
+}
+
+// MarkDeadLettered provides a mock function with given fields: ctx, id, reason
+func (_m *OutboxRepo) MarkDeadLettered(ctx context.Context, id int64, reason string) *errs.CustomError {
+	ret := _m.Called(ctx, id, reason)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkDeadLettered")
+	}
+
+	var r0 *errs.CustomError
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) *errs.CustomError); ok {
+		r0 = rf(ctx, id, reason)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*errs.CustomError)
+		}
+	}
+
+	return r0
+}
+
+// MarkDelivered provides a mock function with given fields: ctx, id
+func (_m *OutboxRepo) MarkDelivered(ctx context.Context, id int64) *errs.CustomError {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkDelivered")
+	}
+
+	var r0 *errs.CustomError
+	if rf, ok := ret.Get(0).(func(context.Context, int64) *errs.CustomError); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*errs.CustomError)
+		}
+	}
+
+	return r0
+}
+
+// MarkFailed provides a mock function with given fields: ctx, id, nextAttemptAt, reason
+func (_m *OutboxRepo) MarkFailed(ctx context.Context, id int64, nextAttemptAt time.Time, reason string) *errs.CustomError {
+	ret := _m.Called(ctx, id, nextAttemptAt, reason)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkFailed")
+	}
+
+	var r0 *errs.CustomError
+	if rf, ok := ret.Get(0).(func(context.Context, int64, time.Time, string) *errs.CustomError); ok {
+		r0 = rf(ctx, id, nextAttemptAt, reason)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*errs.CustomError)
+		}
+	}
+
+	return r0
+}
+
+// NewOutboxRepo creates a new instance of OutboxRepo. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewOutboxRepo(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *OutboxRepo {
+	mock := &OutboxRepo{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -10,6 +10,8 @@ import (
 
 	model "OrderService/internal/model"
 
+	time "time"
+
 	uuid "github.com/google/uuid"
 )
 
@@ -19,8 +21,43 @@ type UserRepo struct {
 }
 
 // CreateUser provides a mock function with given fields: ctx, user
-func (_m *UserRepo) CreateUser(ctx context.Context, user model.User) {
-	_m.Called(ctx, user)
+func (_m *UserRepo) CreateUser(ctx context.Context, user model.User) *errs.CustomError {
+	ret := _m.Called(ctx, user)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateUser")
+	}
+
+	var r0 *errs.CustomError
+	if rf, ok := ret.Get(0).(func(context.Context, model.User) *errs.CustomError); ok {
+		r0 = rf(ctx, user)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*errs.CustomError)
+		}
+	}
+
+	return r0
+}
+
+// DeactivateUser provides a mock function with given fields: ctx, id, at
+func (_m *UserRepo) DeactivateUser(ctx context.Context, id uuid.UUID, at time.Time) *errs.CustomError {
+	ret := _m.Called(ctx, id, at)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeactivateUser")
+	}
+
+	var r0 *errs.CustomError
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, time.Time) *errs.CustomError); ok {
+		r0 = rf(ctx, id, at)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*errs.CustomError)
+		}
+	}
+
+	return r0
 }
 
 // GetUserById provides a mock function with given fields: ctx, id
@@ -55,6 +92,26 @@ func (_m *UserRepo) GetUserById(ctx context.Context, id uuid.UUID) (*model.User,
 	return r0, r1
 }
 
+// UpdateUserRoles provides a mock function with given fields: ctx, id, roles
+func (_m *UserRepo) UpdateUserRoles(ctx context.Context, id uuid.UUID, roles []string) *errs.CustomError {
+	ret := _m.Called(ctx, id, roles)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateUserRoles")
+	}
+
+	var r0 *errs.CustomError
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, []string) *errs.CustomError); ok {
+		r0 = rf(ctx, id, roles)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*errs.CustomError)
+		}
+	}
+
+	return r0
+}
+
 // NewUserRepo creates a new instance of UserRepo. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewUserRepo(t interface {
@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+type lruEntry[K comparable, V any] struct {
+	key       K
+	value     V
+	expiresAt time.Time
+}
+
+// LRU is a bounded in-process cache, once full the least recently used entry makes room.
+// Expired entries are still returned with their expiry, whether to serve them stale is
+// up to the caller. They go once evicted or replaced.
+type LRU[K comparable, V any] struct {
+	capacity int
+
+	mu      sync.Mutex
+	entries map[K]*list.Element
+	order   *list.List
+}
+
+func NewLRU[K comparable, V any](capacity int) *LRU[K, V] {
+	return &LRU[K, V]{
+		capacity: max(capacity, 1),
+		entries:  make(map[K]*list.Element),
+		order:    list.New(),
+	}
+}
+
+func (c *LRU[K, V]) Get(key K) (value V, expiresAt time.Time, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return value, expiresAt, false
+	}
+	c.order.MoveToFront(element)
+
+	entry := element.Value.(*lruEntry[K, V])
+	return entry.value, entry.expiresAt, true
+}
+
+func (c *LRU[K, V]) Set(key K, value V, expiresAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		entry := element.Value.(*lruEntry[K, V])
+		entry.value, entry.expiresAt = value, expiresAt
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&lruEntry[K, V]{key: key, value: value, expiresAt: expiresAt})
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry[K, V]).key)
+	}
+}
+
+func (c *LRU[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		c.order.Remove(element)
+		delete(c.entries, key)
+	}
+}
+
+// Purge drops every entry.
+func (c *LRU[K, V]) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = make(map[K]*list.Element)
+	c.order.Init()
+}
+
+func (c *LRU[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
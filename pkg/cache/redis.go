@@ -2,6 +2,7 @@ package cache
 
 import (
 	"OrderService/config"
+	"OrderService/internal/metrics"
 	"context"
 	"time"
 
@@ -9,11 +10,21 @@ import (
 )
 
 type RedisClient interface {
+	Ping(ctx context.Context) *redis.StatusCmd
 	Get(ctx context.Context, key string) *redis.StringCmd
 	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.StatusCmd
 	Del(ctx context.Context, keys ...string) *redis.IntCmd
+	Scan(ctx context.Context, cursor uint64, match string, count int64) *redis.ScanCmd
+	Expire(ctx context.Context, key string, expiration time.Duration) *redis.BoolCmd
 	Publish(ctx context.Context, channel string, message interface{}) *redis.IntCmd
 	Subscribe(ctx context.Context, channels ...string) *redis.PubSub
+	XAdd(ctx context.Context, a *redis.XAddArgs) *redis.StringCmd
+	XRead(ctx context.Context, a *redis.XReadArgs) *redis.XStreamSliceCmd
+	XRevRangeN(ctx context.Context, stream, start, stop string, count int64) *redis.XMessageSliceCmd
+	Eval(ctx context.Context, script string, keys []string, args ...interface{}) *redis.Cmd
+	EvalSha(ctx context.Context, sha1 string, keys []string, args ...interface{}) *redis.Cmd
+	ScriptExists(ctx context.Context, hashes ...string) *redis.BoolSliceCmd
+	ScriptLoad(ctx context.Context, script string) *redis.StringCmd
 }
 
 func NewRedisClient(config *config.Config) RedisClient {
@@ -25,6 +36,7 @@ func NewRedisClient(config *config.Config) RedisClient {
 		Password:     config.Infrastructure.RedisConfig.Password,
 		DB:           config.Infrastructure.RedisConfig.DB,
 	})
+	client.AddHook(metrics.RedisHook{})
 
 	return client
 }
@@ -0,0 +1,190 @@
+package cache
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// Lookup is the outcome of a lookup in the local tier.
+type Lookup string
+
+const (
+	LookupHit   Lookup = "hit"
+	LookupStale Lookup = "stale"
+	LookupMiss  Lookup = "miss"
+)
+
+// Loader fetches the value of a key from the tier behind the local one, found is false
+// when it does not hold the key either.
+type Loader[V any] func(ctx context.Context) (value V, found bool, err error)
+
+// Source is where the values missing locally come from. Store, when set, is called with a
+// loaded value before it is kept locally, and not at all when the key was invalidated while
+// it was being loaded, so that it is not written back to the tier it was invalidated in.
+type Source[V any] struct {
+	Load  Loader[V]
+	Store func(ctx context.Context, value V) error
+}
+
+type TieredOptions struct {
+	// Capacity bounds the entries of the local tier.
+	Capacity int
+	// TTL is how long a local entry is fresh. For StaleTTL after that it is still served,
+	// while a refresh in the background replaces it.
+	TTL      time.Duration
+	StaleTTL time.Duration
+	// LoadTimeout bounds a load, it runs detached from the caller that started it since
+	// the callers coalesced onto it must not fail with it.
+	LoadTimeout time.Duration
+	// OnLookup, when set, is called with the outcome of every local lookup.
+	OnLookup func(Lookup)
+}
+
+// Tiered is an in-process LRU in front of a slower tier. Concurrent misses of a key are
+// coalesced into one load, and stale entries are served while they are being refreshed.
+//
+// Every Set, Delete and Purge moves the version of the keys it touches, and a load only
+// keeps what it loaded if the version of its key is still the one it started at, so a
+// load racing an invalidation can not bring back what was invalidated.
+type Tiered[V any] struct {
+	local *LRU[string, V]
+	loads singleflight.Group
+	opts  TieredOptions
+
+	// mu orders the versions against the loads keeping their values
+	mu sync.Mutex
+	// epoch is moved by Purge, generations by Set and Delete of a key. Purge drops the
+	// generations, so they only ever hold the keys touched since the last one.
+	epoch       uint64
+	generations map[string]uint64
+}
+
+type version struct {
+	epoch      uint64
+	generation uint64
+}
+
+func NewTiered[V any](opts TieredOptions) *Tiered[V] {
+	return &Tiered[V]{
+		local:       NewLRU[string, V](opts.Capacity),
+		opts:        opts,
+		generations: make(map[string]uint64),
+	}
+}
+
+// Get returns the value of key from the local tier, or loads it from source and keeps it
+// locally when found.
+func (t *Tiered[V]) Get(ctx context.Context, key string, source Source[V]) (V, bool, error) {
+	now := time.Now()
+	ver := t.version(key)
+	// a load started before an invalidation is not joined by the lookups after it
+	flight := key + "@" + strconv.FormatUint(ver.epoch, 10) + "." + strconv.FormatUint(ver.generation, 10)
+
+	value, expiresAt, ok := t.local.Get(key)
+	switch {
+	case ok && now.Before(expiresAt):
+		t.observe(LookupHit)
+		return value, true, nil
+	case ok && now.Before(expiresAt.Add(t.opts.StaleTTL)):
+		t.observe(LookupStale)
+		// DoChan returns at once, the refresh goes on in the background
+		t.loads.DoChan(flight, func() (any, error) {
+			return t.load(ctx, key, ver, source)
+		})
+		return value, true, nil
+	}
+
+	t.observe(LookupMiss)
+	result, err, _ := t.loads.Do(flight, func() (any, error) {
+		return t.load(ctx, key, ver, source)
+	})
+	if err != nil {
+		var zero V
+		return zero, false, err
+	}
+
+	loaded := result.(loadResult[V])
+	return loaded.value, loaded.found, nil
+}
+
+// Set keeps value locally, fresh for TTL from now. A load of key in flight is not kept.
+func (t *Tiered[V]) Set(key string, value V) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.generations[key]++
+	t.local.Set(key, value, time.Now().Add(t.opts.TTL))
+}
+
+// Delete drops the local entry of key. A load of key in flight is not kept.
+func (t *Tiered[V]) Delete(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.generations[key]++
+	t.local.Delete(key)
+}
+
+// Purge drops every local entry. No load in flight is kept.
+func (t *Tiered[V]) Purge() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.epoch++
+	clear(t.generations)
+	t.local.Purge()
+}
+
+func (t *Tiered[V]) version(key string) version {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return version{epoch: t.epoch, generation: t.generations[key]}
+}
+
+type loadResult[V any] struct {
+	value V
+	found bool
+}
+
+func (t *Tiered[V]) load(ctx context.Context, key string, ver version, source Source[V]) (loadResult[V], error) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), t.opts.LoadTimeout)
+	defer cancel()
+
+	value, found, err := source.Load(ctx)
+	if err != nil {
+		return loadResult[V]{}, err
+	}
+	if !found {
+		return loadResult[V]{}, nil
+	}
+
+	// held through Store, an invalidation either comes before it and the value is not
+	// kept, or waits for it and then drops what it stored
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.epoch != ver.epoch || t.generations[key] != ver.generation {
+		// the callers waiting for it still get it, it is just not kept
+		return loadResult[V]{value: value, found: true}, nil
+	}
+
+	if source.Store != nil {
+		if err := source.Store(ctx, value); err != nil {
+			return loadResult[V]{}, err
+		}
+	}
+	t.local.Set(key, value, time.Now().Add(t.opts.TTL))
+
+	return loadResult[V]{value: value, found: true}, nil
+}
+
+func (t *Tiered[V]) observe(lookup Lookup) {
+	if t.opts.OnLookup != nil {
+		t.opts.OnLookup(lookup)
+	}
+}
@@ -0,0 +1,176 @@
+package cache_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"OrderService/pkg/cache"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRU_EvictsLeastRecentlyUsed(t *testing.T) {
+	lru := cache.NewLRU[string, int](2)
+	expiresAt := time.Now().Add(time.Minute)
+
+	lru.Set("a", 1, expiresAt)
+	lru.Set("b", 2, expiresAt)
+	_, _, ok := lru.Get("a")
+	assert.True(t, ok)
+
+	lru.Set("c", 3, expiresAt)
+
+	_, _, ok = lru.Get("b")
+	assert.False(t, ok)
+	value, _, ok := lru.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+	assert.Equal(t, 2, lru.Len())
+}
+
+func TestTiered_CoalescesConcurrentMisses(t *testing.T) {
+	tiered := cache.NewTiered[string](cache.TieredOptions{
+		Capacity:    8,
+		TTL:         time.Minute,
+		LoadTimeout: time.Second,
+	})
+
+	var loads atomic.Int32
+	release := make(chan struct{})
+	load := func(context.Context) (string, bool, error) {
+		loads.Add(1)
+		<-release
+		return "markets", true, nil
+	}
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			value, found, err := tiered.Get(context.Background(), "markets:role:USER_ROLE_TRADER", cache.Source[string]{Load: load})
+			assert.NoError(t, err)
+			assert.True(t, found)
+			assert.Equal(t, "markets", value)
+		})
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), loads.Load())
+
+	// loaded once, served locally from then on
+	_, _, err := tiered.Get(context.Background(), "markets:role:USER_ROLE_TRADER", cache.Source[string]{Load: load})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), loads.Load())
+}
+
+func TestTiered_ServesStaleWhileRevalidating(t *testing.T) {
+	var lookups []cache.Lookup
+	var mu sync.Mutex
+	tiered := cache.NewTiered[string](cache.TieredOptions{
+		Capacity:    8,
+		TTL:         10 * time.Millisecond,
+		StaleTTL:    time.Minute,
+		LoadTimeout: time.Second,
+		OnLookup: func(lookup cache.Lookup) {
+			mu.Lock()
+			lookups = append(lookups, lookup)
+			mu.Unlock()
+		},
+	})
+	tiered.Set("key", "old")
+	time.Sleep(20 * time.Millisecond)
+
+	var (
+		refreshes atomic.Int32
+		refreshed = make(chan struct{})
+		once      sync.Once
+	)
+	refresh := func(context.Context) (string, bool, error) {
+		refreshes.Add(1)
+		once.Do(func() { close(refreshed) })
+		return "new", true, nil
+	}
+
+	value, found, err := tiered.Get(context.Background(), "key", cache.Source[string]{Load: refresh})
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "old", value)
+
+	<-refreshed
+	// on a slow runner the refreshed entry may be stale again by now, which only refreshes it once more
+	assert.Eventually(t, func() bool {
+		value, _, _ := tiered.Get(context.Background(), "key", cache.Source[string]{Load: refresh})
+		return value == "new"
+	}, time.Second, 5*time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, cache.LookupStale, lookups[0])
+	assert.GreaterOrEqual(t, refreshes.Load(), int32(1))
+}
+
+func TestTiered_DropsLoadsRacingAnInvalidation(t *testing.T) {
+	for name, invalidate := range map[string]func(*cache.Tiered[string]){
+		"delete": func(tiered *cache.Tiered[string]) { tiered.Delete("key") },
+		"purge":  func(tiered *cache.Tiered[string]) { tiered.Purge() },
+	} {
+		t.Run(name, func(t *testing.T) {
+			tiered := cache.NewTiered[string](cache.TieredOptions{
+				Capacity:    8,
+				TTL:         time.Minute,
+				LoadTimeout: time.Second,
+			})
+
+			started := make(chan struct{})
+			release := make(chan struct{})
+			var stored atomic.Int32
+			stale := cache.Source[string]{
+				Load: func(context.Context) (string, bool, error) {
+					close(started)
+					<-release
+					return "old", true, nil
+				},
+				Store: func(context.Context, string) error {
+					stored.Add(1)
+					return nil
+				},
+			}
+
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				// the caller that started it still gets what it loaded
+				value, found, err := tiered.Get(context.Background(), "key", stale)
+				assert.NoError(t, err)
+				assert.True(t, found)
+				assert.Equal(t, "old", value)
+			}()
+
+			<-started
+			invalidate(tiered)
+
+			// a lookup after the invalidation does not join the load in flight
+			fresh := cache.Source[string]{
+				Load: func(context.Context) (string, bool, error) {
+					return "new", true, nil
+				},
+			}
+			value, _, err := tiered.Get(context.Background(), "key", fresh)
+			assert.NoError(t, err)
+			assert.Equal(t, "new", value)
+
+			close(release)
+			<-done
+
+			assert.Equal(t, int32(0), stored.Load())
+			value, _, err = tiered.Get(context.Background(), "key", fresh)
+			assert.NoError(t, err)
+			assert.Equal(t, "new", value)
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	log "github.com/erdedan1/shared/logger"
+)
+
+const layer = "CertReloader"
+
+// Files are the PEM files a Reloader keeps in memory. CertFile and KeyFile go together,
+// CAFile is the bundle peers are verified against. Either part may be empty.
+type Files struct {
+	CertFile string
+	KeyFile  string
+	CAFile   string
+}
+
+// Reloader serves a certificate and CA pool that follow the files on disk. The files are
+// checked at most once per interval, on the next handshake, and re-read when any of them
+// changed. A failed reload keeps the last good material, so a half-written rotation does
+// not take the listener down.
+type Reloader struct {
+	files    Files
+	interval time.Duration
+	log      log.Logger
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	pool      *x509.CertPool
+	modTimes  map[string]time.Time
+	checkedAt time.Time
+}
+
+func NewReloader(files Files, interval time.Duration, log log.Logger) (*Reloader, error) {
+	if (files.CertFile == "") != (files.KeyFile == "") {
+		return nil, errors.New("certificate and key files must be set together")
+	}
+
+	r := &Reloader{
+		files:    files,
+		interval: interval,
+		log:      log,
+	}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *Reloader) HasCertificate() bool {
+	return r.files.CertFile != ""
+}
+
+func (r *Reloader) HasCA() bool {
+	return r.files.CAFile != ""
+}
+
+// Current returns the certificate and CA pool, reloading them first if the files changed.
+func (r *Reloader) Current() (*tls.Certificate, *x509.CertPool) {
+	const method = "Current"
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if time.Since(r.checkedAt) >= r.interval {
+		r.checkedAt = time.Now()
+
+		if r.changed() {
+			if err := r.load(); err != nil {
+				r.log.Error(layer, method, "failed to reload certificates, keeping the previous ones", err, "cert_file", r.files.CertFile, "ca_file", r.files.CAFile)
+			} else {
+				r.log.Info(layer, method, "certificates reloaded", "cert_file", r.files.CertFile, "ca_file", r.files.CAFile)
+			}
+		}
+	}
+
+	return r.cert, r.pool
+}
+
+func (r *Reloader) load() error {
+	modTimes := make(map[string]time.Time, 3)
+	for _, path := range r.paths() {
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		modTimes[path] = info.ModTime()
+	}
+
+	var cert *tls.Certificate
+	if r.files.CertFile != "" {
+		pair, err := tls.LoadX509KeyPair(r.files.CertFile, r.files.KeyFile)
+		if err != nil {
+			return fmt.Errorf("load key pair: %w", err)
+		}
+		cert = &pair
+	}
+
+	var pool *x509.CertPool
+	if r.files.CAFile != "" {
+		pem, err := os.ReadFile(r.files.CAFile)
+		if err != nil {
+			return fmt.Errorf("read ca bundle: %w", err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("ca bundle %s has no certificates", r.files.CAFile)
+		}
+	}
+
+	r.cert = cert
+	r.pool = pool
+	r.modTimes = modTimes
+
+	return nil
+}
+
+func (r *Reloader) changed() bool {
+	for _, path := range r.paths() {
+		info, err := os.Stat(path)
+		if err != nil || !info.ModTime().Equal(r.modTimes[path]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *Reloader) paths() []string {
+	var paths []string
+	for _, path := range []string{r.files.CertFile, r.files.KeyFile, r.files.CAFile} {
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+
+	return paths
+}
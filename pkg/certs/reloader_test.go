@@ -0,0 +1,77 @@
+package certs_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"OrderService/pkg/certs"
+
+	log "github.com/erdedan1/shared/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeSelfSigned(t *testing.T, dir, commonName string) (certFile, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+
+	certFile = filepath.Join(dir, "tls.crt")
+	keyFile = filepath.Join(dir, "tls.key")
+	assert.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	assert.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+
+	return certFile, keyFile
+}
+
+func TestReloader_PicksUpRotatedCertificate(t *testing.T) {
+	logger, _ := log.NewLogger("debug")
+	dir := t.TempDir()
+
+	certFile, keyFile := writeSelfSigned(t, dir, "first")
+	reloader, err := certs.NewReloader(certs.Files{CertFile: certFile, KeyFile: keyFile}, 0, logger)
+	assert.NoError(t, err)
+
+	cert, _ := reloader.Current()
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	assert.NoError(t, err)
+	assert.Equal(t, "first", leaf.Subject.CommonName)
+
+	writeSelfSigned(t, dir, "second")
+	later := time.Now().Add(time.Minute)
+	assert.NoError(t, os.Chtimes(certFile, later, later))
+
+	cert, _ = reloader.Current()
+	leaf, err = x509.ParseCertificate(cert.Certificate[0])
+	assert.NoError(t, err)
+	assert.Equal(t, "second", leaf.Subject.CommonName)
+
+	// a broken rotation keeps serving the last good certificate
+	assert.NoError(t, os.WriteFile(certFile, []byte("garbage"), 0o600))
+	evenLater := later.Add(time.Minute)
+	assert.NoError(t, os.Chtimes(certFile, evenLater, evenLater))
+
+	cert, _ = reloader.Current()
+	leaf, err = x509.ParseCertificate(cert.Certificate[0])
+	assert.NoError(t, err)
+	assert.Equal(t, "second", leaf.Subject.CommonName)
+}
@@ -0,0 +1,84 @@
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+)
+
+// ServerConfig builds a server TLS config that picks up reloaded certificates on every
+// handshake. Client certificates are verified against the CA bundle when one is set,
+// and required when requireClientCert is true.
+func ServerConfig(r *Reloader, requireClientCert bool) *tls.Config {
+	clientAuth := tls.NoClientCert
+	switch {
+	case requireClientCert:
+		clientAuth = tls.RequireAndVerifyClientCert
+	case r.HasCA():
+		clientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			cert, pool := r.Current()
+			if cert == nil {
+				return nil, errors.New("no server certificate loaded")
+			}
+
+			return &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*cert},
+				ClientCAs:    pool,
+				ClientAuth:   clientAuth,
+			}, nil
+		},
+	}
+}
+
+// ClientConfig builds a client TLS config that presents the reloaded client certificate,
+// if any, and verifies the server against the reloaded CA bundle, or the system roots
+// when there is none.
+func ClientConfig(r *Reloader, serverName string) *tls.Config {
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: serverName,
+	}
+
+	if r.HasCertificate() {
+		cfg.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			cert, _ := r.Current()
+			return cert, nil
+		}
+	}
+
+	if r.HasCA() {
+		// the stock verification would pin the pool loaded at dial time, so the chain is
+		// verified here against the current one instead
+		cfg.InsecureSkipVerify = true
+		cfg.VerifyConnection = func(state tls.ConnectionState) error {
+			return verifyServer(state, r)
+		}
+	}
+
+	return cfg
+}
+
+func verifyServer(state tls.ConnectionState, r *Reloader) error {
+	if len(state.PeerCertificates) == 0 {
+		return errors.New("server presented no certificate")
+	}
+
+	_, pool := r.Current()
+	opts := x509.VerifyOptions{
+		DNSName:       state.ServerName,
+		Roots:         pool,
+		Intermediates: x509.NewCertPool(),
+	}
+	for _, cert := range state.PeerCertificates[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+
+	_, err := state.PeerCertificates[0].Verify(opts)
+	return err
+}
@@ -0,0 +1,185 @@
+package circuitbreaker
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+type State string
+
+const (
+	StateClosed   State = "closed"
+	StateOpen     State = "open"
+	StateHalfOpen State = "half-open"
+)
+
+// ErrOpen is returned by Allow while the breaker rejects calls.
+var ErrOpen = errors.New("circuit breaker is open")
+
+// StateChange is the event a breaker emits on every transition.
+type StateChange struct {
+	Name string
+	From State
+	To   State
+	At   time.Time
+}
+
+// Snapshot is the state of a breaker and its window at one moment.
+type Snapshot struct {
+	Name         string     `json:"name"`
+	State        State      `json:"state"`
+	Requests     uint32     `json:"requests"`
+	Failures     uint32     `json:"failures"`
+	FailureRatio float64    `json:"failure_ratio"`
+	OpenedAt     *time.Time `json:"opened_at,omitempty"`
+	Settings     Settings   `json:"settings"`
+}
+
+// Breaker trips when the failure ratio of the calls in its rolling window reaches the
+// configured ratio, once the window holds at least the minimum number of calls. After
+// the open timeout a few trial calls go through, the breaker closes when all of them
+// succeed and opens again on the first failure.
+type Breaker struct {
+	name     string
+	settings Settings
+	onChange func(StateChange)
+
+	mu               sync.Mutex
+	state            State
+	window           *window
+	openedAt         time.Time
+	halfOpenInFlight uint32
+	halfOpenPassed   uint32
+	// generation changes with every transition, so calls that started in an earlier
+	// state do not count towards the current one.
+	generation uint64
+}
+
+// New builds a closed breaker. onChange, if set, runs on every transition while the
+// breaker is locked, so it must not call back into it.
+func New(name string, settings Settings, onChange func(StateChange)) *Breaker {
+	settings = settings.withDefaults()
+
+	return &Breaker{
+		name:     name,
+		settings: settings,
+		onChange: onChange,
+		state:    StateClosed,
+		window:   newWindow(settings.Window.Duration, settings.WindowBuckets),
+	}
+}
+
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+// Allow asks to make a call. When it is let through, done must be called with its
+// outcome, failure being whether the call counts against the downstream.
+func (b *Breaker) Allow() (done func(failure bool), err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	if b.state == StateOpen {
+		if now.Sub(b.openedAt) < b.settings.OpenTimeout.Duration {
+			return nil, ErrOpen
+		}
+		b.transition(StateHalfOpen, now)
+	}
+
+	if b.state == StateHalfOpen {
+		if b.halfOpenInFlight+b.halfOpenPassed >= b.settings.HalfOpenRequests {
+			return nil, ErrOpen
+		}
+		b.halfOpenInFlight++
+	}
+
+	generation := b.generation
+	return func(failure bool) {
+		b.done(generation, failure)
+	}, nil
+}
+
+func (b *Breaker) done(generation uint64, failure bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if generation != b.generation {
+		return
+	}
+
+	now := time.Now()
+	switch b.state {
+	case StateHalfOpen:
+		b.halfOpenInFlight--
+		if failure {
+			b.transition(StateOpen, now)
+			return
+		}
+		b.halfOpenPassed++
+		if b.halfOpenPassed >= b.settings.HalfOpenRequests {
+			b.transition(StateClosed, now)
+		}
+
+	case StateClosed:
+		b.window.record(now, failure)
+		if !failure {
+			return
+		}
+
+		requests, failures := b.window.counts(now)
+		if requests >= b.settings.MinRequests && float64(failures)/float64(requests) >= b.settings.FailureRatio {
+			b.transition(StateOpen, now)
+		}
+	}
+}
+
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.state
+}
+
+func (b *Breaker) Snapshot() Snapshot {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	requests, failures := b.window.counts(time.Now())
+	snapshot := Snapshot{
+		Name:     b.name,
+		State:    b.state,
+		Requests: requests,
+		Failures: failures,
+		Settings: b.settings,
+	}
+	if requests > 0 {
+		snapshot.FailureRatio = float64(failures) / float64(requests)
+	}
+	if b.state != StateClosed {
+		openedAt := b.openedAt
+		snapshot.OpenedAt = &openedAt
+	}
+
+	return snapshot
+}
+
+func (b *Breaker) transition(to State, now time.Time) {
+	from := b.state
+
+	b.state = to
+	b.generation++
+	b.halfOpenInFlight = 0
+	b.halfOpenPassed = 0
+	switch to {
+	case StateOpen:
+		b.openedAt = now
+	case StateClosed:
+		b.window.reset()
+	}
+
+	if b.onChange != nil {
+		b.onChange(StateChange{Name: b.name, From: from, To: to, At: now})
+	}
+}
@@ -0,0 +1,76 @@
+package circuitbreaker_test
+
+import (
+	"testing"
+	"time"
+
+	"OrderService/pkg/circuitbreaker"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func callBreaker(t *testing.T, breaker *circuitbreaker.Breaker, failure bool) {
+	done, err := breaker.Allow()
+	assert.NoError(t, err)
+	done(failure)
+}
+
+func TestCircuitBreaker_TripsOnFailureRatio(t *testing.T) {
+	var changes []circuitbreaker.StateChange
+	breaker := circuitbreaker.New("CreateOrder", circuitbreaker.Settings{
+		Window:           circuitbreaker.Duration{Duration: time.Minute},
+		MinRequests:      4,
+		FailureRatio:     0.5,
+		OpenTimeout:      circuitbreaker.Duration{Duration: 20 * time.Millisecond},
+		HalfOpenRequests: 1,
+	}, func(change circuitbreaker.StateChange) {
+		changes = append(changes, change)
+	})
+
+	// below the minimum volume even a full failure ratio does not trip
+	callBreaker(t, breaker, true)
+	callBreaker(t, breaker, true)
+	callBreaker(t, breaker, false)
+	assert.Equal(t, circuitbreaker.StateClosed, breaker.State())
+
+	callBreaker(t, breaker, false)
+	callBreaker(t, breaker, true)
+	assert.Equal(t, circuitbreaker.StateOpen, breaker.State())
+
+	_, err := breaker.Allow()
+	assert.ErrorIs(t, err, circuitbreaker.ErrOpen)
+
+	time.Sleep(30 * time.Millisecond)
+	done, err := breaker.Allow()
+	assert.NoError(t, err)
+	_, err = breaker.Allow()
+	assert.ErrorIs(t, err, circuitbreaker.ErrOpen, "only one trial call while half-open")
+
+	done(false)
+	assert.Equal(t, circuitbreaker.StateClosed, breaker.State())
+
+	assert.Len(t, changes, 3)
+	assert.Equal(t, circuitbreaker.StateOpen, changes[0].To)
+	assert.Equal(t, circuitbreaker.StateHalfOpen, changes[1].To)
+	assert.Equal(t, circuitbreaker.StateClosed, changes[2].To)
+}
+
+func TestCircuitBreakerGroup_IsolatesMethods(t *testing.T) {
+	group := circuitbreaker.NewGroup(
+		circuitbreaker.Settings{MinRequests: 1, FailureRatio: 0.5},
+		map[string]circuitbreaker.Settings{"GetOrderStatus": {MinRequests: 100}},
+		nil,
+	)
+
+	callBreaker(t, group.Get("SubscribeOrderStatus"), true)
+	callBreaker(t, group.Get("GetOrderStatus"), true)
+
+	assert.Equal(t, circuitbreaker.StateOpen, group.Get("SubscribeOrderStatus").State())
+	assert.Equal(t, circuitbreaker.StateClosed, group.Get("GetOrderStatus").State())
+	assert.Equal(t, circuitbreaker.StateClosed, group.Get("CreateOrder").State())
+
+	snapshots := group.Snapshots()
+	assert.Len(t, snapshots, 3)
+	assert.Equal(t, "CreateOrder", snapshots[0].Name)
+	assert.Equal(t, uint32(100), snapshots[1].Settings.MinRequests)
+}
@@ -0,0 +1,60 @@
+package circuitbreaker
+
+import (
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Group hands out one breaker per name, created on first use with the defaults merged
+// with the overrides of that name.
+type Group struct {
+	defaults  Settings
+	overrides map[string]Settings
+	onChange  func(StateChange)
+
+	mu       sync.Mutex
+	breakers map[string]*Breaker
+}
+
+func NewGroup(defaults Settings, overrides map[string]Settings, onChange func(StateChange)) *Group {
+	return &Group{
+		defaults:  defaults,
+		overrides: overrides,
+		onChange:  onChange,
+		breakers:  make(map[string]*Breaker),
+	}
+}
+
+func (g *Group) Get(name string) *Breaker {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	breaker, ok := g.breakers[name]
+	if !ok {
+		breaker = New(name, g.defaults.Merge(g.overrides[name]), g.onChange)
+		g.breakers[name] = breaker
+	}
+
+	return breaker
+}
+
+// Snapshots are the states of all breakers created so far, ordered by name.
+func (g *Group) Snapshots() []Snapshot {
+	g.mu.Lock()
+	breakers := make([]*Breaker, 0, len(g.breakers))
+	for _, breaker := range g.breakers {
+		breakers = append(breakers, breaker)
+	}
+	g.mu.Unlock()
+
+	snapshots := make([]Snapshot, 0, len(breakers))
+	for _, breaker := range breakers {
+		snapshots = append(snapshots, breaker.Snapshot())
+	}
+	slices.SortFunc(snapshots, func(a, b Snapshot) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return snapshots
+}
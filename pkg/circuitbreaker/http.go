@@ -0,0 +1,19 @@
+package circuitbreaker
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Handler serves the snapshots of the breakers of every group as JSON, keyed by group name.
+func Handler(groups map[string]*Group) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		view := make(map[string][]Snapshot, len(groups))
+		for name, group := range groups {
+			view[name] = group.Snapshots()
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(view)
+	})
+}
@@ -0,0 +1,140 @@
+package circuitbreaker
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	defaultWindow           = 10 * time.Second
+	defaultWindowBuckets    = 10
+	defaultMinRequests      = 20
+	defaultFailureRatio     = 0.5
+	defaultOpenTimeout      = 10 * time.Second
+	defaultHalfOpenRequests = 3
+)
+
+// Duration is a time.Duration written as "10s" in JSON.
+type Duration struct {
+	time.Duration
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Duration) UnmarshalJSON(raw []byte) error {
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return err
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	d.Duration = parsed
+
+	return nil
+}
+
+// Settings configure a breaker. A zero field takes its value from the settings it is
+// merged into, and in the end from the package defaults.
+type Settings struct {
+	// Window is how far back calls are counted, split into WindowBuckets buckets.
+	Window        Duration `json:"window"`
+	WindowBuckets int      `json:"window_buckets"`
+	// MinRequests is the number of calls the window must hold before the breaker may trip.
+	MinRequests  uint32   `json:"min_requests"`
+	FailureRatio float64  `json:"failure_ratio"`
+	OpenTimeout  Duration `json:"open_timeout"`
+	// HalfOpenRequests is the number of trial calls that must succeed to close again.
+	HalfOpenRequests uint32 `json:"half_open_requests"`
+}
+
+// Merge returns s with the non-zero fields of override applied.
+func (s Settings) Merge(override Settings) Settings {
+	if override.Window.Duration > 0 {
+		s.Window = override.Window
+	}
+	if override.WindowBuckets > 0 {
+		s.WindowBuckets = override.WindowBuckets
+	}
+	if override.MinRequests > 0 {
+		s.MinRequests = override.MinRequests
+	}
+	if override.FailureRatio > 0 {
+		s.FailureRatio = override.FailureRatio
+	}
+	if override.OpenTimeout.Duration > 0 {
+		s.OpenTimeout = override.OpenTimeout
+	}
+	if override.HalfOpenRequests > 0 {
+		s.HalfOpenRequests = override.HalfOpenRequests
+	}
+
+	return s
+}
+
+func (s Settings) withDefaults() Settings {
+	return Settings{
+		Window:           Duration{defaultWindow},
+		WindowBuckets:    defaultWindowBuckets,
+		MinRequests:      defaultMinRequests,
+		FailureRatio:     defaultFailureRatio,
+		OpenTimeout:      Duration{defaultOpenTimeout},
+		HalfOpenRequests: defaultHalfOpenRequests,
+	}.Merge(s)
+}
+
+// Validate checks the settings, a window narrower than its bucket count would make a
+// zero bucket width.
+func (s Settings) Validate() error {
+	if s.FailureRatio < 0 || s.FailureRatio > 1 {
+		return fmt.Errorf("failure_ratio %v is not within [0, 1]", s.FailureRatio)
+	}
+	if s.WindowBuckets < 0 {
+		return fmt.Errorf("window_buckets must not be negative")
+	}
+	if s.WindowBuckets > 0 && s.Window.Duration > 0 && s.Window.Duration < time.Duration(s.WindowBuckets) {
+		return fmt.Errorf("window %s is too short for %d buckets", s.Window, s.WindowBuckets)
+	}
+
+	return nil
+}
+
+// File is the JSON layout of a breaker settings file, Breakers is keyed by breaker name:
+//
+//	{
+//	  "default": {"window": "10s", "min_requests": 20, "failure_ratio": 0.5},
+//	  "breakers": {"/order.v1.OrderService/SubscribeOrderStatus": {"failure_ratio": 0.8}}
+//	}
+type File struct {
+	Default  Settings            `json:"default"`
+	Breakers map[string]Settings `json:"breakers"`
+}
+
+func LoadFile(path string) (*File, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read circuit breaker file: %w", err)
+	}
+
+	var file File
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return nil, fmt.Errorf("parse circuit breaker file: %w", err)
+	}
+
+	if err := file.Default.Validate(); err != nil {
+		return nil, fmt.Errorf("default settings: %w", err)
+	}
+	for name, settings := range file.Breakers {
+		if err := settings.Validate(); err != nil {
+			return nil, fmt.Errorf("settings of %s: %w", name, err)
+		}
+	}
+
+	return &file, nil
+}
@@ -0,0 +1,55 @@
+package circuitbreaker
+
+import "time"
+
+type bucket struct {
+	// epoch is the number of the bucket width since the unix epoch, it tells a live
+	// bucket from one left over by an earlier lap of the ring.
+	epoch    int64
+	requests uint32
+	failures uint32
+}
+
+// window counts the calls of the last size, in buckets of equal width.
+type window struct {
+	width   time.Duration
+	buckets []bucket
+}
+
+func newWindow(size time.Duration, buckets int) *window {
+	return &window{
+		width:   size / time.Duration(buckets),
+		buckets: make([]bucket, buckets),
+	}
+}
+
+func (w *window) record(now time.Time, failure bool) {
+	epoch := now.UnixNano() / int64(w.width)
+	b := &w.buckets[epoch%int64(len(w.buckets))]
+	if b.epoch != epoch {
+		*b = bucket{epoch: epoch}
+	}
+
+	b.requests++
+	if failure {
+		b.failures++
+	}
+}
+
+func (w *window) counts(now time.Time) (requests, failures uint32) {
+	epoch := now.UnixNano() / int64(w.width)
+	oldest := epoch - int64(len(w.buckets)) + 1
+
+	for _, b := range w.buckets {
+		if b.epoch >= oldest && b.epoch <= epoch {
+			requests += b.requests
+			failures += b.failures
+		}
+	}
+
+	return requests, failures
+}
+
+func (w *window) reset() {
+	clear(w.buckets)
+}
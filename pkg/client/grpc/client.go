@@ -4,10 +4,13 @@ import (
 	"context"
 
 	"OrderService/config"
+	"OrderService/pkg/certs"
 
+	log "github.com/erdedan1/shared/logger"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/backoff"
 	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 )
 
@@ -23,9 +26,16 @@ type IGRPCClient interface {
 	WaitForStateChange(ctx context.Context, sourceState connectivity.State) bool
 }
 
-func New(address string, cfg *config.Config, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+// New dials address. Calls made without a deadline get the configured call timeout, the
+// deadline covers all retries of the call.
+func New(address string, cfg *config.Config, log log.Logger, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	creds, err := transportCredentials(cfg.GRPCClient, log)
+	if err != nil {
+		return nil, err
+	}
+
 	gGRPCopts := []grpc.DialOption{
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithTransportCredentials(creds),
 		grpc.WithConnectParams(grpc.ConnectParams{
 			Backoff: backoff.Config{
 				BaseDelay:  cfg.GRPCClient.BaseBackoffDelay,
@@ -35,8 +45,27 @@ func New(address string, cfg *config.Config, opts ...grpc.DialOption) (*grpc.Cli
 			},
 			MinConnectTimeout: cfg.GRPCClient.ConnectTimeout,
 		}),
+		grpc.WithChainUnaryInterceptor(UnaryDeadlineInterceptor(cfg.GRPCClient.CallTimeout)),
 	}
 	gGRPCopts = append(gGRPCopts, opts...)
 
 	return grpc.NewClient(address, gGRPCopts...)
 }
+
+// transportCredentials is TLS, with a client certificate when one is configured, or plaintext when TLS is off.
+func transportCredentials(cfg config.GRPCClientConfig, log log.Logger) (credentials.TransportCredentials, error) {
+	if !cfg.TLSEnabled {
+		return insecure.NewCredentials(), nil
+	}
+
+	reloader, err := certs.NewReloader(certs.Files{
+		CertFile: cfg.TLSCertFile,
+		KeyFile:  cfg.TLSKeyFile,
+		CAFile:   cfg.TLSCAFile,
+	}, cfg.TLSReloadInterval, log)
+	if err != nil {
+		return nil, err
+	}
+
+	return credentials.NewTLS(certs.ClientConfig(reloader, cfg.TLSServerName)), nil
+}
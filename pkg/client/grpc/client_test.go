@@ -0,0 +1,112 @@
+package grpc_client_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"OrderService/config"
+	"OrderService/pkg/circuitbreaker"
+	grpc_client "OrderService/pkg/client/grpc"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestUnaryDeadlineInterceptor_KeepsCallerDeadline(t *testing.T) {
+	interceptor := grpc_client.UnaryDeadlineInterceptor(time.Second)
+
+	var deadline time.Time
+	invoker := func(ctx context.Context, _ string, _, _ any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		deadline, _ = ctx.Deadline()
+		return nil
+	}
+
+	assert.NoError(t, interceptor(context.Background(), "/svc/Method", nil, nil, nil, invoker))
+	assert.WithinDuration(t, time.Now().Add(time.Second), deadline, 100*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	assert.NoError(t, interceptor(ctx, "/svc/Method", nil, nil, nil, invoker))
+	assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, 100*time.Millisecond)
+}
+
+func TestUnaryCircuitBreakerInterceptor_FailsFastWhenOpen(t *testing.T) {
+	breakers := circuitbreaker.NewGroup(circuitbreaker.Settings{
+		Window:       circuitbreaker.Duration{Duration: time.Minute},
+		MinRequests:  2,
+		FailureRatio: 0.5,
+		OpenTimeout:  circuitbreaker.Duration{Duration: time.Minute},
+	}, nil, nil)
+	interceptor := grpc_client.UnaryCircuitBreakerInterceptor(breakers)
+
+	var calls int
+	invoker := func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+		calls++
+		return status.Error(codes.Unavailable, "spot instrument service is down")
+	}
+
+	for range 2 {
+		assert.Error(t, interceptor(context.Background(), "/svc/Method", nil, nil, nil, invoker))
+	}
+
+	err := interceptor(context.Background(), "/svc/Method", nil, nil, nil, invoker)
+	assert.True(t, errors.Is(err, circuitbreaker.ErrOpen))
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, 2, calls)
+
+	// the other methods keep their own breakers
+	assert.Error(t, interceptor(context.Background(), "/svc/Other", nil, nil, nil, invoker))
+	assert.Equal(t, 3, calls)
+}
+
+type flakyHealthServer struct {
+	healthpb.UnimplementedHealthServer
+	calls atomic.Int32
+}
+
+func (s *flakyHealthServer) Check(context.Context, *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	if s.calls.Add(1) < 3 {
+		return nil, status.Error(codes.Unavailable, "warming up")
+	}
+
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
+
+func TestWithRetry_RetriesIdempotentMethods(t *testing.T) {
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	flaky := &flakyHealthServer{}
+	healthpb.RegisterHealthServer(server, flaky)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	cfg := config.GRPCClientConfig{
+		RetryMaxAttempts:       3,
+		RetryInitialBackoff:    time.Millisecond,
+		RetryMaxBackoff:        10 * time.Millisecond,
+		RetryBackoffMultiplier: 2,
+		RetryableCodes:         []string{"UNAVAILABLE"},
+	}
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return listener.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc_client.WithRetry(cfg, grpc_client.MethodName{Service: healthpb.Health_ServiceDesc.ServiceName}),
+	)
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	resp, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.GetStatus())
+	assert.Equal(t, int32(3), flaky.calls.Load())
+}
@@ -0,0 +1,72 @@
+package grpc_client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"OrderService/pkg/circuitbreaker"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryDeadlineInterceptor gives calls made without a deadline one of timeout, so a hung
+// server cannot stall the caller for good. Deadlines set by the caller are kept.
+func UnaryDeadlineInterceptor(timeout time.Duration) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if _, ok := ctx.Deadline(); !ok {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// UnaryCircuitBreakerInterceptor keeps one breaker per full method name. It sits above the
+// retries, so a call counts once however many attempts it took. While a breaker is open
+// calls fail right away with an error that matches circuitbreaker.ErrOpen.
+func UnaryCircuitBreakerInterceptor(breakers *circuitbreaker.Group) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		done, err := breakers.Get(method).Allow()
+		if err != nil {
+			return &breakerOpenError{method: method}
+		}
+
+		err = invoker(ctx, method, req, reply, cc, opts...)
+		done(isServerFailure(err))
+		return err
+	}
+}
+
+// breakerOpenError is an Unavailable status to gRPC aware code and circuitbreaker.ErrOpen
+// to errors.Is.
+type breakerOpenError struct {
+	method string
+}
+
+func (e *breakerOpenError) Error() string {
+	return fmt.Sprintf("%s: %s", e.method, circuitbreaker.ErrOpen)
+}
+
+func (e *breakerOpenError) Unwrap() error {
+	return circuitbreaker.ErrOpen
+}
+
+func (e *breakerOpenError) GRPCStatus() *status.Status {
+	return status.New(codes.Unavailable, e.Error())
+}
+
+// isServerFailure tells failures of the server apart from calls it rightly rejected or
+// the caller gave up on.
+func isServerFailure(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Internal, codes.Unknown:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,63 @@
+package grpc_client
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"OrderService/config"
+
+	"google.golang.org/grpc"
+)
+
+// MethodName names the methods a retry policy applies to, an empty Method stands for
+// every method of Service.
+type MethodName struct {
+	Service string `json:"service"`
+	Method  string `json:"method,omitempty"`
+}
+
+type retryPolicy struct {
+	MaxAttempts          int      `json:"maxAttempts"`
+	InitialBackoff       string   `json:"initialBackoff"`
+	MaxBackoff           string   `json:"maxBackoff"`
+	BackoffMultiplier    float64  `json:"backoffMultiplier"`
+	RetryableStatusCodes []string `json:"retryableStatusCodes"`
+}
+
+type methodConfig struct {
+	Name        []MethodName `json:"name"`
+	RetryPolicy retryPolicy  `json:"retryPolicy"`
+}
+
+type serviceConfig struct {
+	MethodConfig []methodConfig `json:"methodConfig"`
+}
+
+// WithRetry makes the channel retry the idempotent methods with exponential backoff, as
+// a default service config. Only pass methods that are safe to run more than once.
+func WithRetry(cfg config.GRPCClientConfig, idempotent ...MethodName) grpc.DialOption {
+	if cfg.RetryMaxAttempts < 2 || len(idempotent) == 0 {
+		return grpc.EmptyDialOption{}
+	}
+
+	raw, _ := json.Marshal(serviceConfig{
+		MethodConfig: []methodConfig{{
+			Name: idempotent,
+			RetryPolicy: retryPolicy{
+				MaxAttempts:          cfg.RetryMaxAttempts,
+				InitialBackoff:       serviceConfigDuration(cfg.RetryInitialBackoff),
+				MaxBackoff:           serviceConfigDuration(cfg.RetryMaxBackoff),
+				BackoffMultiplier:    cfg.RetryBackoffMultiplier,
+				RetryableStatusCodes: cfg.RetryableCodes,
+			},
+		}},
+	})
+
+	return grpc.WithDefaultServiceConfig(string(raw))
+}
+
+// serviceConfigDuration formats d the way the service config expects it, seconds with an s suffix.
+func serviceConfigDuration(d time.Duration) string {
+	return fmt.Sprintf("%gs", d.Seconds())
+}
@@ -0,0 +1,9 @@
+-- Demo users for a local database, the same ones the in-memory repo is seeded with.
+-- Never run this against a shared or production database, two of them are admins
+-- with well-known ids. Apply with `make seed-dev-users`.
+INSERT INTO users (id, name, roles) VALUES
+    ('1179803e-06f0-4369-b94f-14e26ec190a3', 'Gleb', '{USER_ROLE_TRADER}'),
+    ('2179803e-06f0-4369-b94f-14e26ec190a3', 'Oleg', '{USER_ROLE_ADMIN}'),
+    ('3179803e-06f0-4369-b94f-14e26ec190a3', 'Vova', '{USER_ROLE_TRADER}'),
+    ('4179803e-06f0-4369-b94f-14e26ec190a3', 'Arsen', '{USER_ROLE_ADMIN}')
+ON CONFLICT (id) DO NOTHING;